@@ -0,0 +1,100 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+)
+
+//APIKeyHandler handler
+type APIKeyHandler struct {
+	App    *App
+	Method string
+}
+
+//ServeHTTP method
+func (h *APIKeyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch h.Method {
+	case "create":
+		h.create(w, r)
+		return
+	case "delete":
+		h.delete(w, r)
+		return
+	}
+}
+
+func (h *APIKeyHandler) create(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	payload := apiKeyFromCtx(r.Context())
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "apiKeyHandler",
+		"operation": "create",
+		"userEmail": userEmail,
+		"name":      payload.Name,
+	})
+
+	var err error
+	var apiKey *models.APIKey
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		apiKey, err = models.CreateAPIKey(r.Context(), h.App.DB, payload.Name, mr)
+		return err
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Create API key failed.")
+		if conflicted, ok := err.(*errors.ConflictedModelError); ok {
+			h.App.HandleError(w, http.StatusConflict, conflicted.Error(), conflicted)
+			return
+		}
+		h.App.HandleError(w, http.StatusInternalServerError, "Create API key failed", err)
+		return
+	}
+
+	logger.Info("Created API key successfully.")
+	bytesRes, _ := json.Marshal(apiKey)
+	WriteBytes(w, http.StatusCreated, bytesRes)
+}
+
+func (h *APIKeyHandler) delete(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	name := paramKeyFromContext(r.Context())
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "apiKeyHandler",
+		"operation": "delete",
+		"userEmail": userEmail,
+		"name":      name,
+	})
+
+	err := mr.WithSegment(models.SegmentModel, func() error {
+		return models.DeleteAPIKey(r.Context(), h.App.DB, name, mr)
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Delete API key failed.")
+		if modelNotFound, ok := err.(*errors.ModelNotFoundError); ok {
+			h.App.HandleError(w, http.StatusNotFound, modelNotFound.Error(), modelNotFound)
+			return
+		}
+		h.App.HandleError(w, http.StatusInternalServerError, "Delete API key failed", err)
+		return
+	}
+
+	logger.Info("Deleted API key successfully.")
+	bytesRes, _ := json.Marshal(map[string]interface{}{"name": name})
+	WriteBytes(w, http.StatusOK, bytesRes)
+}
@@ -47,6 +47,7 @@ type App struct {
 	Cache             *cache.Cache
 	OffersCacheMaxAge time.Duration
 	Pagination        *Pagination
+	ReceiptVerifiers  map[string]models.ReceiptVerifier
 }
 
 //Pagination holds the page size (limit) and the offset that is the page number
@@ -102,17 +103,140 @@ func (a *App) getRouter() *mux.Router {
 		&MetricsReporterMiddleware{App: a},
 		&NewRelicMiddleware{App: a},
 		&AuthMiddleware{App: a, useBasicAuth: true},
-		NewParamKeyMiddleware(a, func(id string) bool {
-			return govalidator.Matches(id, "^[^-][a-zA-Z0-9-_]*$") && govalidator.StringLength(id, "1", "255")
-		}),
+		NewParamKeyMiddleware(a, isValidGameID),
 		NewValidationMiddleware(func() interface{} { return &models.Game{} }),
+		NewRoleMiddleware(a, models.RoleAdmin, gameIDFromParamKey),
 	).ServeHTTP).Methods("PUT").Name("game")
 
+	r.Handle("/games/{id}/roles", Chain(
+		&GameRoleHandler{App: a, Method: "list"},
+		&SentryMiddleware{},
+		&MetricsReporterMiddleware{App: a},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, isValidGameID),
+		NewRoleMiddleware(a, models.RoleAdmin, gameIDFromParamKey),
+	)).Methods("GET").Name("game")
+
+	r.Handle("/games/{id}/roles", Chain(
+		&GameRoleHandler{App: a, Method: "upsert"},
+		&SentryMiddleware{},
+		&MetricsReporterMiddleware{App: a},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, isValidGameID),
+		NewValidationMiddleware(func() interface{} { return &models.GameRole{} }),
+		NewRoleMiddleware(a, models.RoleAdmin, gameIDFromParamKey),
+	)).Methods("PUT").Name("game")
+
+	r.Handle("/games/{id}/roles", Chain(
+		&GameRoleHandler{App: a, Method: "delete"},
+		&SentryMiddleware{},
+		&MetricsReporterMiddleware{App: a},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, isValidGameID),
+		NewRoleMiddleware(a, models.RoleAdmin, gameIDFromParamKey),
+	)).Methods("DELETE").Name("game")
+
+	r.Handle("/games/{id}/test-players", Chain(
+		&TestPlayerHandler{App: a, Method: "list"},
+		&SentryMiddleware{},
+		&MetricsReporterMiddleware{App: a},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, isValidGameID),
+		NewRoleMiddleware(a, models.RoleAdmin, gameIDFromParamKey),
+	)).Methods("GET").Name("game")
+
+	r.Handle("/games/{id}/test-players", Chain(
+		&TestPlayerHandler{App: a, Method: "add"},
+		&SentryMiddleware{},
+		&MetricsReporterMiddleware{App: a},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, isValidGameID),
+		NewValidationMiddleware(func() interface{} { return &models.TestPlayer{} }),
+		NewRoleMiddleware(a, models.RoleAdmin, gameIDFromParamKey),
+	)).Methods("PUT").Name("game")
+
+	r.Handle("/games/{id}/test-players", Chain(
+		&TestPlayerHandler{App: a, Method: "remove"},
+		&SentryMiddleware{},
+		&MetricsReporterMiddleware{App: a},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, isValidGameID),
+		NewRoleMiddleware(a, models.RoleAdmin, gameIDFromParamKey),
+	)).Methods("DELETE").Name("game")
+
+	r.Handle("/games/{id}/placements", Chain(
+		&PlacementHandler{App: a, Method: "list"},
+		&SentryMiddleware{},
+		&MetricsReporterMiddleware{App: a},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, isValidGameID),
+		NewRoleMiddleware(a, models.RoleViewer, gameIDFromParamKey),
+	)).Methods("GET").Name("game")
+
+	r.Handle("/games/{id}/placements", Chain(
+		&PlacementHandler{App: a, Method: "upsert"},
+		&SentryMiddleware{},
+		&MetricsReporterMiddleware{App: a},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, isValidGameID),
+		NewValidationMiddleware(func() interface{} { return &models.Placement{} }),
+		NewRoleMiddleware(a, models.RoleAdmin, gameIDFromParamKey),
+	)).Methods("PUT").Name("game")
+
+	r.Handle("/games/{id}/placements", Chain(
+		&PlacementHandler{App: a, Method: "delete"},
+		&SentryMiddleware{},
+		&MetricsReporterMiddleware{App: a},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, isValidGameID),
+		NewRoleMiddleware(a, models.RoleAdmin, gameIDFromParamKey),
+	)).Methods("DELETE").Name("game")
+
+	r.Handle("/games/{id}/client-key", Chain(
+		&GameHandler{App: a, Method: "rotate-client-key"},
+		&SentryMiddleware{},
+		&MetricsReporterMiddleware{App: a},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, isValidGameID),
+		NewRoleMiddleware(a, models.RoleAdmin, gameIDFromParamKey),
+	)).Methods("POST").Name("game")
+
+	r.Handle("/api-keys", Chain(
+		&APIKeyHandler{App: a, Method: "create"},
+		&SentryMiddleware{},
+		&MetricsReporterMiddleware{App: a},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewValidationMiddleware(func() interface{} { return &models.APIKey{} }),
+		NewRoleMiddleware(a, models.RoleAdmin, anyGame),
+	)).Methods("POST").Name("api-keys")
+
+	r.Handle("/api-keys/{id}", Chain(
+		&APIKeyHandler{App: a, Method: "delete"},
+		&SentryMiddleware{},
+		&MetricsReporterMiddleware{App: a},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, isValidAPIKeyName),
+		NewRoleMiddleware(a, models.RoleAdmin, anyGame),
+	)).Methods("DELETE").Name("api-keys")
+
 	r.Handle("/offers", Chain(
 		&OfferHandler{App: a, Method: "list"},
 		&SentryMiddleware{},
 		&NewRelicMiddleware{App: a},
 		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewRoleMiddleware(a, models.RoleViewer, gameIDFromQuery),
 	)).Methods("GET").Name("offers")
 
 	r.Handle("/offers", Chain(
@@ -121,6 +245,7 @@ func (a *App) getRouter() *mux.Router {
 		&NewRelicMiddleware{App: a},
 		&AuthMiddleware{App: a, useBasicAuth: true},
 		NewValidationMiddleware(func() interface{} { return &models.Offer{} }),
+		NewRoleMiddleware(a, models.RoleEditor, gameIDFromPayload),
 	)).Methods("POST").Name("offers")
 
 	r.Handle("/offers/claim", Chain(
@@ -128,9 +253,19 @@ func (a *App) getRouter() *mux.Router {
 		&SentryMiddleware{},
 		&NewRelicMiddleware{App: a},
 		&AuthMiddleware{App: a},
+		NewSignatureMiddleware(a, signedGameIDFromBody),
 		NewValidationMiddleware(func() interface{} { return &models.ClaimOfferPayload{} }),
 	)).Methods("PUT").Name("offer-requests")
 
+	r.Handle("/offers/claim/revoke", Chain(
+		&OfferRequestHandler{App: a, Method: "revoke-claim"},
+		&SentryMiddleware{},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewValidationMiddleware(func() interface{} { return &models.RevokeClaimPayload{} }),
+		NewRoleMiddleware(a, models.RolePublisher, gameIDFromPayload),
+	)).Methods("POST").Name("offer-requests")
+
 	r.Handle("/offers/{id}", Chain(
 		&OfferHandler{App: a, Method: "update"},
 		&SentryMiddleware{},
@@ -138,6 +273,7 @@ func (a *App) getRouter() *mux.Router {
 		&AuthMiddleware{App: a, useBasicAuth: true},
 		NewParamKeyMiddleware(a, govalidator.IsUUIDv4),
 		NewValidationMiddleware(func() interface{} { return &models.Offer{} }),
+		NewRoleMiddleware(a, models.RoleEditor, gameIDFromPayload),
 	)).Methods("PUT").Name("offers")
 
 	r.Handle("/offers/{id}/enable", Chain(
@@ -146,6 +282,7 @@ func (a *App) getRouter() *mux.Router {
 		&NewRelicMiddleware{App: a},
 		&AuthMiddleware{App: a, useBasicAuth: true},
 		NewParamKeyMiddleware(a, govalidator.IsUUIDv4),
+		NewRoleMiddleware(a, models.RolePublisher, gameIDFromQuery),
 	)).Methods("PUT").Name("offers")
 
 	r.Handle("/offers/{id}/disable", Chain(
@@ -154,20 +291,166 @@ func (a *App) getRouter() *mux.Router {
 		&NewRelicMiddleware{App: a},
 		&AuthMiddleware{App: a, useBasicAuth: true},
 		NewParamKeyMiddleware(a, govalidator.IsUUIDv4),
+		NewRoleMiddleware(a, models.RolePublisher, gameIDFromQuery),
 	)).Methods("PUT").Name("offers")
 
+	r.Handle("/offers/{id}/draft", Chain(
+		&OfferHandler{App: a, Method: "get-draft"},
+		&SentryMiddleware{},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, govalidator.IsUUIDv4),
+		NewRoleMiddleware(a, models.RoleViewer, gameIDFromQuery),
+	)).Methods("GET").Name("offers")
+
+	r.Handle("/offers/{id}/draft", Chain(
+		&OfferHandler{App: a, Method: "save-draft"},
+		&SentryMiddleware{},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, govalidator.IsUUIDv4),
+		NewValidationMiddleware(func() interface{} { return &models.Offer{} }),
+		NewRoleMiddleware(a, models.RoleEditor, gameIDFromPayload),
+	)).Methods("PUT").Name("offers")
+
+	r.Handle("/offers/{id}/draft", Chain(
+		&OfferHandler{App: a, Method: "delete-draft"},
+		&SentryMiddleware{},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, govalidator.IsUUIDv4),
+		NewRoleMiddleware(a, models.RoleEditor, gameIDFromQuery),
+	)).Methods("DELETE").Name("offers")
+
+	r.Handle("/offers/{id}/publish", Chain(
+		&OfferHandler{App: a, Method: "publish"},
+		&SentryMiddleware{},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, govalidator.IsUUIDv4),
+		NewRoleMiddleware(a, models.RolePublisher, gameIDFromQuery),
+	)).Methods("POST").Name("offers")
+
+	r.Handle("/offers/{id}/schedule", Chain(
+		&OfferHandler{App: a, Method: "schedule"},
+		&SentryMiddleware{},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, govalidator.IsUUIDv4),
+		NewValidationMiddleware(func() interface{} { return &models.ScheduledOperation{} }),
+		NewRoleMiddleware(a, models.RolePublisher, gameIDFromPayload),
+	)).Methods("POST").Name("offers")
+
+	r.Handle("/offers/{id}/schedule", Chain(
+		&OfferHandler{App: a, Method: "list-schedule"},
+		&SentryMiddleware{},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, govalidator.IsUUIDv4),
+		NewRoleMiddleware(a, models.RoleViewer, gameIDFromQuery),
+	)).Methods("GET").Name("offers")
+
+	r.Handle("/audit", Chain(
+		&AuditHandler{App: a},
+		&SentryMiddleware{},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewRoleMiddleware(a, models.RoleAdmin, gameIDFromQuery),
+	)).Methods("GET").Name("audit")
+
+	r.Handle("/claims", Chain(
+		&ClaimHandler{App: a},
+		&SentryMiddleware{},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewRoleMiddleware(a, models.RoleViewer, gameIDFromQuery),
+	)).Methods("GET").Name("claims")
+
+	r.Handle("/players/{id}/offers", Chain(
+		&PlayerHandler{App: a, Method: "list-offers"},
+		&SentryMiddleware{},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, isValidPlayerID),
+		NewRoleMiddleware(a, models.RoleViewer, gameIDFromQuery),
+	)).Methods("GET").Name("players")
+
+	r.Handle("/players/merge", Chain(
+		&PlayerHandler{App: a, Method: "merge"},
+		&SentryMiddleware{},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewValidationMiddleware(func() interface{} { return &models.MergePlayersPayload{} }),
+		NewRoleMiddleware(a, models.RolePublisher, gameIDFromPayload),
+	)).Methods("POST").Name("players")
+
+	r.Handle("/players/{id}/offers", Chain(
+		&PlayerHandler{App: a, Method: "reset-offer"},
+		&SentryMiddleware{},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, isValidPlayerID),
+		NewRoleMiddleware(a, models.RolePublisher, gameIDFromQuery),
+	)).Methods("DELETE").Name("players")
+
+	r.Handle("/players/{id}/export", Chain(
+		&PlayerHandler{App: a, Method: "export"},
+		&SentryMiddleware{},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, isValidPlayerID),
+		NewRoleMiddleware(a, models.RoleAdmin, gameIDFromQuery),
+	)).Methods("GET").Name("players")
+
+	r.Handle("/players/{id}", Chain(
+		&PlayerHandler{App: a, Method: "erase"},
+		&SentryMiddleware{},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewParamKeyMiddleware(a, isValidPlayerID),
+		NewRoleMiddleware(a, models.RoleAdmin, gameIDFromQuery),
+	)).Methods("DELETE").Name("players")
+
+	r.Handle("/players/{id}/reset", Chain(
+		&PlayerHandler{App: a, Method: "reset"},
+		&SentryMiddleware{},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a},
+		NewSignatureMiddleware(a, signedGameIDFromQuery),
+		NewParamKeyMiddleware(a, isValidPlayerID),
+	)).Methods("POST").Name("players")
+
 	r.Handle("/available-offers", Chain(
 		&OfferRequestHandler{App: a, Method: "get-offers"},
 		&SentryMiddleware{},
 		&NewRelicMiddleware{App: a},
 		&AuthMiddleware{App: a},
+		NewSignatureMiddleware(a, signedGameIDFromQuery),
+	)).Methods("GET").Name("offer-requests")
+
+	r.Handle("/available-offers/explain", Chain(
+		&OfferRequestHandler{App: a, Method: "explain-offers"},
+		&SentryMiddleware{},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewRoleMiddleware(a, models.RoleViewer, gameIDFromQuery),
 	)).Methods("GET").Name("offer-requests")
 
+	r.Handle("/available-offers/preview", Chain(
+		&OfferRequestHandler{App: a, Method: "preview-offers"},
+		&SentryMiddleware{},
+		&NewRelicMiddleware{App: a},
+		&AuthMiddleware{App: a, useBasicAuth: true},
+		NewValidationMiddleware(func() interface{} { return &models.PreviewOffersPayload{} }),
+		NewRoleMiddleware(a, models.RoleViewer, gameIDFromPayload),
+	)).Methods("POST").Name("offer-requests")
+
 	r.HandleFunc("/offers/{id}/impressions", Chain(
 		&OfferRequestHandler{App: a, Method: "impressions"},
 		&SentryMiddleware{},
 		&NewRelicMiddleware{App: a},
 		&AuthMiddleware{App: a},
+		NewSignatureMiddleware(a, signedGameIDFromBody),
 		NewParamKeyMiddleware(a, govalidator.IsUUIDv4),
 		NewValidationMiddleware(func() interface{} { return &models.OfferImpressionPayload{} }),
 	).ServeHTTP).Methods("PUT").Name("offer-requests")
@@ -177,6 +460,7 @@ func (a *App) getRouter() *mux.Router {
 		&SentryMiddleware{},
 		&NewRelicMiddleware{App: a},
 		&AuthMiddleware{App: a},
+		NewSignatureMiddleware(a, signedGameIDFromQuery),
 	)).Methods("GET").Name("offer-requests")
 
 	return r
@@ -206,7 +490,11 @@ func (a *App) configureApp() error {
 	a.configurePagination()
 	a.configureServer()
 	a.configureCache()
-	return nil
+	a.configureScheduler()
+	a.configureRBAC()
+	a.configureSigning()
+	a.configureOfferTokens()
+	return a.configureReceiptVerifiers()
 }
 
 func (a *App) configureMetricsReporter() error {
@@ -376,6 +664,12 @@ func (a *App) ListenAndServe() (io.Closer, error) {
 		return nil, err
 	}
 
+	if a.Config.GetBool("scheduler.enabled") {
+		stop := make(chan struct{})
+		defer close(stop)
+		go a.RunScheduler(stop)
+	}
+
 	err = a.Server.Serve(listener)
 	if err != nil {
 		listener.Close()
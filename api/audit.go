@@ -0,0 +1,92 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/topfreegames/offers/models"
+)
+
+//AuditHandler handler
+type AuditHandler struct {
+	App *App
+}
+
+//ServeHTTP method
+func (h *AuditHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+
+	vars := r.URL.Query()
+	gameID := vars.Get("game-id")
+	offerID := vars.Get("offer-id")
+	sinceStr := vars.Get("since")
+	limitStr := vars.Get("limit")
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "auditHandler",
+		"operation": "list",
+		"userEmail": userEmail,
+		"gameID":    gameID,
+		"offerID":   offerID,
+	})
+
+	if gameID == "" {
+		err := fmt.Errorf("The game-id parameter cannot be empty")
+		logger.WithError(err).Error("List audit events failed.")
+		h.App.HandleError(w, http.StatusBadRequest, "The game-id parameter cannot be empty.", err)
+		return
+	}
+
+	since := time.Unix(0, 0)
+	if sinceStr != "" {
+		sinceUnix, err := strconv.ParseInt(sinceStr, 10, 64)
+		if err != nil {
+			logger.WithError(err).Error("List audit events failed.")
+			h.App.HandleError(w, http.StatusBadRequest, "The since parameter must be an unix timestamp.", err)
+			return
+		}
+		since = time.Unix(sinceUnix, 0)
+	}
+
+	limit := h.App.Pagination.Limit
+	if limitStr != "" {
+		var err error
+		limit, err = strconv.ParseUint(limitStr, 10, 64)
+		if err != nil {
+			logger.WithError(err).Error("List audit events failed.")
+			h.App.HandleError(w, http.StatusBadRequest, "The limit parameter must be an uint.", err)
+			return
+		}
+	}
+
+	var err error
+	var events []*models.AuditEvent
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		events, err = models.ListAuditEvents(r.Context(), h.App.DB, gameID, offerID, since, limit, mr)
+		return err
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("List audit events failed.")
+		h.App.HandleError(w, http.StatusInternalServerError, "List audit events failed.", err)
+		return
+	}
+
+	logger.Info("Listed audit events successfully.")
+	bts, _ := json.Marshal(map[string]interface{}{
+		"events": events,
+	})
+	WriteBytes(w, http.StatusOK, bts)
+}
@@ -0,0 +1,88 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/topfreegames/offers/testing"
+)
+
+var _ = Describe("Audit Handler", func() {
+	var recorder *httptest.ResponseRecorder
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+	})
+
+	Describe("GET /audit", func() {
+		It("should return the changes made through the API", func() {
+			request, _ := http.NewRequest("PUT", "/offers/dd21ec96-2890-4ba0-b8e2-40ea67196990/disable?game-id=offers-game", JSONFor(JSON{}))
+			request.Header.Set("x-forwarded-email", "admin@tfgco.com")
+			request.Header.Set("x-request-id", "request-1")
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("x-request-id")).To(Equal("request-1"))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/audit?game-id=offers-game&offer-id=dd21ec96-2890-4ba0-b8e2-40ea67196990&since=0", nil)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+			var obj map[string][]map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["events"]).To(HaveLen(1))
+			Expect(obj["events"][0]["action"]).To(Equal("disable-offer"))
+			Expect(obj["events"][0]["actor"]).To(Equal("admin@tfgco.com"))
+			Expect(obj["events"][0]["requestId"]).To(Equal("request-1"))
+			Expect(obj["events"][0]["before"].(map[string]interface{})["enabled"]).To(BeTrue())
+			Expect(obj["events"][0]["after"].(map[string]interface{})["enabled"]).To(BeFalse())
+		})
+
+		It("should generate a request id if none is given", func() {
+			request, _ := http.NewRequest("GET", "/audit?game-id=offers-game", nil)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("x-request-id")).NotTo(BeEmpty())
+		})
+
+		It("should return status code 400 if game-id is missing", func() {
+			request, _ := http.NewRequest("GET", "/audit", nil)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should return status code 400 if since is not a timestamp", func() {
+			request, _ := http.NewRequest("GET", "/audit?game-id=offers-game&since=yesterday", nil)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["error"]).To(Equal("The since parameter must be an unix timestamp."))
+		})
+
+		It("should return status code of 401 if no auth provided", func() {
+			defer func() {
+				config.Set("basicauth.username", "")
+				config.Set("basicauth.password", "")
+			}()
+			config.Set("basicauth.username", "user")
+			config.Set("basicauth.password", "pass")
+			request, _ := http.NewRequest("GET", "/audit?game-id=offers-game", nil)
+
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+})
@@ -10,6 +10,10 @@ package api
 import (
 	"context"
 	"net/http"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
 )
 
 //AuthMiddleware automatically adds a user email to the context
@@ -20,6 +24,7 @@ type AuthMiddleware struct {
 }
 
 const userEmailKey = contextKey("userEmail")
+const principalKey = contextKey("principal")
 
 func newContextWithUserEmail(ctx context.Context, r *http.Request) context.Context {
 	userEmail := r.Header.Get("x-forwarded-email")
@@ -31,13 +36,47 @@ func userEmailFromContext(ctx context.Context) string {
 	return ctx.Value(userEmailKey).(string)
 }
 
+//principalFromContext returns who roles are checked against: the API key if one was used,
+//the user email otherwise
+func principalFromContext(ctx context.Context) string {
+	principal := ctx.Value(principalKey)
+	if principal == nil {
+		return ""
+	}
+	return principal.(string)
+}
+
 // ServeHTTP method
 func (m *AuthMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := newContextWithUserEmail(r.Context(), r)
+	principal := userEmailFromContext(ctx)
+
+	authenticatedByKey := false
+	if key := r.Header.Get("x-api-key"); m.useBasicAuth && key != "" {
+		apiKey, err := models.GetAPIKeyByKey(ctx, m.App.DB, key, nil)
+		if err != nil {
+			if _, ok := err.(*errors.ModelNotFoundError); ok {
+				Write(w, http.StatusUnauthorized, "Authentication failed.")
+				return
+			}
+			m.App.HandleError(w, http.StatusInternalServerError, "Authentication failed", err)
+			return
+		}
+		principal = apiKey.Principal()
+		authenticatedByKey = true
+	}
+
+	requestID := r.Header.Get("x-request-id")
+	if requestID == "" {
+		requestID = uuid.NewV4().String()
+	}
+	w.Header().Set("x-request-id", requestID)
+	ctx = context.WithValue(ctx, principalKey, principal)
+	ctx = models.NewContextWithAuditInfo(ctx, principal, requestID)
 
 	basicAuthUser := m.App.Config.GetString("basicauth.username")
 	basicAuthPass := m.App.Config.GetString("basicauth.password")
-	if m.useBasicAuth && basicAuthUser != "" && basicAuthPass != "" {
+	if m.useBasicAuth && !authenticatedByKey && basicAuthUser != "" && basicAuthPass != "" {
 		user, pass, ok := r.BasicAuth()
 		if !ok {
 			Write(w, http.StatusUnauthorized, "Authentication failed.")
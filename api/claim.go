@@ -0,0 +1,92 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/topfreegames/offers/models"
+)
+
+//ClaimHandler handler
+type ClaimHandler struct {
+	App *App
+}
+
+//ServeHTTP method
+func (h *ClaimHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+
+	vars := r.URL.Query()
+	gameID := vars.Get("game-id")
+	playerID := vars.Get("player-id")
+	sinceStr := vars.Get("since")
+	limitStr := vars.Get("limit")
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "claimHandler",
+		"operation": "list",
+		"userEmail": userEmail,
+		"gameID":    gameID,
+		"playerID":  playerID,
+	})
+
+	if gameID == "" {
+		err := fmt.Errorf("The game-id parameter cannot be empty")
+		logger.WithError(err).Error("List claims failed.")
+		h.App.HandleError(w, http.StatusBadRequest, "The game-id parameter cannot be empty.", err)
+		return
+	}
+
+	since := time.Unix(0, 0)
+	if sinceStr != "" {
+		sinceUnix, err := strconv.ParseInt(sinceStr, 10, 64)
+		if err != nil {
+			logger.WithError(err).Error("List claims failed.")
+			h.App.HandleError(w, http.StatusBadRequest, "The since parameter must be an unix timestamp.", err)
+			return
+		}
+		since = time.Unix(sinceUnix, 0)
+	}
+
+	limit := h.App.Pagination.Limit
+	if limitStr != "" {
+		var err error
+		limit, err = strconv.ParseUint(limitStr, 10, 64)
+		if err != nil {
+			logger.WithError(err).Error("List claims failed.")
+			h.App.HandleError(w, http.StatusBadRequest, "The limit parameter must be an uint.", err)
+			return
+		}
+	}
+
+	var err error
+	var claims []*models.Claim
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		claims, err = models.ListClaims(r.Context(), h.App.DB, gameID, playerID, since, limit, mr)
+		return err
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("List claims failed.")
+		h.App.HandleError(w, http.StatusInternalServerError, "List claims failed.", err)
+		return
+	}
+
+	logger.Info("Listed claims successfully.")
+	bts, _ := json.Marshal(map[string]interface{}{
+		"claims": claims,
+	})
+	WriteBytes(w, http.StatusOK, bts)
+}
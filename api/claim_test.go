@@ -0,0 +1,75 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	uuid "github.com/satori/go.uuid"
+	. "github.com/topfreegames/offers/testing"
+)
+
+var _ = Describe("Claim Handler", func() {
+	var recorder *httptest.ResponseRecorder
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+	})
+
+	Describe("GET /claims", func() {
+		It("should return the claims of the player", func() {
+			transactionID := uuid.NewV4().String()
+			request, _ := http.NewRequest("PUT", "/offers/claim", JSONFor(JSON{
+				"gameId":        "offers-game",
+				"playerId":      "player-1",
+				"timestamp":     app.Clock.GetTime().Unix(),
+				"transactionId": transactionID,
+				"id":            "eb7e8d2a-2739-4da3-aa31-7970b63bdad7",
+			}))
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/claims?game-id=offers-game&player-id=player-1&since=0", nil)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var obj map[string][]map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["claims"]).To(HaveLen(1))
+			Expect(obj["claims"][0]["transactionId"]).To(Equal(transactionID))
+			Expect(obj["claims"][0]["offerVersionId"]).To(Equal("eb7e8d2a-2739-4da3-aa31-7970b63bdad7"))
+			Expect(obj["claims"][0]["offerVersion"]).To(BeEquivalentTo(3))
+			Expect(obj["claims"][0]["productId"]).To(Equal("com.tfg.sample"))
+			Expect(obj["claims"][0]["cost"]).To(Equal(map[string]interface{}{"gems": float64(500)}))
+		})
+
+		It("should return an empty list if there are no claims", func() {
+			request, _ := http.NewRequest("GET", "/claims?game-id=offers-game&player-id=no-claims-player", nil)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"claims": []}`))
+		})
+
+		It("should return status code 400 if game-id is missing", func() {
+			request, _ := http.NewRequest("GET", "/claims", nil)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should return status code 400 if since is not a timestamp", func() {
+			request, _ := http.NewRequest("GET", "/claims?game-id=offers-game&since=yesterday", nil)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
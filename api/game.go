@@ -10,8 +10,10 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/topfreegames/offers/errors"
 	"github.com/topfreegames/offers/models"
 )
 
@@ -30,6 +32,9 @@ func (g *GameHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case "upsert":
 		g.upsert(w, r)
 		return
+	case "rotate-client-key":
+		g.rotateClientKey(w, r)
+		return
 	}
 }
 
@@ -46,6 +51,10 @@ func (g *GameHandler) list(w http.ResponseWriter, r *http.Request) {
 	var games []*models.Game
 	err = mr.WithSegment(models.SegmentModel, func() error {
 		games, err = models.ListGames(r.Context(), g.App.DB, mr)
+		if err != nil {
+			return err
+		}
+		games, err = g.App.visibleGames(r.Context(), games)
 		return err
 	})
 
@@ -83,10 +92,58 @@ func (g *GameHandler) upsert(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		logger.WithError(err).Error("Upserting game failed.")
+		if invalidModel, ok := err.(*errors.InvalidModelError); ok {
+			g.App.HandleError(w, http.StatusUnprocessableEntity, invalidModel.Error(), invalidModel)
+			return
+		}
 		g.App.HandleError(w, http.StatusInternalServerError, "Upserting game failed", err)
 		return
 	}
+	g.App.Cache.Delete(models.GetImpressionCapKey(game.ID))
 	logger.Info("Upserted game successfully.")
 	bytesRes, _ := json.Marshal(map[string]interface{}{"gameId": game.ID})
 	WriteBytes(w, http.StatusOK, bytesRes)
 }
+
+func (g *GameHandler) rotateClientKey(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	gameID := paramKeyFromContext(r.Context())
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := g.App.Logger.WithFields(logrus.Fields{
+		"source":    "gameHandler",
+		"operation": "rotateClientKey",
+		"userEmail": userEmail,
+		"gameID":    gameID,
+	})
+
+	var game *models.Game
+	err := mr.WithSegment(models.SegmentModel, func() error {
+		var err error
+		currentTime := g.App.Clock.GetTime()
+		gracePeriod := time.Duration(g.App.Config.GetInt64("signing.gracePeriodSeconds")) * time.Second
+		game, err = models.RotateGameClientKey(r.Context(), g.App.DB, gameID, currentTime, gracePeriod, mr)
+		return err
+	})
+
+	if err != nil {
+		if modelNotFound, ok := err.(*errors.ModelNotFoundError); ok {
+			logger.WithError(err).Error("Game not found.")
+			g.App.HandleError(w, http.StatusNotFound, modelNotFound.Error(), modelNotFound)
+			return
+		}
+		logger.WithError(err).Error("Rotating client key failed.")
+		g.App.HandleError(w, http.StatusInternalServerError, "Rotating client key failed", err)
+		return
+	}
+	logger.Info("Rotated client key successfully.")
+	res := map[string]interface{}{
+		"gameId":    game.ID,
+		"clientKey": game.ClientKey,
+	}
+	if game.PreviousClientKeyExpiresAt.Valid {
+		res["previousClientKeyExpiresAt"] = game.PreviousClientKeyExpiresAt.Time.Unix()
+	}
+	bytesRes, _ := json.Marshal(res)
+	WriteBytes(w, http.StatusOK, bytesRes)
+}
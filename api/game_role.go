@@ -0,0 +1,144 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+)
+
+//GameRoleHandler handler
+type GameRoleHandler struct {
+	App    *App
+	Method string
+}
+
+//ServeHTTP method
+func (g *GameRoleHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch g.Method {
+	case "list":
+		g.list(w, r)
+		return
+	case "upsert":
+		g.upsert(w, r)
+		return
+	case "delete":
+		g.delete(w, r)
+		return
+	}
+}
+
+func (g *GameRoleHandler) list(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	gameID := paramKeyFromContext(r.Context())
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := g.App.Logger.WithFields(logrus.Fields{
+		"source":    "gameRoleHandler",
+		"operation": "list",
+		"userEmail": userEmail,
+		"gameID":    gameID,
+	})
+
+	var err error
+	var roles []*models.GameRole
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		roles, err = models.ListGameRoles(r.Context(), g.App.DB, gameID, mr)
+		return err
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("List game roles failed.")
+		g.App.HandleError(w, http.StatusInternalServerError, "List game roles failed.", err)
+		return
+	}
+
+	logger.Info("Listed game roles successfully.")
+	bytesRes, _ := json.Marshal(map[string]interface{}{"roles": roles})
+	WriteBytes(w, http.StatusOK, bytesRes)
+}
+
+func (g *GameRoleHandler) upsert(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	role := gameRoleFromCtx(r.Context())
+	role.GameID = paramKeyFromContext(r.Context())
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := g.App.Logger.WithFields(logrus.Fields{
+		"source":    "gameRoleHandler",
+		"operation": "upsert",
+		"userEmail": userEmail,
+		"role":      role,
+	})
+
+	err := mr.WithSegment(models.SegmentModel, func() error {
+		return models.UpsertGameRole(r.Context(), g.App.DB, role, g.App.Clock.GetTime(), mr)
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Upsert game role failed.")
+		if invalidModel, ok := err.(*errors.InvalidModelError); ok {
+			g.App.HandleError(w, http.StatusUnprocessableEntity, invalidModel.Error(), invalidModel)
+			return
+		}
+		g.App.HandleError(w, http.StatusInternalServerError, "Upsert game role failed", err)
+		return
+	}
+
+	logger.Info("Upserted game role successfully.")
+	bytesRes, _ := json.Marshal(role)
+	WriteBytes(w, http.StatusOK, bytesRes)
+}
+
+func (g *GameRoleHandler) delete(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	gameID := paramKeyFromContext(r.Context())
+	principal := r.URL.Query().Get("principal")
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := g.App.Logger.WithFields(logrus.Fields{
+		"source":    "gameRoleHandler",
+		"operation": "delete",
+		"userEmail": userEmail,
+		"gameID":    gameID,
+		"principal": principal,
+	})
+
+	if principal == "" {
+		err := fmt.Errorf("The principal parameter cannot be empty")
+		logger.WithError(err).Error("Delete game role failed.")
+		g.App.HandleError(w, http.StatusBadRequest, "The principal parameter cannot be empty.", err)
+		return
+	}
+
+	err := mr.WithSegment(models.SegmentModel, func() error {
+		return models.DeleteGameRole(r.Context(), g.App.DB, gameID, principal, mr)
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Delete game role failed.")
+		if modelNotFound, ok := err.(*errors.ModelNotFoundError); ok {
+			g.App.HandleError(w, http.StatusNotFound, modelNotFound.Error(), modelNotFound)
+			return
+		}
+		g.App.HandleError(w, http.StatusInternalServerError, "Delete game role failed", err)
+		return
+	}
+
+	logger.Info("Deleted game role successfully.")
+	bytesRes, _ := json.Marshal(map[string]interface{}{
+		"gameId":    gameID,
+		"principal": principal,
+	})
+	WriteBytes(w, http.StatusOK, bytesRes)
+}
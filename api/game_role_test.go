@@ -0,0 +1,281 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/topfreegames/offers/models"
+	. "github.com/topfreegames/offers/testing"
+	"gopkg.in/mgutz/dat.v2/dat"
+)
+
+var _ = Describe("Game Role Handler", func() {
+	var recorder *httptest.ResponseRecorder
+	offerID := "a411fbcf-dddc-4153-b42b-3f9b2684c965"
+
+	grant := func(principal, role string) {
+		err := models.UpsertGameRole(nil, app.DB, &models.GameRole{
+			GameID:    "offers-game",
+			Principal: principal,
+			Role:      role,
+		}, time.Unix(1486678000, 0), nil)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	offerReader := func() io.Reader {
+		return JSONFor(JSON{
+			"name":      "New Awesome Game",
+			"productId": "com.tfg.example",
+			"gameId":    "offers-game",
+			"contents":  dat.JSON([]byte(`{"gems": 5432}`)),
+			"period":    dat.JSON([]byte(`{"max": 123}`)),
+			"frequency": dat.JSON([]byte(`{"every": "240h"}`)),
+			"trigger":   dat.JSON([]byte(`{"from": 123456789101, "to": 123456789111}`)),
+			"placement": "popup",
+		})
+	}
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+		config.Set("rbac.enabled", true)
+		config.Set("rbac.superusers", []string{"root@tfgco.com"})
+	})
+
+	AfterEach(func() {
+		config.Set("rbac.enabled", false)
+		config.Set("rbac.superusers", []string{})
+	})
+
+	Describe("Role enforcement", func() {
+		It("should return status code 403 if principal has no role in the game", func() {
+			request, _ := http.NewRequest("PUT", "/offers/"+offerID, offerReader())
+			request.Header.Set("x-forwarded-email", "user@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusForbidden))
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["code"]).To(Equal("OFF-005"))
+			Expect(obj["error"]).To(Equal("AccessDeniedError"))
+		})
+
+		It("should return status code 403 if role is not enough", func() {
+			grant("user@tfgco.com", models.RoleViewer)
+
+			request, _ := http.NewRequest("PUT", "/offers/"+offerID, offerReader())
+			request.Header.Set("x-forwarded-email", "user@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusForbidden))
+		})
+
+		It("should let editors update offers but not enable them", func() {
+			grant("user@tfgco.com", models.RoleEditor)
+
+			request, _ := http.NewRequest("PUT", "/offers/"+offerID, offerReader())
+			request.Header.Set("x-forwarded-email", "user@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("PUT", "/offers/"+offerID+"/enable?game-id=offers-game", nil)
+			request.Header.Set("x-forwarded-email", "user@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusForbidden))
+		})
+
+		It("should let superusers do anything", func() {
+			request, _ := http.NewRequest("PUT", "/offers/"+offerID+"/enable?game-id=offers-game", nil)
+			request.Header.Set("x-forwarded-email", "root@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+		})
+
+		It("should only list the games the principal has a role in", func() {
+			grant("user@tfgco.com", models.RoleViewer)
+
+			request, _ := http.NewRequest("GET", "/games", nil)
+			request.Header.Set("x-forwarded-email", "user@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var obj []map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj).To(HaveLen(1))
+			Expect(obj[0]["id"]).To(Equal("offers-game"))
+		})
+	})
+
+	Describe("PUT /games/{id}/roles", func() {
+		It("should let game admins grant roles", func() {
+			grant("admin@tfgco.com", models.RoleAdmin)
+
+			request, _ := http.NewRequest("PUT", "/games/offers-game/roles", JSONFor(JSON{
+				"principal": "user@tfgco.com",
+				"role":      "publisher",
+			}))
+			request.Header.Set("x-forwarded-email", "admin@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			role, err := models.GetGameRole(nil, app.DB, "offers-game", "user@tfgco.com", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(role.Role).To(Equal(models.RolePublisher))
+		})
+
+		It("should return status code 422 if role is invalid", func() {
+			request, _ := http.NewRequest("PUT", "/games/offers-game/roles", JSONFor(JSON{
+				"principal": "user@tfgco.com",
+				"role":      "owner",
+			}))
+			request.Header.Set("x-forwarded-email", "root@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		})
+
+		It("should return status code 403 if principal is not a game admin", func() {
+			grant("user@tfgco.com", models.RolePublisher)
+
+			request, _ := http.NewRequest("PUT", "/games/offers-game/roles", JSONFor(JSON{
+				"principal": "user@tfgco.com",
+				"role":      "admin",
+			}))
+			request.Header.Set("x-forwarded-email", "user@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusForbidden))
+		})
+	})
+
+	Describe("GET /games/{id}/roles", func() {
+		It("should list the roles of the game", func() {
+			grant("user@tfgco.com", models.RoleViewer)
+
+			request, _ := http.NewRequest("GET", "/games/offers-game/roles", nil)
+			request.Header.Set("x-forwarded-email", "root@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var obj map[string][]map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["roles"]).To(HaveLen(1))
+			Expect(obj["roles"][0]["principal"]).To(Equal("user@tfgco.com"))
+			Expect(obj["roles"][0]["role"]).To(Equal("viewer"))
+		})
+	})
+
+	Describe("DELETE /games/{id}/roles", func() {
+		It("should revoke a role", func() {
+			grant("user@tfgco.com", models.RoleViewer)
+
+			request, _ := http.NewRequest("DELETE", "/games/offers-game/roles?principal=user@tfgco.com", nil)
+			request.Header.Set("x-forwarded-email", "root@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			_, err := models.GetGameRole(nil, app.DB, "offers-game", "user@tfgco.com", nil)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should return status code 404 if principal has no role", func() {
+			request, _ := http.NewRequest("DELETE", "/games/offers-game/roles?principal=user@tfgco.com", nil)
+			request.Header.Set("x-forwarded-email", "root@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should return status code 400 if principal is missing", func() {
+			request, _ := http.NewRequest("DELETE", "/games/offers-game/roles", nil)
+			request.Header.Set("x-forwarded-email", "root@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("API keys", func() {
+		It("should let superusers create keys that authenticate requests", func() {
+			request, _ := http.NewRequest("POST", "/api-keys", JSONFor(JSON{
+				"name": "deploy-bot",
+			}))
+			request.Header.Set("x-forwarded-email", "root@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["name"]).To(Equal("deploy-bot"))
+			key := obj["key"].(string)
+			Expect(key).NotTo(BeEmpty())
+
+			grant("apikey:deploy-bot", models.RolePublisher)
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("PUT", "/offers/"+offerID+"/enable?game-id=offers-game", nil)
+			request.Header.Set("x-api-key", key)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+		})
+
+		It("should return status code 401 if key is unknown", func() {
+			request, _ := http.NewRequest("PUT", "/offers/"+offerID+"/enable?game-id=offers-game", nil)
+			request.Header.Set("x-api-key", "unknown-key")
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should return status code 403 if a game admin creates a key", func() {
+			grant("admin@tfgco.com", models.RoleAdmin)
+
+			request, _ := http.NewRequest("POST", "/api-keys", JSONFor(JSON{
+				"name": "deploy-bot",
+			}))
+			request.Header.Set("x-forwarded-email", "admin@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusForbidden))
+		})
+
+		It("should return status code 409 if name is taken", func() {
+			_, err := models.CreateAPIKey(nil, app.DB, "deploy-bot", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			request, _ := http.NewRequest("POST", "/api-keys", JSONFor(JSON{
+				"name": "deploy-bot",
+			}))
+			request.Header.Set("x-forwarded-email", "root@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusConflict))
+		})
+
+		It("should delete keys", func() {
+			_, err := models.CreateAPIKey(nil, app.DB, "deploy-bot", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			request, _ := http.NewRequest("DELETE", "/api-keys/deploy-bot", nil)
+			request.Header.Set("x-forwarded-email", "root@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+		})
+	})
+})
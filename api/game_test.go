@@ -63,6 +63,18 @@ var _ = Describe("Game Handler", func() {
 			Expect(obj["description"]).To(Equal("Name: non zero value required;"))
 		})
 
+		It("should return status code of 422 if the impression cap is invalid", func() {
+			id := uuid.NewV4().String()
+			gameReader := JSONFor(JSON{
+				"Name":     "Game Awesome Name",
+				"Metadata": map[string]interface{}{"impressionCap": map[string]interface{}{"every": "6h", "max": 1.5}},
+			})
+			request, _ := http.NewRequest("PUT", fmt.Sprintf("/games/%s", id), gameReader)
+
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity), recorder.Body.String())
+		})
+
 		It("should return status code of 422 if invalid name", func() {
 			reallyBigName := "1234567890"
 			for i := 0; i < 5; i++ {
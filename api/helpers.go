@@ -7,7 +7,13 @@
 
 package api
 
-import "net/http"
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/asaskevich/govalidator"
+)
 
 type responseWriter struct {
 	http.ResponseWriter
@@ -34,3 +40,30 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+func isValidGameID(id string) bool {
+	return govalidator.Matches(id, "^[^-][a-zA-Z0-9-_]*$") && govalidator.StringLength(id, "1", "255")
+}
+
+func isValidAPIKeyName(name string) bool {
+	return govalidator.Matches(name, "^[a-zA-Z0-9-_\\.]+$") && govalidator.StringLength(name, "1", "200")
+}
+
+func isValidPlayerID(id string) bool {
+	return govalidator.IsASCII(id) && govalidator.StringLength(id, "1", "1000")
+}
+
+//filterAttrsFromQuery returns the query parameters, except the ignored ones, as filter attributes
+func filterAttrsFromQuery(query url.Values, ignored ...string) (map[string]string, error) {
+	filterAttrs := make(map[string]string)
+	for _, key := range ignored {
+		delete(query, key)
+	}
+	for k, v := range query {
+		if len(v) == 0 || len(v) > 1 {
+			return nil, fmt.Errorf("Filter attribute passed with invalid number of arguments. Key: %s", k)
+		}
+		filterAttrs[k] = v[0]
+	}
+	return filterAttrs, nil
+}
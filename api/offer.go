@@ -8,6 +8,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	e "errors"
 	"fmt"
@@ -43,9 +44,49 @@ func (g *OfferHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case "list":
 		g.list(w, r)
 		return
+	case "get-draft":
+		g.getDraft(w, r)
+		return
+	case "save-draft":
+		g.saveDraft(w, r)
+		return
+	case "delete-draft":
+		g.deleteDraft(w, r)
+		return
+	case "publish":
+		g.publish(w, r)
+		return
+	case "schedule":
+		g.schedule(w, r)
+		return
+	case "list-schedule":
+		g.listSchedule(w, r)
+		return
 	}
 }
 
+func (g *OfferHandler) validateCost(w http.ResponseWriter, offer *models.Offer, failMsg string) bool {
+	if offer.ProductID != "" {
+		return true
+	}
+	validationError := errors.NewValidationFailedError(e.New("Cost and ProductID cannot be both null"))
+	if offer.Cost == nil {
+		g.App.HandleError(w, http.StatusUnprocessableEntity, validationError.Error(), validationError)
+		return false
+	}
+	var costVal map[string]interface{}
+	err := offer.Cost.Unmarshal(&costVal)
+	if err != nil {
+		g.App.HandleError(w, http.StatusInternalServerError, failMsg, err)
+		return false
+	}
+	if len(costVal) == 0 {
+		g.App.HandleError(w, http.StatusUnprocessableEntity, validationError.Error(), validationError)
+		return false
+	}
+	return true
+}
+
 func (g *OfferHandler) insertOffer(w http.ResponseWriter, r *http.Request) {
 	mr := metricsReporterFromCtx(r.Context())
 	offer := offerFromCtx(r.Context())
@@ -59,22 +100,8 @@ func (g *OfferHandler) insertOffer(w http.ResponseWriter, r *http.Request) {
 	})
 
 	var err error
-	if offer.ProductID == "" {
-		validationError := errors.NewValidationFailedError(e.New("Cost and ProductID cannot be both null"))
-		if offer.Cost == nil {
-			g.App.HandleError(w, http.StatusUnprocessableEntity, validationError.Error(), validationError)
-			return
-		}
-		var costVal map[string]interface{}
-		err := offer.Cost.Unmarshal(&costVal)
-		if err != nil {
-			g.App.HandleError(w, http.StatusInternalServerError, "Insert offer failed", err)
-			return
-		}
-		if len(costVal) == 0 {
-			g.App.HandleError(w, http.StatusUnprocessableEntity, validationError.Error(), validationError)
-			return
-		}
+	if !g.validateCost(w, offer, "Insert offer failed") {
+		return
 	}
 
 	err = mr.WithSegment(models.SegmentModel, func() error {
@@ -152,34 +179,35 @@ func (g *OfferHandler) updateOffer(w http.ResponseWriter, r *http.Request) {
 	})
 
 	var err error
-	if offer.ProductID == "" {
-		validationError := errors.NewValidationFailedError(e.New("Cost and ProductID cannot be both null"))
-		if offer.Cost == nil {
-			g.App.HandleError(w, http.StatusUnprocessableEntity, validationError.Error(), validationError)
-			return
-		}
-		var costVal map[string]interface{}
-		err := offer.Cost.Unmarshal(&costVal)
-		if err != nil {
-			g.App.HandleError(w, http.StatusInternalServerError, "Insert offer failed", err)
-			return
-		}
-		if len(costVal) == 0 {
-			g.App.HandleError(w, http.StatusUnprocessableEntity, validationError.Error(), validationError)
-			return
-		}
+	if !g.validateCost(w, offer, "Update offer failed") {
+		return
 	}
 
-	err = mr.WithSegment(models.SegmentModel, func() error {
-		offer, err = models.UpdateOffer(r.Context(), g.App.DB, offer, g.App.Cache, mr)
-		return err
-	})
+	requireApproval, err := g.requirePublishApproval(r.Context(), offer.GameID)
+	if _, ok := err.(*errors.ModelNotFoundError); ok {
+		//UpdateOffer reports the invalid offer
+		err = nil
+	}
+	if err == nil && requireApproval {
+		g.saveUpdateAsDraft(w, r, offer, logger)
+		return
+	}
+	if err == nil {
+		err = mr.WithSegment(models.SegmentModel, func() error {
+			offer, err = models.UpdateOffer(r.Context(), g.App.DB, offer, g.App.Cache, mr)
+			return err
+		})
+	}
 	if err != nil {
 		logger.WithError(err).Error("Update offer failed.")
 		if notFoundError, ok := err.(*errors.ModelNotFoundError); ok {
 			g.App.HandleError(w, http.StatusNotFound, notFoundError.Error(), notFoundError)
 			return
 		}
+		if invalidModelError, ok := err.(*errors.InvalidModelError); ok {
+			g.App.HandleError(w, http.StatusUnprocessableEntity, invalidModelError.Error(), invalidModelError)
+			return
+		}
 
 		g.App.HandleError(w, http.StatusInternalServerError, "Update offer failed", err)
 		return
@@ -196,6 +224,33 @@ func (g *OfferHandler) updateOffer(w http.ResponseWriter, r *http.Request) {
 	WriteBytes(w, http.StatusOK, bytesRes)
 }
 
+//saveUpdateAsDraft saves the update of an offer of a game that requires publish approval
+//as the offer draft, so it goes live only when another user publishes it
+func (g *OfferHandler) saveUpdateAsDraft(w http.ResponseWriter, r *http.Request, offer *models.Offer, logger logrus.FieldLogger) {
+	mr := metricsReporterFromCtx(r.Context())
+	draft := models.OfferDraftFromOffer(offer, principalFromContext(r.Context()))
+	err := mr.WithSegment(models.SegmentModel, func() error {
+		return models.UpsertOfferDraft(r.Context(), g.App.DB, draft, g.App.Clock.GetTime(), mr)
+	})
+	if err != nil {
+		logger.WithError(err).Error("Save offer update as draft failed.")
+		if notFoundError, ok := err.(*errors.ModelNotFoundError); ok {
+			g.App.HandleError(w, http.StatusNotFound, notFoundError.Error(), notFoundError)
+			return
+		}
+		if invalidModelError, ok := err.(*errors.InvalidModelError); ok {
+			g.App.HandleError(w, http.StatusUnprocessableEntity, invalidModelError.Error(), invalidModelError)
+			return
+		}
+		g.App.HandleError(w, http.StatusInternalServerError, "Update offer failed", err)
+		return
+	}
+
+	logger.Info("Saved offer update as draft, it requires publish approval.")
+	bytesRes, _ := json.Marshal(draft)
+	WriteBytes(w, http.StatusAccepted, bytesRes)
+}
+
 func (g *OfferHandler) list(w http.ResponseWriter, r *http.Request) {
 	mr := metricsReporterFromCtx(r.Context())
 
@@ -267,3 +322,256 @@ func (g *OfferHandler) list(w http.ResponseWriter, r *http.Request) {
 	bts, _ := json.Marshal(responseObj)
 	WriteBytes(w, http.StatusOK, bts)
 }
+
+func (g *OfferHandler) getDraft(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	offerID := paramKeyFromContext(r.Context())
+	userEmail := userEmailFromContext(r.Context())
+	gameID := r.URL.Query().Get("game-id")
+
+	logger := g.App.Logger.WithFields(logrus.Fields{
+		"source":    "offerHandler",
+		"operation": "getDraft",
+		"userEmail": userEmail,
+		"offerID":   offerID,
+		"gameID":    gameID,
+	})
+
+	var err error
+	var draft *models.OfferDraft
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		draft, err = models.GetOfferDraft(r.Context(), g.App.DB, gameID, offerID, mr)
+		return err
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Get offer draft failed.")
+		if modelNotFound, ok := err.(*errors.ModelNotFoundError); ok {
+			g.App.HandleError(w, http.StatusNotFound, modelNotFound.Error(), modelNotFound)
+			return
+		}
+		g.App.HandleError(w, http.StatusInternalServerError, "Get offer draft failed", err)
+		return
+	}
+
+	logger.Info("Retrieved offer draft successfully.")
+	bytesRes, _ := json.Marshal(draft)
+	WriteBytes(w, http.StatusOK, bytesRes)
+}
+
+func (g *OfferHandler) saveDraft(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	offer := offerFromCtx(r.Context())
+	userEmail := userEmailFromContext(r.Context())
+	offer.ID = paramKeyFromContext(r.Context())
+
+	logger := g.App.Logger.WithFields(logrus.Fields{
+		"source":    "offerHandler",
+		"operation": "saveDraft",
+		"userEmail": userEmail,
+		"offer":     offer,
+	})
+
+	if !g.validateCost(w, offer, "Save offer draft failed") {
+		return
+	}
+
+	draft := models.OfferDraftFromOffer(offer, principalFromContext(r.Context()))
+	err := mr.WithSegment(models.SegmentModel, func() error {
+		return models.UpsertOfferDraft(r.Context(), g.App.DB, draft, g.App.Clock.GetTime(), mr)
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Save offer draft failed.")
+		if modelNotFound, ok := err.(*errors.ModelNotFoundError); ok {
+			g.App.HandleError(w, http.StatusNotFound, modelNotFound.Error(), modelNotFound)
+			return
+		}
+		if invalidModel, ok := err.(*errors.InvalidModelError); ok {
+			g.App.HandleError(w, http.StatusUnprocessableEntity, invalidModel.Error(), invalidModel)
+			return
+		}
+		g.App.HandleError(w, http.StatusInternalServerError, "Save offer draft failed", err)
+		return
+	}
+
+	logger.Info("Saved offer draft successfully.")
+	bytesRes, _ := json.Marshal(draft)
+	WriteBytes(w, http.StatusOK, bytesRes)
+}
+
+func (g *OfferHandler) deleteDraft(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	offerID := paramKeyFromContext(r.Context())
+	userEmail := userEmailFromContext(r.Context())
+	gameID := r.URL.Query().Get("game-id")
+
+	logger := g.App.Logger.WithFields(logrus.Fields{
+		"source":    "offerHandler",
+		"operation": "deleteDraft",
+		"userEmail": userEmail,
+		"offerID":   offerID,
+		"gameID":    gameID,
+	})
+
+	err := mr.WithSegment(models.SegmentModel, func() error {
+		return models.DeleteOfferDraft(r.Context(), g.App.DB, gameID, offerID, mr)
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Delete offer draft failed.")
+		if modelNotFound, ok := err.(*errors.ModelNotFoundError); ok {
+			g.App.HandleError(w, http.StatusNotFound, modelNotFound.Error(), modelNotFound)
+			return
+		}
+		g.App.HandleError(w, http.StatusInternalServerError, "Delete offer draft failed", err)
+		return
+	}
+
+	logger.Info("Deleted offer draft successfully.")
+	bytesRes, _ := json.Marshal(map[string]interface{}{"id": offerID})
+	WriteBytes(w, http.StatusOK, bytesRes)
+}
+
+//requirePublishApproval reads requirePublishApproval from the metadata of a game
+func (g *OfferHandler) requirePublishApproval(ctx context.Context, gameID string) (bool, error) {
+	mr := metricsReporterFromCtx(ctx)
+	var err error
+	var game *models.Game
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		game, err = models.GetGameByID(ctx, g.App.DB, gameID, mr)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return game.RequirePublishApproval()
+}
+
+func (g *OfferHandler) publish(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	offerID := paramKeyFromContext(r.Context())
+	userEmail := userEmailFromContext(r.Context())
+	gameID := r.URL.Query().Get("game-id")
+
+	logger := g.App.Logger.WithFields(logrus.Fields{
+		"source":    "offerHandler",
+		"operation": "publish",
+		"userEmail": userEmail,
+		"offerID":   offerID,
+		"gameID":    gameID,
+	})
+
+	requireApproval, err := g.requirePublishApproval(r.Context(), gameID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to retrieve game.")
+		if modelNotFound, ok := err.(*errors.ModelNotFoundError); ok {
+			g.App.HandleError(w, http.StatusNotFound, modelNotFound.Error(), modelNotFound)
+			return
+		}
+		g.App.HandleError(w, http.StatusInternalServerError, "Failed to retrieve game", err)
+		return
+	}
+
+	var offer *models.Offer
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		offer, err = models.PublishOfferDraft(r.Context(), g.App.DB, gameID, offerID, principalFromContext(r.Context()), requireApproval, g.App.Cache, mr)
+		return err
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Publish offer draft failed.")
+		if modelNotFound, ok := err.(*errors.ModelNotFoundError); ok {
+			g.App.HandleError(w, http.StatusNotFound, modelNotFound.Error(), modelNotFound)
+			return
+		}
+		if invalidModel, ok := err.(*errors.InvalidModelError); ok {
+			g.App.HandleError(w, http.StatusUnprocessableEntity, invalidModel.Error(), invalidModel)
+			return
+		}
+		g.App.HandleError(w, http.StatusInternalServerError, "Publish offer draft failed", err)
+		return
+	}
+
+	logger.Info("Published offer draft successfully.")
+	bytesRes, _ := json.Marshal(offer)
+	WriteBytes(w, http.StatusOK, bytesRes)
+}
+
+func (g *OfferHandler) schedule(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	op := scheduledOperationFromCtx(r.Context())
+	userEmail := userEmailFromContext(r.Context())
+	op.OfferID = paramKeyFromContext(r.Context())
+	op.CreatedBy = principalFromContext(r.Context())
+
+	logger := g.App.Logger.WithFields(logrus.Fields{
+		"source":    "offerHandler",
+		"operation": "schedule",
+		"userEmail": userEmail,
+		"scheduled": op,
+	})
+
+	err := mr.WithSegment(models.SegmentModel, func() error {
+		return models.InsertScheduledOperation(r.Context(), g.App.DB, op, mr)
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Schedule offer operation failed.")
+		if modelNotFound, ok := err.(*errors.ModelNotFoundError); ok {
+			g.App.HandleError(w, http.StatusNotFound, modelNotFound.Error(), modelNotFound)
+			return
+		}
+		if invalidModel, ok := err.(*errors.InvalidModelError); ok {
+			g.App.HandleError(w, http.StatusUnprocessableEntity, invalidModel.Error(), invalidModel)
+			return
+		}
+		g.App.HandleError(w, http.StatusInternalServerError, "Schedule offer operation failed", err)
+		return
+	}
+
+	logger.Info("Scheduled offer operation successfully.")
+	bytesRes, _ := json.Marshal(op)
+	WriteBytes(w, http.StatusCreated, bytesRes)
+}
+
+func (g *OfferHandler) listSchedule(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	offerID := paramKeyFromContext(r.Context())
+	userEmail := userEmailFromContext(r.Context())
+	gameID := r.URL.Query().Get("game-id")
+
+	logger := g.App.Logger.WithFields(logrus.Fields{
+		"source":    "offerHandler",
+		"operation": "listSchedule",
+		"userEmail": userEmail,
+		"offerID":   offerID,
+		"gameID":    gameID,
+	})
+
+	if gameID == "" {
+		err := fmt.Errorf("The game-id parameter cannot be empty")
+		logger.WithError(err).Error("List offer schedule failed.")
+		g.App.HandleError(w, http.StatusBadRequest, "The game-id parameter cannot be empty.", err)
+		return
+	}
+
+	var err error
+	var ops []*models.ScheduledOperation
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		ops, err = models.ListScheduledOperations(r.Context(), g.App.DB, gameID, offerID, mr)
+		return err
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("List offer schedule failed.")
+		g.App.HandleError(w, http.StatusInternalServerError, "List offer schedule failed", err)
+		return
+	}
+
+	logger.Info("Listed offer schedule successfully.")
+	bytesRes, _ := json.Marshal(map[string]interface{}{
+		"operations": ops,
+	})
+	WriteBytes(w, http.StatusOK, bytesRes)
+}
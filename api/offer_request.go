@@ -12,6 +12,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	e "github.com/topfreegames/offers/errors"
@@ -29,8 +31,14 @@ func (h *OfferRequestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	switch h.Method {
 	case "get-offers":
 		h.getOffers(w, r)
+	case "explain-offers":
+		h.explainOffers(w, r)
+	case "preview-offers":
+		h.previewOffers(w, r)
 	case "claim":
 		h.claimOffer(w, r)
+	case "revoke-claim":
+		h.revokeClaim(w, r)
 	case "impressions":
 		h.viewOffer(w, r)
 	case "offer-info":
@@ -61,24 +69,16 @@ func (h *OfferRequestHandler) getOffers(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	currentTime := h.App.Clock.GetTime()
-	filterAttrsList := r.URL.Query()
-	filterAttrs := make(map[string]string)
-	delete(filterAttrsList, "player-id")
-	delete(filterAttrsList, "game-id")
-	for k, v := range filterAttrsList {
-		if len(v) == 0 || len(v) > 1 {
-			err := fmt.Errorf("Filter attribute passed with invalid number of arguments. Key: %s", k)
-			logger.WithError(err).Error("Failed to retrieve offer for player.")
-			h.App.HandleError(w, http.StatusBadRequest, "A filter parameter is invalid.", err)
-			return
-		}
-		filterAttrs[k] = v[0]
+	filterAttrs, err := filterAttrsFromQuery(r.URL.Query(), "player-id", "game-id")
+	if err != nil {
+		logger.WithError(err).Error("Failed to retrieve offer for player.")
+		h.App.HandleError(w, http.StatusBadRequest, "A filter parameter is invalid.", err)
+		return
 	}
 
 	maxAge := h.App.MaxAge
 	allowInefficientQueries := false
 	var game *models.Game
-	var err error
 	err = mr.WithSegment(models.SegmentModel, func() error {
 		game, err = models.GetGameByID(r.Context(), h.App.DB, gameID, mr)
 		return err
@@ -120,6 +120,7 @@ func (h *OfferRequestHandler) getOffers(w http.ResponseWriter, r *http.Request)
 		h.App.HandleError(w, http.StatusInternalServerError, "Failed to retrieve offer for player", err)
 		return
 	}
+	h.App.signOfferTokens(gameID, playerID, offers, currentTime)
 
 	bytes, err := json.Marshal(offers)
 	if err != nil {
@@ -134,6 +135,172 @@ func (h *OfferRequestHandler) getOffers(w http.ResponseWriter, r *http.Request)
 	WriteBytes(w, http.StatusOK, bytes)
 }
 
+func (h *OfferRequestHandler) explainOffers(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	playerID := r.URL.Query().Get("player-id")
+	gameID := r.URL.Query().Get("game-id")
+	userEmail := userEmailFromContext(r.Context())
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "offerHandler",
+		"operation": "explainOffers",
+		"userEmail": userEmail,
+		"gameID":    gameID,
+		"playerID":  playerID,
+	})
+
+	if playerID == "" {
+		err := fmt.Errorf("The player-id parameter cannot be empty")
+		logger.WithError(err).Error("Failed to explain offers for player.")
+		h.App.HandleError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	} else if gameID == "" {
+		err := fmt.Errorf("The game-id parameter cannot be empty")
+		logger.WithError(err).Error("Failed to explain offers for player.")
+		h.App.HandleError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	currentTime := h.App.Clock.GetTime()
+	if at := r.URL.Query().Get("at"); at != "" {
+		atUnix, err := strconv.ParseInt(at, 10, 64)
+		if err != nil {
+			logger.WithError(err).Error("Failed to explain offers for player.")
+			h.App.HandleError(w, http.StatusBadRequest, "The at parameter must be an unix timestamp.", err)
+			return
+		}
+		currentTime = time.Unix(atUnix, 0)
+	}
+	filterAttrs, err := filterAttrsFromQuery(r.URL.Query(), "player-id", "game-id", "at")
+	if err != nil {
+		logger.WithError(err).Error("Failed to explain offers for player.")
+		h.App.HandleError(w, http.StatusBadRequest, "A filter parameter is invalid.", err)
+		return
+	}
+
+	var game *models.Game
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		game, err = models.GetGameByID(r.Context(), h.App.DB, gameID, mr)
+		return err
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to retrieve game.")
+		if modelNotFound, ok := err.(*e.ModelNotFoundError); ok {
+			h.App.HandleError(w, http.StatusNotFound, modelNotFound.Error(), modelNotFound)
+			return
+		}
+		h.App.HandleError(w, http.StatusInternalServerError, "Failed to retrieve game", err)
+		return
+	}
+	metadata, err := game.GetMetadata()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get game metadata.")
+		h.App.HandleError(w, http.StatusInternalServerError, "Failed to get game metadata", err)
+		return
+	}
+	allowInefficientQueries, _ := metadata["allowInefficientQueries"].(bool)
+
+	var explanations []*models.OfferExplanation
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		explanations, err = models.ExplainAvailableOffers(r.Context(), h.App.DB, gameID, playerID, currentTime, filterAttrs, allowInefficientQueries, mr)
+		return err
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to explain offers for player.")
+		h.App.HandleError(w, http.StatusInternalServerError, "Failed to explain offers for player", err)
+		return
+	}
+
+	var impressionCaps []*models.ImpressionCapState
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		impressionCaps, err = models.GetImpressionCapStates(r.Context(), h.App.DB, gameID, playerID, currentTime, mr)
+		return err
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to get impression caps of player.")
+		h.App.HandleError(w, http.StatusInternalServerError, "Failed to get impression caps of player", err)
+		return
+	}
+
+	var rotations []*models.RotationState
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		rotations, err = models.GetRotationStates(r.Context(), h.App.DB, gameID, playerID, currentTime, mr)
+		return err
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to get rotations of player.")
+		h.App.HandleError(w, http.StatusInternalServerError, "Failed to get rotations of player", err)
+		return
+	}
+
+	logger.Info("Explained player offers successfully.")
+	bytes, _ := json.Marshal(map[string]interface{}{
+		"at":             currentTime.Unix(),
+		"offers":         explanations,
+		"impressionCaps": impressionCaps,
+		"rotations":      rotations,
+	})
+	WriteBytes(w, http.StatusOK, bytes)
+}
+
+func (h *OfferRequestHandler) previewOffers(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	payload := previewOffersPayloadFromCtx(r.Context())
+	userEmail := userEmailFromContext(r.Context())
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "offerHandler",
+		"operation": "previewOffers",
+		"userEmail": userEmail,
+		"payload":   payload,
+	})
+
+	var game *models.Game
+	var err error
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		game, err = models.GetGameByID(r.Context(), h.App.DB, payload.GameID, mr)
+		return err
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to retrieve game.")
+		if modelNotFound, ok := err.(*e.ModelNotFoundError); ok {
+			h.App.HandleError(w, http.StatusNotFound, modelNotFound.Error(), modelNotFound)
+			return
+		}
+		h.App.HandleError(w, http.StatusInternalServerError, "Failed to retrieve game", err)
+		return
+	}
+	metadata, err := game.GetMetadata()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get game metadata.")
+		h.App.HandleError(w, http.StatusInternalServerError, "Failed to get game metadata", err)
+		return
+	}
+	allowInefficientQueries, _ := metadata["allowInefficientQueries"].(bool)
+
+	clock := models.FixedClock{Time: time.Unix(payload.At, 0)}
+	var offers map[string][]*models.OfferToReturn
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		offers, err = models.PreviewAvailableOffers(
+			r.Context(), h.App.DB, payload.GameID, payload.PlayerID, clock,
+			payload.Attributes, allowInefficientQueries, payload.OfferPlayers, mr,
+		)
+		return err
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to preview offers.")
+		h.App.HandleError(w, http.StatusInternalServerError, "Failed to preview offers", err)
+		return
+	}
+
+	bytes, err := json.Marshal(offers)
+	if err != nil {
+		logger.WithError(err).Error("Failed to parse structs to JSON.")
+		h.App.HandleError(w, http.StatusInternalServerError, "Failed to parse structs to JSON", err)
+		return
+	}
+
+	logger.Info("Previewed offers successfully.")
+	WriteBytes(w, http.StatusOK, bytes)
+}
+
 func (h *OfferRequestHandler) claimOffer(w http.ResponseWriter, r *http.Request) {
 	mr := metricsReporterFromCtx(r.Context())
 	payload := claimOfferPayloadFromCtx(r.Context())
@@ -150,7 +317,19 @@ func (h *OfferRequestHandler) claimOffer(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	contents, alreadyClaimed, nextAt, err := models.ClaimOffer(
+	metadata, ok := h.gameMetadata(w, r, payload.GameID, logger)
+	if !ok {
+		return
+	}
+	if !h.checkOfferToken(w, metadata, payload.GameID, payload.PlayerID, payload.OfferInstanceID, payload.Token, logger) {
+		return
+	}
+	receipt, ok := h.verifyReceipt(w, r, metadata, payload, currentTime, logger)
+	if !ok {
+		return
+	}
+
+	contents, alreadyClaimed, nextAt, err := models.ClaimOfferWithReceipt(
 		r.Context(),
 		h.App.DB,
 		payload.GameID,
@@ -159,6 +338,7 @@ func (h *OfferRequestHandler) claimOffer(w http.ResponseWriter, r *http.Request)
 		payload.ProductID,
 		payload.TransactionID,
 		payload.Timestamp,
+		receipt,
 		currentTime,
 		mr,
 	)
@@ -169,6 +349,14 @@ func (h *OfferRequestHandler) claimOffer(w http.ResponseWriter, r *http.Request)
 			h.App.HandleError(w, http.StatusNotFound, modelNotFound.Error(), modelNotFound)
 			return
 		}
+		if soldOut, ok := err.(*e.OfferSoldOutError); ok {
+			h.App.HandleError(w, http.StatusGone, soldOut.Error(), soldOut)
+			return
+		}
+		if receiptRejected, ok := err.(*e.ReceiptRejectedError); ok {
+			h.App.HandleError(w, http.StatusPaymentRequired, receiptRejected.Error(), receiptRejected)
+			return
+		}
 
 		h.App.HandleError(w, http.StatusInternalServerError, err.Error(), err)
 		return
@@ -190,6 +378,63 @@ func (h *OfferRequestHandler) claimOffer(w http.ResponseWriter, r *http.Request)
 	WriteBytes(w, http.StatusOK, bytesRes)
 }
 
+func (h *OfferRequestHandler) revokeClaim(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	payload := revokeClaimPayloadFromCtx(r.Context())
+	currentTime := h.App.Clock.GetTime()
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "offerHandler",
+		"operation": "revokeClaim",
+		"payload":   payload,
+	})
+
+	var offerPlayer *models.OfferPlayer
+	var revocation *models.ClaimRevocation
+	var alreadyRevoked bool
+	err := mr.WithSegment(models.SegmentModel, func() error {
+		var err error
+		offerPlayer, revocation, alreadyRevoked, err = models.RevokeClaim(
+			r.Context(),
+			h.App.DB,
+			payload.GameID,
+			payload.PlayerID,
+			payload.TransactionID,
+			payload.Reason,
+			payload.RestoreEligibility,
+			currentTime,
+			mr,
+		)
+		return err
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Failed to revoke claim.")
+		if modelNotFound, ok := err.(*e.ModelNotFoundError); ok {
+			h.App.HandleError(w, http.StatusNotFound, modelNotFound.Error(), modelNotFound)
+			return
+		}
+		if invalidModel, ok := err.(*e.InvalidModelError); ok {
+			h.App.HandleError(w, http.StatusUnprocessableEntity, invalidModel.Error(), invalidModel)
+			return
+		}
+		h.App.HandleError(w, http.StatusInternalServerError, "Failed to revoke claim", err)
+		return
+	}
+
+	logger.WithField("alreadyRevoked", alreadyRevoked).Info("Revoked claim successfully")
+	bytesRes, _ := json.Marshal(map[string]interface{}{
+		"transactionId":       revocation.TransactionID,
+		"playerId":            offerPlayer.PlayerID,
+		"offerId":             offerPlayer.OfferID,
+		"claimCounter":        offerPlayer.ClaimCounter,
+		"revokedAt":           revocation.RevokedAt,
+		"reason":              revocation.Reason,
+		"restoredEligibility": revocation.RestoredEligibility,
+		"alreadyRevoked":      alreadyRevoked,
+	})
+	WriteBytes(w, http.StatusOK, bytesRes)
+}
+
 func (h *OfferRequestHandler) viewOffer(w http.ResponseWriter, r *http.Request) {
 	mr := metricsReporterFromCtx(r.Context())
 	payload := offerImpressionPayloadFromCtx(r.Context())
@@ -201,14 +446,24 @@ func (h *OfferRequestHandler) viewOffer(w http.ResponseWriter, r *http.Request)
 		"payload":   payload,
 	})
 
+	metadata, ok := h.gameMetadata(w, r, payload.GameID, logger)
+	if !ok {
+		return
+	}
+	if !h.checkOfferToken(w, metadata, payload.GameID, payload.PlayerID, offerInstanceID, payload.Token, logger) {
+		return
+	}
+
 	alreadyViewed, nextAt, err := models.ViewOffer(
 		r.Context(),
 		h.App.DB,
+		h.App.Cache,
 		payload.GameID,
 		offerInstanceID,
 		payload.PlayerID,
 		payload.ImpressionID,
 		currentTime,
+		h.App.OffersCacheMaxAge,
 		mr,
 	)
 	if err != nil {
@@ -267,7 +522,7 @@ func (h *OfferRequestHandler) offerInfo(w http.ResponseWriter, r *http.Request)
 	var err error
 	var offer *models.OfferToReturn
 	err = mr.WithSegment(models.SegmentModel, func() error {
-		offer, err = models.GetOfferInfo(r.Context(), h.App.DB, gameID, offerInstanceID, h.App.OffersCacheMaxAge, mr)
+		offer, err = models.GetOfferInfo(r.Context(), h.App.DB, gameID, playerID, offerInstanceID, h.App.OffersCacheMaxAge, mr)
 		return err
 	})
 
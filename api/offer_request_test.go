@@ -159,6 +159,29 @@ var _ = Describe("Offer Handler", func() {
 			Expect(recorder.Header().Get("Cache-Control")).To(Equal("max-age=123"))
 		})
 
+		It("should return at most the maxOffers offers of a placement", func() {
+			for _, placement := range []*models.Placement{
+				{GameID: "offers-game", Name: "popup"},
+				{GameID: "offers-game", Name: "store", MaxOffers: dat.NullInt64From(1)},
+				{GameID: "offers-game", Name: "unique-place"},
+			} {
+				err := models.UpsertPlacement(nil, app.DB, placement, app.Cache, time.Now(), nil)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			url := "/available-offers?player-id=player-1&game-id=offers-game"
+			request, _ := http.NewRequest("GET", url, nil)
+			var jsonBody map[string][]map[string]interface{}
+
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			err := json.Unmarshal(recorder.Body.Bytes(), &jsonBody)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jsonBody["popup"]).To(HaveLen(1))
+			Expect(jsonBody["store"]).To(HaveLen(1))
+			Expect(jsonBody["store"][0]["productId"]).To(Equal("com.tfg.sample.3"))
+		})
+
 		It("should return empty list of available offers", func() {
 			playerID := "player-1"
 			gameID := "non-existing-offers-game"
@@ -912,6 +935,39 @@ var _ = Describe("Offer Handler", func() {
 			Expect(jsonBody["description"]).To(Equal("OfferInstance was not found with specified filters."))
 			Expect(jsonBody["error"]).To(Equal("OfferInstanceNotFoundError"))
 		})
+
+		It("should return 410 if the stock of the offer is sold out", func() {
+			offerID := "dd21ec96-2890-4ba0-b8e2-40ea67196990"
+			gameID := "offers-game"
+			playerID := "player-1"
+			_, err := app.DB.Update("offers").
+				Set("stock", 10).
+				Set("stock_sold", 10).
+				Where("id = $1 AND game_id = $2", offerID, gameID).
+				Exec()
+			Expect(err).NotTo(HaveOccurred())
+
+			offerReader := JSONFor(JSON{
+				"gameId":        gameID,
+				"playerId":      playerID,
+				"productId":     "com.tfg.sample",
+				"timestamp":     app.Clock.GetTime().Unix(),
+				"transactionId": uuid.NewV4().String(),
+				"id":            "56fc0477-39f1-485c-898e-4909e9155eb1",
+			})
+			request, _ := http.NewRequest("PUT", "/offers/claim", offerReader)
+
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusGone))
+			var jsonBody map[string]string
+			json.Unmarshal(recorder.Body.Bytes(), &jsonBody)
+			Expect(jsonBody["code"]).To(Equal("OFF-009"))
+			Expect(jsonBody["error"]).To(Equal("OfferSoldOutError"))
+
+			claims, err := models.ListClaims(nil, app.DB, gameID, playerID, time.Unix(0, 0), 10, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(claims).To(BeEmpty())
+		})
 	})
 
 	Describe("PUT /offers/{id}/impressions", func() {
@@ -1113,10 +1169,14 @@ var _ = Describe("Offer Handler", func() {
 				OfferID:       offerID,
 				ViewCounter:   1,
 				ViewTimestamp: dat.NullTimeFrom(time.Unix(timestamp, 0)),
-				Impressions:   dat.JSON([]byte(fmt.Sprintf(`["%s"]`, impressionID))),
 			}
 			err := models.CreateOfferPlayer(nil, app.DB, op, nil)
 			Expect(err).ToNot(HaveOccurred())
+			_, err = app.DB.SQL(
+				"INSERT INTO dedupe_keys (game_id, player_id, offer_id, kind, key) VALUES ($1, $2, $3, $4, $5)",
+				gameID, playerID, offerID, models.DedupeKindImpression, impressionID,
+			).Exec()
+			Expect(err).ToNot(HaveOccurred())
 
 			offerReader := JSONFor(JSON{
 				"playerId":     playerID,
@@ -1377,4 +1437,234 @@ var _ = Describe("Offer Handler", func() {
 			app.DB = oldDB // avoid errors in after each
 		})
 	})
+
+	Describe("POST /offers/claim/revoke", func() {
+		gameID := "offers-game"
+		playerID := "player-1"
+		offerID := "dd21ec96-2890-4ba0-b8e2-40ea67196990"
+		var transactionID string
+
+		BeforeEach(func() {
+			transactionID = uuid.NewV4().String()
+			request, _ := http.NewRequest("PUT", "/offers/claim", JSONFor(JSON{
+				"gameId":        gameID,
+				"playerId":      playerID,
+				"timestamp":     app.Clock.GetTime().Unix(),
+				"transactionId": transactionID,
+				"id":            "56fc0477-39f1-485c-898e-4909e9155eb1",
+			}))
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			recorder = httptest.NewRecorder()
+		})
+
+		It("should revoke the claim and restore eligibility", func() {
+			request, _ := http.NewRequest("POST", "/offers/claim/revoke", JSONFor(JSON{
+				"gameId":             gameID,
+				"transactionId":      transactionID,
+				"reason":             "refunded",
+				"restoreEligibility": true,
+			}))
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["playerId"]).To(Equal(playerID))
+			Expect(obj["offerId"]).To(Equal(offerID))
+			Expect(obj["claimCounter"]).To(BeEquivalentTo(0))
+			Expect(obj["restoredEligibility"]).To(BeTrue())
+			Expect(obj["alreadyRevoked"]).To(BeFalse())
+
+			offerPlayer, err := models.GetOfferPlayer(nil, app.DB, gameID, playerID, offerID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offerPlayer.ClaimCounter).To(Equal(0))
+			var revocations map[string]*models.ClaimRevocation
+			Expect(offerPlayer.Revocations.Unmarshal(&revocations)).To(Succeed())
+			Expect(revocations[transactionID].Reason).To(Equal("refunded"))
+		})
+
+		It("should be idempotent", func() {
+			for i := 0; i < 2; i++ {
+				recorder = httptest.NewRecorder()
+				request, _ := http.NewRequest("POST", "/offers/claim/revoke", JSONFor(JSON{
+					"gameId":             gameID,
+					"playerId":           playerID,
+					"transactionId":      transactionID,
+					"restoreEligibility": true,
+				}))
+				app.Router.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			}
+
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["alreadyRevoked"]).To(BeTrue())
+			Expect(obj["claimCounter"]).To(BeEquivalentTo(0))
+		})
+
+		It("should return status code 404 if the transaction was not claimed", func() {
+			request, _ := http.NewRequest("POST", "/offers/claim/revoke", JSONFor(JSON{
+				"gameId":        gameID,
+				"transactionId": uuid.NewV4().String(),
+			}))
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should return status code 422 if transactionId is missing", func() {
+			request, _ := http.NewRequest("POST", "/offers/claim/revoke", JSONFor(JSON{
+				"gameId": gameID,
+			}))
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		})
+	})
+
+	Describe("GET /available-offers/explain", func() {
+		It("should explain the offers of the game for the player", func() {
+			request, _ := http.NewRequest("GET", "/available-offers/explain?game-id=offers-game&player-id=player-1", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["at"]).To(BeEquivalentTo(1486678000))
+			explanations := map[string]map[string]interface{}{}
+			for _, explanation := range obj["offers"].([]interface{}) {
+				e := explanation.(map[string]interface{})
+				explanations[e["name"].(string)] = e
+			}
+			Expect(explanations["template-1"]["included"]).To(BeTrue())
+			Expect(explanations["template-9"]["included"]).To(BeFalse())
+			Expect(explanations["template-9"]["rule"]).To(Equal("disabled"))
+		})
+
+		It("should explain the offers at the given time", func() {
+			request, _ := http.NewRequest("GET", "/available-offers/explain?game-id=offers-game&player-id=player-1&at=1486679050", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["at"]).To(BeEquivalentTo(1486679050))
+			for _, explanation := range obj["offers"].([]interface{}) {
+				e := explanation.(map[string]interface{})
+				if e["name"] == "template-1" {
+					Expect(e["rule"]).To(Equal("expired"))
+				}
+			}
+		})
+
+		It("should return the impression caps of the player", func() {
+			request, _ := http.NewRequest("PUT", "/games/offers-game/placements", JSONFor(JSON{
+				"name":          "popup",
+				"impressionCap": map[string]interface{}{"every": "6h", "max": 3},
+			}))
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/available-offers/explain?game-id=offers-game&player-id=player-1", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			impressionCaps := obj["impressionCaps"].([]interface{})
+			Expect(impressionCaps).To(HaveLen(1))
+			impressionCap := impressionCaps[0].(map[string]interface{})
+			Expect(impressionCap["placement"]).To(Equal("popup"))
+			Expect(impressionCap["max"]).To(BeEquivalentTo(3))
+			Expect(impressionCap["count"]).To(BeEquivalentTo(0))
+			Expect(impressionCap["reached"]).To(BeFalse())
+		})
+
+		It("should return status code 400 if at is not a timestamp", func() {
+			request, _ := http.NewRequest("GET", "/available-offers/explain?game-id=offers-game&player-id=player-1&at=now", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should return status code 400 if player-id is missing", func() {
+			request, _ := http.NewRequest("GET", "/available-offers/explain?game-id=offers-game", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should return status code 404 if the game does not exist", func() {
+			request, _ := http.NewRequest("GET", "/available-offers/explain?game-id=unknown-game&player-id=player-1", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("POST /available-offers/preview", func() {
+		It("should return the offers at the given time", func() {
+			request, _ := http.NewRequest("POST", "/available-offers/preview", JSONFor(JSON{
+				"gameId": "offers-game",
+				"at":     1486679150,
+			}))
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var obj map[string][]map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj).NotTo(HaveKey("popup"))
+			Expect(obj["store"]).To(HaveLen(1))
+			Expect(obj["store"][0]["productId"]).To(Equal("com.tfg.sample.2"))
+			Expect(obj["store"][0]).NotTo(HaveKey("token"))
+		})
+
+		It("should use the simulated offer players", func() {
+			request, _ := http.NewRequest("POST", "/available-offers/preview", JSONFor(JSON{
+				"gameId": "offers-game",
+				"at":     1486678000,
+				"offerPlayers": []interface{}{
+					map[string]interface{}{
+						"offerId":        "dd21ec96-2890-4ba0-b8e2-40ea67196990",
+						"claimCounter":   1,
+						"claimTimestamp": 1486678000,
+					},
+				},
+			}))
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var obj map[string][]map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj).NotTo(HaveKey("popup"))
+		})
+
+		It("should return status code 422 if at is missing", func() {
+			request, _ := http.NewRequest("POST", "/available-offers/preview", JSONFor(JSON{
+				"gameId": "offers-game",
+			}))
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		})
+
+		It("should return status code 404 if the game does not exist", func() {
+			request, _ := http.NewRequest("POST", "/available-offers/preview", JSONFor(JSON{
+				"gameId": "unknown-game",
+				"at":     1486678000,
+			}))
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
 })
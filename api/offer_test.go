@@ -1008,6 +1008,30 @@ var _ = Describe("Offer Template Handler", func() {
 			Expect(int(obj["version"].(float64))).To(Equal(2))
 		})
 
+		It("should save the update as the offer draft if the game requires publish approval", func() {
+			_, err := app.DB.Update("games").
+				Set("metadata", dat.JSON([]byte(`{"requirePublishApproval": true}`))).
+				Where("id = $1", "offers-game").
+				Exec()
+			Expect(err).NotTo(HaveOccurred())
+			id := "a411fbcf-dddc-4153-b42b-3f9b2684c965"
+			request, _ := http.NewRequest("PUT", fmt.Sprintf("/offers/%s", id), offerReader)
+			request.Header.Set("x-forwarded-email", "author@tfgco.com")
+
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusAccepted), recorder.Body.String())
+			var obj map[string]interface{}
+			err = json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["offerId"]).To(Equal(id))
+			Expect(obj["author"]).To(Equal("author@tfgco.com"))
+
+			var version int
+			err = app.DB.SQL("SELECT version FROM offers WHERE id = $1", id).QueryScalar(&version)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal(1))
+		})
+
 		It("should update offer with cost", func() {
 			name := "New Awesome Game"
 			gameID := "offers-game"
@@ -1427,4 +1451,194 @@ var _ = Describe("Offer Template Handler", func() {
 			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
 		})
 	})
+
+	Describe("Offer drafts", func() {
+		id := "a411fbcf-dddc-4153-b42b-3f9b2684c965"
+		var draftReader io.Reader
+		BeforeEach(func() {
+			draftReader = JSONFor(JSON{
+				"name":      "Drafted Offer",
+				"productId": "com.tfg.example",
+				"gameId":    "offers-game",
+				"contents":  dat.JSON([]byte(`{"gems": 42}`)),
+				"period":    dat.JSON([]byte(`{"max": 1}`)),
+				"frequency": dat.JSON([]byte(`{"every": "24h"}`)),
+				"trigger":   dat.JSON([]byte(`{"from": 1486678000, "to": 1486679000}`)),
+				"placement": "popup",
+			})
+		})
+
+		saveDraft := func(author string) {
+			request, _ := http.NewRequest("PUT", fmt.Sprintf("/offers/%s/draft", id), draftReader)
+			request.Header.Set("x-forwarded-email", author)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK), recorder.Body.String())
+			recorder = httptest.NewRecorder()
+		}
+
+		It("should save and return a draft without changing the offer", func() {
+			saveDraft("author@tfgco.com")
+
+			request, _ := http.NewRequest("GET", fmt.Sprintf("/offers/%s/draft?game-id=offers-game", id), nil)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["offerId"]).To(Equal(id))
+			Expect(obj["name"]).To(Equal("Drafted Offer"))
+			Expect(obj["author"]).To(Equal("author@tfgco.com"))
+
+			var version int
+			err = app.DB.Select("version").
+				From("offers").
+				Where("id = $1", id).
+				QueryScalar(&version)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal(1))
+		})
+
+		It("should return status code 404 if the offer doesn't exist", func() {
+			request, _ := http.NewRequest("PUT", fmt.Sprintf("/offers/%s/draft", uuid.NewV4().String()), draftReader)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["code"]).To(Equal("OFF-001"))
+		})
+
+		It("should return status code 404 if there is no draft", func() {
+			request, _ := http.NewRequest("GET", fmt.Sprintf("/offers/%s/draft?game-id=offers-game", id), nil)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["error"]).To(Equal("OfferDraftNotFoundError"))
+		})
+
+		It("should discard a draft", func() {
+			saveDraft("author@tfgco.com")
+
+			request, _ := http.NewRequest("DELETE", fmt.Sprintf("/offers/%s/draft?game-id=offers-game", id), nil)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", fmt.Sprintf("/offers/%s/draft?game-id=offers-game", id), nil)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should publish a draft as a new offer version", func() {
+			saveDraft("author@tfgco.com")
+
+			request, _ := http.NewRequest("POST", fmt.Sprintf("/offers/%s/publish?game-id=offers-game", id), nil)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK), recorder.Body.String())
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["id"]).To(Equal(id))
+			Expect(obj["name"]).To(Equal("Drafted Offer"))
+			Expect(int(obj["version"].(float64))).To(Equal(2))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", fmt.Sprintf("/offers/%s/draft?game-id=offers-game", id), nil)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should return status code 422 if the author publishes a draft that requires approval", func() {
+			_, err := app.DB.Update("games").
+				Set("metadata", dat.JSON([]byte(`{"requirePublishApproval": true}`))).
+				Where("id = $1", "offers-game").
+				Exec()
+			Expect(err).NotTo(HaveOccurred())
+			saveDraft("author@tfgco.com")
+
+			request, _ := http.NewRequest("POST", fmt.Sprintf("/offers/%s/publish?game-id=offers-game", id), nil)
+			request.Header.Set("x-forwarded-email", "author@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+			var obj map[string]interface{}
+			err = json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["code"]).To(Equal("OFF-003"))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("POST", fmt.Sprintf("/offers/%s/publish?game-id=offers-game", id), nil)
+			request.Header.Set("x-forwarded-email", "reviewer@tfgco.com")
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK), recorder.Body.String())
+		})
+	})
+
+	Describe("/offers/{id}/schedule", func() {
+		id := "dd21ec96-2890-4ba0-b8e2-40ea67196990"
+
+		It("should schedule an operation and list it", func() {
+			opReader := JSONFor(JSON{
+				"gameId":    "offers-game",
+				"operation": "patch",
+				"patch":     dat.JSON([]byte(`{"contents": {"gems": 1000}}`)),
+				"executeAt": 1486680000,
+			})
+			request, _ := http.NewRequest("POST", fmt.Sprintf("/offers/%s/schedule", id), opReader)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+			Expect(recorder.Code).To(Equal(http.StatusCreated), recorder.Body.String())
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["id"]).NotTo(BeEmpty())
+			Expect(obj["offerId"]).To(Equal(id))
+			Expect(obj["status"]).To(Equal("pending"))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", fmt.Sprintf("/offers/%s/schedule?game-id=offers-game", id), nil)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var list map[string][]map[string]interface{}
+			err = json.Unmarshal([]byte(recorder.Body.String()), &list)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(list["operations"]).To(HaveLen(1))
+			Expect(list["operations"][0]["operation"]).To(Equal("patch"))
+			Expect(list["operations"][0]["executeAt"]).To(BeEquivalentTo(1486680000))
+		})
+
+		It("should return status code 422 if operation is invalid", func() {
+			opReader := JSONFor(JSON{
+				"gameId":    "offers-game",
+				"operation": "delete",
+				"executeAt": 1486680000,
+			})
+			request, _ := http.NewRequest("POST", fmt.Sprintf("/offers/%s/schedule", id), opReader)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["code"]).To(Equal("OFF-002"))
+		})
+
+		It("should return status code 404 if offer doesn't exist", func() {
+			opReader := JSONFor(JSON{
+				"gameId":    "offers-game",
+				"operation": "enable",
+				"executeAt": 1486680000,
+			})
+			request, _ := http.NewRequest("POST", fmt.Sprintf("/offers/%s/schedule", uuid.NewV4().String()), opReader)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should return status code 400 if game-id is missing", func() {
+			request, _ := http.NewRequest("GET", fmt.Sprintf("/offers/%s/schedule", id), nil)
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
 })
@@ -0,0 +1,116 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	e "github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+)
+
+func (a *App) configureOfferTokens() {
+	a.Config.SetDefault("offerTokens.secret", "")
+	a.Config.SetDefault("offerTokens.ttlSeconds", 3600)
+
+	if a.Config.GetString("offerTokens.secret") == "" {
+		a.Logger.WithFields(logrus.Fields{
+			"source":    "app",
+			"operation": "configureOfferTokens",
+		}).Warn("offerTokens.secret is not set, available offers will not have tokens.")
+	}
+}
+
+//signOfferTokens sets the token of each offer, if offer tokens are configured
+func (a *App) signOfferTokens(gameID, playerID string, offers map[string][]*models.OfferToReturn, t time.Time) {
+	secret := a.Config.GetString("offerTokens.secret")
+	if secret == "" {
+		return
+	}
+	expiresAt := t.Add(time.Duration(a.Config.GetInt64("offerTokens.ttlSeconds")) * time.Second)
+	for _, placementOffers := range offers {
+		for _, offer := range placementOffers {
+			offer.Token = models.SignOfferToken(secret, gameID, playerID, offer.ID, expiresAt)
+		}
+	}
+}
+
+//gameMetadata returns the metadata of the game, or an empty metadata if the game does not exist
+//so the model reports the missing game. It writes an error and returns false if it fails.
+func (h *OfferRequestHandler) gameMetadata(
+	w http.ResponseWriter,
+	r *http.Request,
+	gameID string,
+	logger logrus.FieldLogger,
+) (map[string]interface{}, bool) {
+	mr := metricsReporterFromCtx(r.Context())
+	var game *models.Game
+	var err error
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		game, err = models.GetGameByID(r.Context(), h.App.DB, gameID, mr)
+		return err
+	})
+	if err != nil {
+		if _, ok := err.(*e.ModelNotFoundError); ok {
+			return map[string]interface{}{}, true
+		}
+		logger.WithError(err).Error("Failed to retrieve game.")
+		h.App.HandleError(w, http.StatusInternalServerError, "Failed to retrieve game", err)
+		return nil, false
+	}
+	metadata, err := game.GetMetadata()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get game metadata.")
+		h.App.HandleError(w, http.StatusInternalServerError, "Failed to get game metadata", err)
+		return nil, false
+	}
+	return metadata, true
+}
+
+//checkOfferToken writes an error and returns false if the game requires offer tokens
+//(requireOfferTokens in its metadata) and token is not valid for the player and offer instance
+func (h *OfferRequestHandler) checkOfferToken(
+	w http.ResponseWriter,
+	metadata map[string]interface{},
+	gameID, playerID, offerInstanceID, token string,
+	logger logrus.FieldLogger,
+) bool {
+	requireTokens := false
+	if val, ok := metadata["requireOfferTokens"]; ok {
+		if requireFromMeta, ok := val.(bool); ok {
+			requireTokens = requireFromMeta
+		}
+	}
+	if !requireTokens {
+		return true
+	}
+
+	secret := h.App.Config.GetString("offerTokens.secret")
+	if secret == "" {
+		err := fmt.Errorf("offerTokens.secret is not set")
+		logger.WithError(err).Error("Game requires offer tokens but they are not configured.")
+		h.App.HandleError(w, http.StatusInternalServerError, "Offer tokens are not configured", err)
+		return false
+	}
+
+	var err error
+	if offerInstanceID == "" {
+		err = e.NewInvalidOfferTokenError("", "the offer id is required to verify the offer token")
+	} else {
+		err = models.VerifyOfferToken(secret, token, gameID, playerID, offerInstanceID, h.App.Clock.GetTime())
+	}
+	if err != nil {
+		logger.WithError(err).Warn("Invalid offer token.")
+		h.App.HandleError(w, http.StatusForbidden, err.Error(), err)
+		return false
+	}
+	return true
+}
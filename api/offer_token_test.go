@@ -0,0 +1,137 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	uuid "github.com/satori/go.uuid"
+	"github.com/topfreegames/offers/models"
+	. "github.com/topfreegames/offers/testing"
+	"gopkg.in/mgutz/dat.v2/dat"
+)
+
+var _ = Describe("Offer Tokens", func() {
+	var recorder *httptest.ResponseRecorder
+	secret := "offer-token-secret"
+	gameID := "offers-game"
+	playerID := "player-1"
+	offerInstanceID := "56fc0477-39f1-485c-898e-4909e9155eb1"
+
+	requireOfferTokens := func() {
+		_, err := app.DB.Update("games").
+			Set("metadata", dat.JSON([]byte(`{"requireOfferTokens": true}`))).
+			Where("id = $1", gameID).
+			Exec()
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	claimRequest := func(token string) *http.Request {
+		request, _ := http.NewRequest("PUT", "/offers/claim", JSONFor(JSON{
+			"gameId":        gameID,
+			"playerId":      playerID,
+			"timestamp":     app.Clock.GetTime().Unix(),
+			"transactionId": uuid.NewV4().String(),
+			"id":            offerInstanceID,
+			"token":         token,
+		}))
+		return request
+	}
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+		config.Set("offerTokens.secret", secret)
+	})
+
+	AfterEach(func() {
+		config.Set("offerTokens.secret", "")
+	})
+
+	It("should return a token with each available offer", func() {
+		request, _ := http.NewRequest("GET", "/available-offers?player-id=player-1&game-id=offers-game", nil)
+		app.Router.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		var obj map[string][]map[string]interface{}
+		err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj).NotTo(BeEmpty())
+		for _, offers := range obj {
+			for _, offer := range offers {
+				token := offer["token"].(string)
+				err := models.VerifyOfferToken(secret, token, gameID, playerID, offer["id"].(string), app.Clock.GetTime())
+				Expect(err).NotTo(HaveOccurred())
+			}
+		}
+	})
+
+	It("should claim without token if the game does not require them", func() {
+		app.Router.ServeHTTP(recorder, claimRequest(""))
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+
+	It("should claim with a valid token if the game requires them", func() {
+		requireOfferTokens()
+		token := models.SignOfferToken(secret, gameID, playerID, offerInstanceID, app.Clock.GetTime().Add(time.Minute))
+
+		app.Router.ServeHTTP(recorder, claimRequest(token))
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+
+	It("should return status code 403 if the token is missing and the game requires them", func() {
+		requireOfferTokens()
+
+		app.Router.ServeHTTP(recorder, claimRequest(""))
+
+		Expect(recorder.Code).To(Equal(http.StatusForbidden))
+		var obj map[string]interface{}
+		err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj["code"]).To(Equal("OFF-007"))
+		Expect(obj["error"]).To(Equal("InvalidOfferTokenError"))
+	})
+
+	It("should return status code 403 if the token was issued to another player", func() {
+		requireOfferTokens()
+		token := models.SignOfferToken(secret, gameID, "player-2", offerInstanceID, app.Clock.GetTime().Add(time.Minute))
+
+		app.Router.ServeHTTP(recorder, claimRequest(token))
+
+		Expect(recorder.Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("should require a token for impressions if the game requires them", func() {
+		requireOfferTokens()
+		url := "/offers/" + offerInstanceID + "/impressions"
+
+		request, _ := http.NewRequest("PUT", url, JSONFor(JSON{
+			"gameId":       gameID,
+			"playerId":     playerID,
+			"impressionId": uuid.NewV4().String(),
+		}))
+		app.Router.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusForbidden))
+
+		recorder = httptest.NewRecorder()
+		request, _ = http.NewRequest("PUT", url, JSONFor(JSON{
+			"gameId":       gameID,
+			"playerId":     playerID,
+			"impressionId": uuid.NewV4().String(),
+			"token":        models.SignOfferToken(secret, gameID, playerID, offerInstanceID, app.Clock.GetTime().Add(time.Minute)),
+		}))
+		app.Router.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+})
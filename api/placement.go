@@ -0,0 +1,148 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+)
+
+//PlacementHandler handler
+type PlacementHandler struct {
+	App    *App
+	Method string
+}
+
+//ServeHTTP method
+func (h *PlacementHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch h.Method {
+	case "list":
+		h.list(w, r)
+		return
+	case "upsert":
+		h.upsert(w, r)
+		return
+	case "delete":
+		h.delete(w, r)
+		return
+	}
+}
+
+func (h *PlacementHandler) list(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	gameID := paramKeyFromContext(r.Context())
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "placementHandler",
+		"operation": "list",
+		"userEmail": userEmail,
+		"gameID":    gameID,
+	})
+
+	var err error
+	var placements []*models.Placement
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		placements, err = models.ListPlacements(r.Context(), h.App.DB, gameID, mr)
+		return err
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("List placements failed.")
+		h.App.HandleError(w, http.StatusInternalServerError, "List placements failed.", err)
+		return
+	}
+
+	logger.Info("Listed placements successfully.")
+	bytesRes, _ := json.Marshal(map[string]interface{}{"placements": placements})
+	WriteBytes(w, http.StatusOK, bytesRes)
+}
+
+func (h *PlacementHandler) upsert(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	placement := placementFromCtx(r.Context())
+	placement.GameID = paramKeyFromContext(r.Context())
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "placementHandler",
+		"operation": "upsert",
+		"userEmail": userEmail,
+		"placement": placement,
+	})
+
+	err := mr.WithSegment(models.SegmentModel, func() error {
+		return models.UpsertPlacement(r.Context(), h.App.DB, placement, h.App.Cache, h.App.Clock.GetTime(), mr)
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Upsert placement failed.")
+		if invalidModel, ok := err.(*errors.InvalidModelError); ok {
+			h.App.HandleError(w, http.StatusUnprocessableEntity, invalidModel.Error(), invalidModel)
+			return
+		}
+		h.App.HandleError(w, http.StatusInternalServerError, "Upsert placement failed", err)
+		return
+	}
+
+	logger.Info("Upserted placement successfully.")
+	bytesRes, _ := json.Marshal(placement)
+	WriteBytes(w, http.StatusOK, bytesRes)
+}
+
+func (h *PlacementHandler) delete(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	gameID := paramKeyFromContext(r.Context())
+	name := r.URL.Query().Get("name")
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "placementHandler",
+		"operation": "delete",
+		"userEmail": userEmail,
+		"gameID":    gameID,
+		"name":      name,
+	})
+
+	if name == "" {
+		err := fmt.Errorf("The name parameter cannot be empty")
+		logger.WithError(err).Error("Delete placement failed.")
+		h.App.HandleError(w, http.StatusBadRequest, "The name parameter cannot be empty.", err)
+		return
+	}
+
+	err := mr.WithSegment(models.SegmentModel, func() error {
+		return models.DeletePlacement(r.Context(), h.App.DB, gameID, name, h.App.Cache, mr)
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Delete placement failed.")
+		if modelNotFound, ok := err.(*errors.ModelNotFoundError); ok {
+			h.App.HandleError(w, http.StatusNotFound, modelNotFound.Error(), modelNotFound)
+			return
+		}
+		if invalidModel, ok := err.(*errors.InvalidModelError); ok {
+			h.App.HandleError(w, http.StatusUnprocessableEntity, invalidModel.Error(), invalidModel)
+			return
+		}
+		h.App.HandleError(w, http.StatusInternalServerError, "Delete placement failed", err)
+		return
+	}
+
+	logger.Info("Deleted placement successfully.")
+	bytesRes, _ := json.Marshal(map[string]interface{}{
+		"gameId": gameID,
+		"name":   name,
+	})
+	WriteBytes(w, http.StatusOK, bytesRes)
+}
@@ -0,0 +1,130 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/topfreegames/offers/testing"
+)
+
+var _ = Describe("Placement Handler", func() {
+	var recorder *httptest.ResponseRecorder
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+	})
+
+	Describe("PUT /games/{id}/placements", func() {
+		It("should upsert a placement", func() {
+			request, _ := http.NewRequest("PUT", "/games/offers-game/placements", JSONFor(JSON{
+				"name":            "store",
+				"ordering":        "expiration",
+				"maxOffers":       2,
+				"fallbackOfferId": "dd21ec96-2890-4ba0-b8e2-40ea67196990",
+			}))
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["gameId"]).To(Equal("offers-game"))
+			Expect(obj["name"]).To(Equal("store"))
+			Expect(obj["ordering"]).To(Equal("expiration"))
+			Expect(obj["maxOffers"]).To(BeEquivalentTo(2))
+			Expect(obj["fallbackOfferId"]).To(Equal("dd21ec96-2890-4ba0-b8e2-40ea67196990"))
+		})
+
+		It("should return status code 422 if the ordering is invalid", func() {
+			request, _ := http.NewRequest("PUT", "/games/offers-game/placements", JSONFor(JSON{
+				"name":     "store",
+				"ordering": "random",
+			}))
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		})
+
+		It("should return status code 422 if the rotation is invalid", func() {
+			request, _ := http.NewRequest("PUT", "/games/offers-game/placements", JSONFor(JSON{
+				"name":     "store",
+				"rotation": map[string]interface{}{"size": 0},
+			}))
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		})
+
+		It("should return status code 422 if the fallback offer does not exist", func() {
+			request, _ := http.NewRequest("PUT", "/games/offers-game/placements", JSONFor(JSON{
+				"name":            "store",
+				"fallbackOfferId": "a6ab2d3b-c1b9-4b5a-bf93-27a16b4c6f5e",
+			}))
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		})
+	})
+
+	Describe("GET /games/{id}/placements", func() {
+		It("should list the placements of the game", func() {
+			request, _ := http.NewRequest("PUT", "/games/offers-game/placements", JSONFor(JSON{
+				"name": "store",
+			}))
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/games/offers-game/placements", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var obj map[string][]map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["placements"]).To(HaveLen(1))
+			Expect(obj["placements"][0]["name"]).To(Equal("store"))
+			Expect(obj["placements"][0]["ordering"]).To(Equal("priority"))
+		})
+	})
+
+	Describe("DELETE /games/{id}/placements", func() {
+		It("should return status code 422 if an offer uses the placement", func() {
+			request, _ := http.NewRequest("PUT", "/games/offers-game/placements", JSONFor(JSON{
+				"name": "store",
+			}))
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("DELETE", "/games/offers-game/placements?name=store", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		})
+
+		It("should return status code 404 if the placement does not exist", func() {
+			request, _ := http.NewRequest("DELETE", "/games/offers-game/placements?name=store", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should return status code 400 if the name is missing", func() {
+			request, _ := http.NewRequest("DELETE", "/games/offers-game/placements", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
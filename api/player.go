@@ -0,0 +1,297 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+)
+
+//PlayerHandler handler
+type PlayerHandler struct {
+	App    *App
+	Method string
+}
+
+//ServeHTTP method
+func (h *PlayerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch h.Method {
+	case "list-offers":
+		h.listOffers(w, r)
+		return
+	case "reset":
+		h.reset(w, r)
+		return
+	case "erase":
+		h.erase(w, r)
+		return
+	case "export":
+		h.export(w, r)
+		return
+	case "merge":
+		h.merge(w, r)
+		return
+	case "reset-offer":
+		h.resetOffer(w, r)
+		return
+	}
+}
+
+func (h *PlayerHandler) listOffers(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	playerID := paramKeyFromContext(r.Context())
+	gameID := r.URL.Query().Get("game-id")
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "playerHandler",
+		"operation": "listOffers",
+		"userEmail": userEmail,
+		"gameID":    gameID,
+		"playerID":  playerID,
+	})
+
+	if gameID == "" {
+		err := fmt.Errorf("The game-id parameter cannot be empty")
+		logger.WithError(err).Error("List player offers failed.")
+		h.App.HandleError(w, http.StatusBadRequest, "The game-id parameter cannot be empty.", err)
+		return
+	}
+
+	var err error
+	var offers []*models.PlayerOffer
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		offers, err = models.GetPlayerOffers(r.Context(), h.App.DB, gameID, playerID, h.App.Clock.GetTime(), mr)
+		return err
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("List player offers failed.")
+		h.App.HandleError(w, http.StatusInternalServerError, "List player offers failed.", err)
+		return
+	}
+
+	logger.Info("Listed player offers successfully.")
+	bts, _ := json.Marshal(map[string]interface{}{
+		"offers": offers,
+	})
+	WriteBytes(w, http.StatusOK, bts)
+}
+
+func (h *PlayerHandler) reset(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	playerID := paramKeyFromContext(r.Context())
+	gameID := r.URL.Query().Get("game-id")
+
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "playerHandler",
+		"operation": "reset",
+		"gameID":    gameID,
+		"playerID":  playerID,
+	})
+
+	if gameID == "" {
+		err := fmt.Errorf("The game-id parameter cannot be empty")
+		logger.WithError(err).Error("Reset player failed.")
+		h.App.HandleError(w, http.StatusBadRequest, "The game-id parameter cannot be empty.", err)
+		return
+	}
+
+	err := mr.WithSegment(models.SegmentModel, func() error {
+		return models.ResetTestPlayer(r.Context(), h.App.DB, gameID, playerID, mr)
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Reset player failed.")
+		if _, ok := err.(*errors.ModelNotFoundError); ok {
+			h.App.HandleError(w, http.StatusForbidden, "Only test players can reset their state.", err)
+			return
+		}
+		h.App.HandleError(w, http.StatusInternalServerError, "Reset player failed.", err)
+		return
+	}
+
+	logger.Info("Reset player successfully.")
+	bts, _ := json.Marshal(map[string]interface{}{
+		"gameId":   gameID,
+		"playerId": playerID,
+	})
+	WriteBytes(w, http.StatusOK, bts)
+}
+
+func (h *PlayerHandler) erase(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	playerID := paramKeyFromContext(r.Context())
+	gameID := r.URL.Query().Get("game-id")
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "playerHandler",
+		"operation": "erase",
+		"userEmail": userEmail,
+		"gameID":    gameID,
+	})
+
+	if gameID == "" {
+		err := fmt.Errorf("The game-id parameter cannot be empty")
+		logger.WithError(err).Error("Erase player failed.")
+		h.App.HandleError(w, http.StatusBadRequest, "The game-id parameter cannot be empty.", err)
+		return
+	}
+
+	var err error
+	var erasure *models.PlayerErasure
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		erasure, err = models.ErasePlayer(r.Context(), h.App.DB, gameID, playerID, mr)
+		return err
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Erase player failed.")
+		h.App.HandleError(w, http.StatusInternalServerError, "Erase player failed.", err)
+		return
+	}
+	if erasure.TestPlayers > 0 {
+		h.App.Cache.Delete(models.GetTestPlayersKey(gameID))
+	}
+
+	logger.Info("Erased player successfully.")
+	bts, _ := json.Marshal(erasure)
+	WriteBytes(w, http.StatusOK, bts)
+}
+
+func (h *PlayerHandler) resetOffer(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	playerID := paramKeyFromContext(r.Context())
+	gameID := r.URL.Query().Get("game-id")
+	offerID := r.URL.Query().Get("offer-id")
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "playerHandler",
+		"operation": "resetOffer",
+		"userEmail": userEmail,
+		"gameID":    gameID,
+		"playerID":  playerID,
+		"offerID":   offerID,
+	})
+
+	if gameID == "" || offerID == "" {
+		err := fmt.Errorf("The game-id and offer-id parameters cannot be empty")
+		logger.WithError(err).Error("Reset player offer failed.")
+		h.App.HandleError(w, http.StatusBadRequest, "The game-id and offer-id parameters cannot be empty.", err)
+		return
+	}
+
+	err := mr.WithSegment(models.SegmentModel, func() error {
+		return models.ResetPlayerOffer(r.Context(), h.App.DB, gameID, playerID, offerID, mr)
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Reset player offer failed.")
+		if modelNotFound, ok := err.(*errors.ModelNotFoundError); ok {
+			h.App.HandleError(w, http.StatusNotFound, modelNotFound.Error(), modelNotFound)
+			return
+		}
+		h.App.HandleError(w, http.StatusInternalServerError, "Reset player offer failed.", err)
+		return
+	}
+
+	logger.Info("Reset player offer successfully.")
+	bts, _ := json.Marshal(map[string]interface{}{
+		"gameId":   gameID,
+		"playerId": playerID,
+		"offerId":  offerID,
+	})
+	WriteBytes(w, http.StatusOK, bts)
+}
+
+//streamWriter writes the JSON headers and a 200 status on the first write, so errors that
+//happen before anything is written can still be reported with another status
+type streamWriter struct {
+	w       http.ResponseWriter
+	started bool
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	if !s.started {
+		s.w.Header().Set("Content-Type", "application/json")
+		s.w.WriteHeader(http.StatusOK)
+		s.started = true
+	}
+	return s.w.Write(p)
+}
+
+func (h *PlayerHandler) export(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	playerID := paramKeyFromContext(r.Context())
+	gameID := r.URL.Query().Get("game-id")
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "playerHandler",
+		"operation": "export",
+		"userEmail": userEmail,
+		"gameID":    gameID,
+		"playerID":  playerID,
+	})
+
+	writer := &streamWriter{w: w}
+	err := mr.WithSegment(models.SegmentModel, func() error {
+		return models.ExportPlayer(r.Context(), h.App.DB, gameID, playerID, h.App.Clock.GetTime(), writer, mr)
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Export player failed.")
+		if !writer.started {
+			h.App.HandleError(w, http.StatusInternalServerError, "Export player failed.", err)
+		}
+		return
+	}
+
+	logger.Info("Exported player successfully.")
+}
+
+func (h *PlayerHandler) merge(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	payload := mergePlayersPayloadFromCtx(r.Context())
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "playerHandler",
+		"operation": "merge",
+		"userEmail": userEmail,
+		"payload":   payload,
+	})
+
+	var err error
+	var merge *models.PlayerMerge
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		merge, err = models.MergePlayers(r.Context(), h.App.DB, payload.GameID, payload.FromPlayerID, payload.ToPlayerID, mr)
+		return err
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Merge players failed.")
+		if invalidModel, ok := err.(*errors.InvalidModelError); ok {
+			h.App.HandleError(w, http.StatusUnprocessableEntity, invalidModel.Error(), invalidModel)
+			return
+		}
+		h.App.HandleError(w, http.StatusInternalServerError, "Merge players failed.", err)
+		return
+	}
+
+	logger.Info("Merged players successfully.")
+	bts, _ := json.Marshal(merge)
+	WriteBytes(w, http.StatusOK, bts)
+}
@@ -0,0 +1,242 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/topfreegames/offers/models"
+	. "github.com/topfreegames/offers/testing"
+)
+
+var _ = Describe("Player Handler", func() {
+	var recorder *httptest.ResponseRecorder
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+	})
+
+	Describe("GET /players/{id}/offers", func() {
+		It("should return the offers of the player", func() {
+			request, _ := http.NewRequest("GET", "/players/player-1/offers?game-id=offers-game", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+			var obj map[string][]map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["offers"]).To(HaveLen(1))
+			Expect(obj["offers"][0]["offerId"]).To(Equal("dd21ec96-2890-4ba0-b8e2-40ea67196990"))
+			Expect(obj["offers"][0]["name"]).To(Equal("template-1"))
+			Expect(obj["offers"][0]["placement"]).To(Equal("popup"))
+			Expect(obj["offers"][0]["available"]).To(BeTrue())
+			Expect(obj["offers"][0]).NotTo(HaveKey("unavailableReason"))
+		})
+
+		It("should return an empty list if the player has no offers", func() {
+			request, _ := http.NewRequest("GET", "/players/unknown-player/offers?game-id=offers-game", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"offers": []}`))
+		})
+
+		It("should return status code 400 if game-id is missing", func() {
+			request, _ := http.NewRequest("GET", "/players/player-1/offers", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should return status code of 401 if no auth provided", func() {
+			defer func() {
+				config.Set("basicauth.username", "")
+				config.Set("basicauth.password", "")
+			}()
+			config.Set("basicauth.username", "user")
+			config.Set("basicauth.password", "pass")
+
+			request, _ := http.NewRequest("GET", "/players/player-1/offers?game-id=offers-game", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Describe("POST /players/{id}/reset", func() {
+		It("should reset the state of a test player", func() {
+			err := models.AddTestPlayer(nil, app.DB, &models.TestPlayer{GameID: "offers-game", PlayerID: "player-1"}, app.Cache, time.Unix(1486678000, 0), nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			request, _ := http.NewRequest("POST", "/players/player-1/reset?game-id=offers-game", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"gameId": "offers-game", "playerId": "player-1"}`))
+			offerPlayers, err := models.GetOffersByPlayer(nil, app.DB, "offers-game", "player-1", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offerPlayers).To(BeEmpty())
+		})
+
+		It("should return status code 403 if the player is not a test player", func() {
+			request, _ := http.NewRequest("POST", "/players/player-1/reset?game-id=offers-game", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusForbidden))
+			offerPlayers, err := models.GetOffersByPlayer(nil, app.DB, "offers-game", "player-1", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offerPlayers).To(HaveLen(1))
+		})
+
+		It("should return status code 400 if game-id is missing", func() {
+			request, _ := http.NewRequest("POST", "/players/player-1/reset", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("DELETE /players/{id}", func() {
+		It("should erase the player", func() {
+			request, _ := http.NewRequest("DELETE", "/players/player-1?game-id=offers-game", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["gameId"]).To(Equal("offers-game"))
+			Expect(obj["offerPlayers"]).To(Equal(float64(1)))
+			offerPlayers, err := models.GetOffersByPlayer(nil, app.DB, "offers-game", "player-1", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offerPlayers).To(BeEmpty())
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("DELETE", "/players/player-1?game-id=offers-game", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+		})
+
+		It("should return status code 400 if game-id is missing", func() {
+			request, _ := http.NewRequest("DELETE", "/players/player-1", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("DELETE /players/{id}/offers", func() {
+		It("should reset the state of the player for the offer", func() {
+			request, _ := http.NewRequest("DELETE", "/players/player-1/offers?game-id=offers-game&offer-id=dd21ec96-2890-4ba0-b8e2-40ea67196990", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			offerPlayers, err := models.GetOffersByPlayer(nil, app.DB, "offers-game", "player-1", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offerPlayers).To(BeEmpty())
+		})
+
+		It("should return status code 404 if the player has no state for the offer", func() {
+			request, _ := http.NewRequest("DELETE", "/players/player-1/offers?game-id=offers-game&offer-id=d5114990-77d7-45c4-ba5f-462fc86b213f", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should return status code 400 if offer-id is missing", func() {
+			request, _ := http.NewRequest("DELETE", "/players/player-1/offers?game-id=offers-game", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("GET /players/{id}/export", func() {
+		It("should export the data of the player", func() {
+			request, _ := http.NewRequest("GET", "/players/player-1/export?game-id=offers-game", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["playerId"]).To(Equal("player-1"))
+			games := obj["games"].([]interface{})
+			Expect(games).To(HaveLen(1))
+			game := games[0].(map[string]interface{})
+			Expect(game["gameId"]).To(Equal("offers-game"))
+			Expect(game["offerPlayers"]).To(HaveLen(1))
+		})
+
+		It("should export the data of the player in every game", func() {
+			request, _ := http.NewRequest("GET", "/players/player-1/export", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["games"]).To(HaveLen(1))
+		})
+	})
+
+	Describe("POST /players/merge", func() {
+		It("should merge the state of a player into another", func() {
+			request, _ := http.NewRequest("POST", "/players/merge", JSONFor(JSON{
+				"gameId":       "offers-game",
+				"fromPlayerId": "player-1",
+				"toPlayerId":   "player-2",
+			}))
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{
+				"gameId": "offers-game",
+				"fromPlayerId": "player-1",
+				"toPlayerId": "player-2",
+				"offerPlayers": 1,
+				"dedupeKeys": 0,
+				"claims": 0,
+				"playerImpressions": 0,
+				"receiptTransactions": 0
+			}`))
+			offerPlayers, err := models.GetOffersByPlayer(nil, app.DB, "offers-game", "player-2", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offerPlayers).To(HaveLen(1))
+		})
+
+		It("should return status code 422 if the players are the same", func() {
+			request, _ := http.NewRequest("POST", "/players/merge", JSONFor(JSON{
+				"gameId":       "offers-game",
+				"fromPlayerId": "player-1",
+				"toPlayerId":   "player-1",
+			}))
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		})
+
+		It("should return status code 422 if toPlayerId is missing", func() {
+			request, _ := http.NewRequest("POST", "/players/merge", JSONFor(JSON{
+				"gameId":       "offers-game",
+				"fromPlayerId": "player-1",
+			}))
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		})
+	})
+})
@@ -0,0 +1,112 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	e "github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+)
+
+//configureReceiptVerifiers builds the verifiers under receiptVerifiers.<name>, whose type is apple, google or fake
+func (a *App) configureReceiptVerifiers() error {
+	a.ReceiptVerifiers = map[string]models.ReceiptVerifier{}
+	for name := range a.Config.GetStringMap("receiptVerifiers") {
+		prefix := fmt.Sprintf("receiptVerifiers.%s.", name)
+		switch verifierType := a.Config.GetString(prefix + "type"); verifierType {
+		case models.StoreApple:
+			a.ReceiptVerifiers[name] = models.NewAppleReceiptVerifier(
+				a.Config.GetString(prefix+"sharedSecret"),
+				a.Config.GetBool(prefix+"allowSandbox"),
+				&http.Client{Timeout: 10 * time.Second},
+			)
+		case models.StoreGoogle:
+			verifier, err := models.NewGoogleReceiptVerifier(a.Config.GetString(prefix + "publicKey"))
+			if err != nil {
+				return fmt.Errorf("invalid publicKey of receipt verifier %s: %s", name, err.Error())
+			}
+			a.ReceiptVerifiers[name] = verifier
+		case "fake":
+			a.ReceiptVerifiers[name] = &models.FakeReceiptVerifier{}
+		default:
+			return fmt.Errorf("unknown type %s of receipt verifier %s", verifierType, name)
+		}
+	}
+	return nil
+}
+
+//verifyReceipt verifies the receipt of a claim if the game maps stores to verifiers
+//(receiptVerifiers in its metadata). It writes an error and returns false if the
+//receipt is rejected or could not be verified.
+func (h *OfferRequestHandler) verifyReceipt(
+	w http.ResponseWriter,
+	r *http.Request,
+	metadata map[string]interface{},
+	payload *models.ClaimOfferPayload,
+	t time.Time,
+	logger logrus.FieldLogger,
+) (*models.ReceiptVerification, bool) {
+	stores, _ := metadata["receiptVerifiers"].(map[string]interface{})
+	if len(stores) == 0 {
+		return nil, true
+	}
+
+	name, _ := stores[payload.Store].(string)
+	if name == "" {
+		rErr := e.NewReceiptRejectedError(payload.TransactionID, fmt.Sprintf("store '%s' is not accepted by the game", payload.Store))
+		logger.WithError(rErr).Warn("Receipt rejected.")
+		h.App.HandleError(w, http.StatusPaymentRequired, rErr.Error(), rErr)
+		return nil, false
+	}
+	if payload.Receipt == "" {
+		rErr := e.NewReceiptRejectedError(payload.TransactionID, "missing receipt")
+		logger.WithError(rErr).Warn("Receipt rejected.")
+		h.App.HandleError(w, http.StatusPaymentRequired, rErr.Error(), rErr)
+		return nil, false
+	}
+	verifier, ok := h.App.ReceiptVerifiers[name]
+	if !ok {
+		err := fmt.Errorf("receipt verifier %s is not configured", name)
+		logger.WithError(err).Error("Failed to verify receipt.")
+		h.App.HandleError(w, http.StatusInternalServerError, "Receipt verifier is not configured", err)
+		return nil, false
+	}
+
+	mr := metricsReporterFromCtx(r.Context())
+	var verification *models.ReceiptVerification
+	err := mr.WithExternalSegment(name, func() error {
+		var err error
+		verification, err = verifier.Verify(r.Context(), &models.Receipt{
+			GameID:        payload.GameID,
+			PlayerID:      payload.PlayerID,
+			ProductID:     payload.ProductID,
+			TransactionID: payload.TransactionID,
+			Store:         payload.Store,
+			Data:          payload.Receipt,
+		})
+		return err
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to verify receipt.")
+		h.App.HandleError(w, http.StatusBadGateway, "Failed to verify receipt", err)
+		return nil, false
+	}
+	verification.VerifiedAt = t.Unix()
+
+	if !verification.Valid {
+		rErr := e.NewReceiptRejectedError(payload.TransactionID, verification.Reason)
+		logger.WithError(rErr).Warn("Receipt rejected.")
+		h.App.HandleError(w, http.StatusPaymentRequired, rErr.Error(), rErr)
+		return nil, false
+	}
+	return verification, true
+}
@@ -0,0 +1,106 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	uuid "github.com/satori/go.uuid"
+	"github.com/topfreegames/offers/models"
+	. "github.com/topfreegames/offers/testing"
+	"gopkg.in/mgutz/dat.v2/dat"
+)
+
+var _ = Describe("Receipt Verification", func() {
+	var recorder *httptest.ResponseRecorder
+	gameID := "offers-game"
+	playerID := "player-1"
+	offerID := "dd21ec96-2890-4ba0-b8e2-40ea67196990"
+	offerInstanceID := "56fc0477-39f1-485c-898e-4909e9155eb1"
+
+	claimRequest := func(transactionID, store, receipt string) *http.Request {
+		request, _ := http.NewRequest("PUT", "/offers/claim", JSONFor(JSON{
+			"gameId":        gameID,
+			"playerId":      playerID,
+			"timestamp":     app.Clock.GetTime().Unix(),
+			"transactionId": transactionID,
+			"id":            offerInstanceID,
+			"productId":     "com.tfg.sample",
+			"store":         store,
+			"receipt":       receipt,
+		}))
+		return request
+	}
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+		app.ReceiptVerifiers["fake"] = &models.FakeReceiptVerifier{}
+		_, err := app.DB.Update("games").
+			Set("metadata", dat.JSON([]byte(`{"receiptVerifiers": {"apple": "fake"}}`))).
+			Where("id = $1", gameID).
+			Exec()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		delete(app.ReceiptVerifiers, "fake")
+	})
+
+	It("should claim and store the verification if the receipt is valid", func() {
+		transactionID := uuid.NewV4().String()
+
+		app.Router.ServeHTTP(recorder, claimRequest(transactionID, "apple", "receipt-data"))
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		offerPlayer, err := models.GetOfferPlayer(nil, app.DB, gameID, playerID, offerID, nil)
+		Expect(err).NotTo(HaveOccurred())
+		var receipts map[string]map[string]interface{}
+		Expect(offerPlayer.Receipts.Unmarshal(&receipts)).To(Succeed())
+		Expect(receipts[transactionID]["verifier"]).To(Equal("fake"))
+		Expect(receipts[transactionID]["valid"]).To(BeTrue())
+		Expect(receipts[transactionID]["verifiedAt"]).To(BeEquivalentTo(app.Clock.GetTime().Unix()))
+	})
+
+	It("should return status code 402 if the receipt is rejected", func() {
+		app.Router.ServeHTTP(recorder, claimRequest(uuid.NewV4().String(), "apple", models.FakeInvalidReceipt))
+
+		Expect(recorder.Code).To(Equal(http.StatusPaymentRequired))
+		var obj map[string]interface{}
+		err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj["code"]).To(Equal("OFF-008"))
+		Expect(obj["error"]).To(Equal("ReceiptRejectedError"))
+
+		_, err = models.GetOfferPlayer(nil, app.DB, gameID, playerID, offerID, nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return status code 402 if the receipt is missing", func() {
+		app.Router.ServeHTTP(recorder, claimRequest(uuid.NewV4().String(), "apple", ""))
+
+		Expect(recorder.Code).To(Equal(http.StatusPaymentRequired))
+	})
+
+	It("should return status code 402 if the store is not accepted by the game", func() {
+		app.Router.ServeHTTP(recorder, claimRequest(uuid.NewV4().String(), "google", "receipt-data"))
+
+		Expect(recorder.Code).To(Equal(http.StatusPaymentRequired))
+	})
+
+	It("should return status code 500 if the verifier is not configured", func() {
+		delete(app.ReceiptVerifiers, "fake")
+
+		app.Router.ServeHTTP(recorder, claimRequest(uuid.NewV4().String(), "apple", "receipt-data"))
+
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+	})
+})
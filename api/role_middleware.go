@@ -0,0 +1,159 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/topfreegames/extensions/middleware"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+)
+
+//RoleMiddleware denies the request unless the principal has at least Role in the game returned by GetGameID.
+//If GetGameID returns an empty game only superusers are allowed.
+//Roles are only enforced if rbac.enabled is true.
+type RoleMiddleware struct {
+	App       *App
+	Role      string
+	GetGameID func(r *http.Request) string
+	Next      http.Handler
+}
+
+//NewRoleMiddleware constructs a new role middleware
+func NewRoleMiddleware(app *App, role string, getGameID func(r *http.Request) string) *RoleMiddleware {
+	return &RoleMiddleware{App: app, Role: role, GetGameID: getGameID}
+}
+
+func gameIDFromQuery(r *http.Request) string {
+	return r.URL.Query().Get("game-id")
+}
+
+func gameIDFromParamKey(r *http.Request) string {
+	return paramKeyFromContext(r.Context())
+}
+
+func gameIDFromPayload(r *http.Request) string {
+	switch payload := r.Context().Value(payloadString).(type) {
+	case *models.Offer:
+		return payload.GameID
+	case *models.ScheduledOperation:
+		return payload.GameID
+	case *models.RevokeClaimPayload:
+		return payload.GameID
+	case *models.PreviewOffersPayload:
+		return payload.GameID
+	case *models.MergePlayersPayload:
+		return payload.GameID
+	}
+	return ""
+}
+
+func anyGame(r *http.Request) string {
+	return ""
+}
+
+func (a *App) configureRBAC() {
+	a.Config.SetDefault("rbac.enabled", false)
+	a.Config.SetDefault("rbac.superusers", []string{})
+}
+
+func (a *App) isSuperuser(principal string) bool {
+	if principal == "" {
+		return false
+	}
+	for _, superuser := range a.Config.GetStringSlice("rbac.superusers") {
+		if superuser == principal {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *App) isAllowed(ctx context.Context, principal, gameID, role string) (bool, error) {
+	if principal == "" {
+		return false, nil
+	}
+	if a.isSuperuser(principal) {
+		return true, nil
+	}
+	if gameID == "" {
+		return false, nil
+	}
+
+	gameRole, err := models.GetGameRole(ctx, a.DB, gameID, principal, metricsReporterFromCtx(ctx))
+	if err != nil {
+		if _, ok := err.(*errors.ModelNotFoundError); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	return models.RoleAllows(gameRole.Role, role), nil
+}
+
+//visibleGames filters out the games in which the principal of the request has no role
+func (a *App) visibleGames(ctx context.Context, games []*models.Game) ([]*models.Game, error) {
+	principal := principalFromContext(ctx)
+	if !a.Config.GetBool("rbac.enabled") || a.isSuperuser(principal) {
+		return games, nil
+	}
+	if principal == "" {
+		return []*models.Game{}, nil
+	}
+
+	roles, err := models.ListPrincipalRoles(ctx, a.DB, principal, metricsReporterFromCtx(ctx))
+	if err != nil {
+		return nil, err
+	}
+	gameIDs := map[string]bool{}
+	for _, role := range roles {
+		gameIDs[role.GameID] = true
+	}
+
+	visible := []*models.Game{}
+	for _, game := range games {
+		if gameIDs[game.ID] {
+			visible = append(visible, game)
+		}
+	}
+	return visible, nil
+}
+
+//ServeHTTP method
+func (m *RoleMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !m.App.Config.GetBool("rbac.enabled") {
+		m.Next.ServeHTTP(w, r)
+		return
+	}
+
+	principal := principalFromContext(r.Context())
+	gameID := m.GetGameID(r)
+	allowed, err := m.App.isAllowed(r.Context(), principal, gameID, m.Role)
+	if err != nil {
+		m.App.HandleError(w, http.StatusInternalServerError, "Authorization failed", err)
+		return
+	}
+	if !allowed {
+		if principal == "" {
+			principal = "anonymous"
+		}
+		aErr := errors.NewAccessDeniedError(principal, gameID, m.Role)
+		l := middleware.GetLogger(r.Context())
+		l.WithError(aErr).Warn("Access denied.")
+		WriteBytes(w, http.StatusForbidden, aErr.Serialize())
+		return
+	}
+
+	m.Next.ServeHTTP(w, r)
+}
+
+//SetNext handler
+func (m *RoleMiddleware) SetNext(next http.Handler) {
+	m.Next = next
+}
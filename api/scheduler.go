@@ -0,0 +1,97 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/topfreegames/offers/models"
+)
+
+func (a *App) configureScheduler() {
+	a.Config.SetDefault("scheduler.enabled", true)
+	a.Config.SetDefault("scheduler.intervalSeconds", 10)
+	a.Config.SetDefault("scheduler.batchSize", 100)
+	a.Config.SetDefault("dedupe.retentionSeconds", 2592000)
+	a.Config.SetDefault("dedupe.batchSize", 1000)
+}
+
+//RunScheduler executes the due scheduled offer operations every scheduler.intervalSeconds until stop is closed.
+//It also removes the request signatures that can no longer be replayed and the impression
+//and transaction ids older than dedupe.retentionSeconds, dedupe.batchSize at a time.
+func (a *App) RunScheduler(stop <-chan struct{}) {
+	interval := time.Duration(a.Config.GetInt64("scheduler.intervalSeconds")) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			a.runScheduledOperations()
+			a.deleteExpiredRequestSignatures()
+			a.deleteExpiredDedupeKeys()
+		}
+	}
+}
+
+func (a *App) runScheduledOperations() {
+	l := a.Logger.WithFields(logrus.Fields{
+		"source":    "scheduler",
+		"operation": "runScheduledOperations",
+	})
+
+	batchSize := uint64(a.Config.GetInt64("scheduler.batchSize"))
+	executed, err := models.RunScheduledOperations(
+		context.Background(), a.DB, a.Cache, a.Clock.GetTime(), batchSize, models.NewMixedMetricsReporter(),
+	)
+	if err != nil {
+		l.WithError(err).Error("Failed to run scheduled operations.")
+		return
+	}
+	if executed > 0 {
+		l.WithField("executed", executed).Info("Ran scheduled operations.")
+	}
+}
+
+func (a *App) deleteExpiredRequestSignatures() {
+	l := a.Logger.WithFields(logrus.Fields{
+		"source":    "scheduler",
+		"operation": "deleteExpiredRequestSignatures",
+	})
+
+	_, err := models.DeleteExpiredRequestSignatures(
+		context.Background(), a.DB, a.Clock.GetTime(), models.NewMixedMetricsReporter(),
+	)
+	if err != nil {
+		l.WithError(err).Error("Failed to delete expired request signatures.")
+	}
+}
+
+func (a *App) deleteExpiredDedupeKeys() {
+	l := a.Logger.WithFields(logrus.Fields{
+		"source":    "scheduler",
+		"operation": "deleteExpiredDedupeKeys",
+	})
+
+	retention := time.Duration(a.Config.GetInt64("dedupe.retentionSeconds")) * time.Second
+	batchSize := uint64(a.Config.GetInt64("dedupe.batchSize"))
+	deleted, err := models.DeleteExpiredDedupeKeys(
+		context.Background(), a.DB, a.Clock.GetTime().Add(-retention), batchSize, models.NewMixedMetricsReporter(),
+	)
+	if err != nil {
+		l.WithError(err).Error("Failed to delete expired dedupe keys.")
+		return
+	}
+	if deleted > 0 {
+		l.WithField("deleted", deleted).Info("Deleted expired dedupe keys.")
+	}
+}
@@ -0,0 +1,184 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/topfreegames/extensions/middleware"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+)
+
+//Headers of signed player requests
+const (
+	SignatureHeader          = "x-offers-signature"
+	SignatureTimestampHeader = "x-offers-timestamp"
+)
+
+//SignatureMiddleware verifies that player requests are signed with a client key of their game,
+//read from the same place the handler reads it from. Unknown games and games without client keys
+//are only rejected if signing.required is true.
+//Each signature is accepted once while its timestamp is inside the allowed window.
+type SignatureMiddleware struct {
+	App       *App
+	GetGameID func(r *http.Request, body []byte) (string, error)
+	Next      http.Handler
+}
+
+//NewSignatureMiddleware constructs a new signature middleware
+func NewSignatureMiddleware(app *App, getGameID func(r *http.Request, body []byte) (string, error)) *SignatureMiddleware {
+	return &SignatureMiddleware{App: app, GetGameID: getGameID}
+}
+
+func (a *App) configureSigning() {
+	a.Config.SetDefault("signing.required", false)
+	a.Config.SetDefault("signing.maxSkewSeconds", 300)
+	a.Config.SetDefault("signing.gracePeriodSeconds", 86400)
+}
+
+//SignRequest returns the hex encoded HMAC-SHA256, using the game client key,
+//of the method, request uri (path and query), unix timestamp and body of a request
+func SignRequest(key, method, uri string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(strings.ToUpper(method)))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(uri))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func signedGameIDFromQuery(r *http.Request, body []byte) (string, error) {
+	return r.URL.Query().Get("game-id"), nil
+}
+
+//signedGameIDFromBody returns the gameId of the body, which must match the game-id parameter if it is sent
+func signedGameIDFromBody(r *http.Request, body []byte) (string, error) {
+	var payload struct {
+		GameID string `json:"gameId"`
+	}
+	json.Unmarshal(body, &payload)
+	if gameID := r.URL.Query().Get("game-id"); gameID != "" && gameID != payload.GameID {
+		return "", fmt.Errorf("the game-id parameter does not match the gameId of the body")
+	}
+	return payload.GameID, nil
+}
+
+//ServeHTTP method
+func (m *SignatureMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		m.App.HandleError(w, http.StatusBadRequest, "Reading request body failed", err)
+		return
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	gameID, err := m.GetGameID(r, body)
+	if err != nil {
+		m.App.HandleError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	required := m.App.Config.GetBool("signing.required")
+	if gameID == "" {
+		if required {
+			m.reject(w, r, http.StatusForbidden, gameID, "the game id is missing")
+			return
+		}
+		m.Next.ServeHTTP(w, r)
+		return
+	}
+
+	mr := metricsReporterFromCtx(r.Context())
+	game, err := models.GetGameByID(r.Context(), m.App.DB, gameID, mr)
+	if err != nil {
+		if _, ok := err.(*errors.ModelNotFoundError); ok {
+			if required {
+				m.reject(w, r, http.StatusForbidden, gameID, "the game does not exist")
+				return
+			}
+			m.Next.ServeHTTP(w, r)
+			return
+		}
+		m.App.HandleError(w, http.StatusInternalServerError, "Signature verification failed", err)
+		return
+	}
+
+	currentTime := m.App.Clock.GetTime()
+	keys := game.ClientKeysAt(currentTime)
+	if len(keys) == 0 {
+		if required {
+			m.reject(w, r, http.StatusUnauthorized, gameID, "the game has no client key")
+			return
+		}
+		m.Next.ServeHTTP(w, r)
+		return
+	}
+
+	timestamp, err := strconv.ParseInt(r.Header.Get(SignatureTimestampHeader), 10, 64)
+	if err != nil {
+		m.reject(w, r, http.StatusUnauthorized, gameID, "missing or invalid "+SignatureTimestampHeader+" header")
+		return
+	}
+	maxSkew := time.Duration(m.App.Config.GetInt64("signing.maxSkewSeconds")) * time.Second
+	signedAt := time.Unix(timestamp, 0)
+	if signedAt.Before(currentTime.Add(-maxSkew)) || signedAt.After(currentTime.Add(maxSkew)) {
+		m.reject(w, r, http.StatusUnauthorized, gameID, "the request timestamp is outside the allowed window")
+		return
+	}
+
+	signature := strings.ToLower(r.Header.Get(SignatureHeader))
+	valid := false
+	for _, key := range keys {
+		expected := SignRequest(key, r.Method, r.URL.RequestURI(), timestamp, body)
+		if hmac.Equal([]byte(expected), []byte(signature)) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		m.reject(w, r, http.StatusUnauthorized, gameID, "invalid signature")
+		return
+	}
+
+	registered, err := models.RegisterRequestSignature(r.Context(), m.App.DB, gameID, signature, signedAt.Add(maxSkew), mr)
+	if err != nil {
+		m.App.HandleError(w, http.StatusInternalServerError, "Signature verification failed", err)
+		return
+	}
+	if !registered {
+		m.reject(w, r, http.StatusUnauthorized, gameID, "the request was already received")
+		return
+	}
+
+	m.Next.ServeHTTP(w, r)
+}
+
+func (m *SignatureMiddleware) reject(w http.ResponseWriter, r *http.Request, status int, gameID, reason string) {
+	sErr := errors.NewInvalidSignatureError(gameID, reason)
+	l := middleware.GetLogger(r.Context())
+	l.WithError(sErr).Warn("Request signature rejected.")
+	WriteBytes(w, status, sErr.Serialize())
+}
+
+//SetNext handler
+func (m *SignatureMiddleware) SetNext(next http.Handler) {
+	m.Next = next
+}
@@ -0,0 +1,166 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/topfreegames/offers/api"
+	"github.com/topfreegames/offers/models"
+)
+
+var _ = Describe("Signature Middleware", func() {
+	var recorder *httptest.ResponseRecorder
+	var clientKey string
+	currentTime := time.Unix(1486678000, 0)
+	url := "/available-offers?player-id=john-doe&game-id=offers-game"
+
+	signedRequest := func(key, method, uri string, timestamp int64, body []byte) *http.Request {
+		request, _ := http.NewRequest(method, uri, bytes.NewReader(body))
+		request.Header.Set(api.SignatureTimestampHeader, strconv.FormatInt(timestamp, 10))
+		request.Header.Set(api.SignatureHeader, api.SignRequest(key, method, uri, timestamp, body))
+		return request
+	}
+
+	expectRejected := func() {
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		var obj map[string]interface{}
+		err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj["code"]).To(Equal("OFF-006"))
+		Expect(obj["error"]).To(Equal("InvalidSignatureError"))
+	}
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+		game, err := models.RotateGameClientKey(nil, app.DB, "offers-game", currentTime, time.Hour, nil)
+		Expect(err).NotTo(HaveOccurred())
+		clientKey = game.ClientKey
+	})
+
+	It("should accept signed requests", func() {
+		request := signedRequest(clientKey, "GET", url, currentTime.Unix(), nil)
+		app.Router.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+
+	It("should reject unsigned requests", func() {
+		request, _ := http.NewRequest("GET", url, nil)
+		app.Router.ServeHTTP(recorder, request)
+
+		expectRejected()
+	})
+
+	It("should reject requests signed with another key", func() {
+		request := signedRequest("another-key", "GET", url, currentTime.Unix(), nil)
+		app.Router.ServeHTTP(recorder, request)
+
+		expectRejected()
+	})
+
+	It("should reject requests whose body was changed", func() {
+		body := []byte(`{"gameId": "offers-game", "playerId": "john-doe", "impressionId": "56b3d3e8-4e67-4d4b-8e5b-4a2fd1d3c6d1"}`)
+		request := signedRequest(clientKey, "PUT", "/offers/dd21ec96-2890-4ba0-b8e2-40ea67196990/impressions", currentTime.Unix(), body)
+		request.Body = ioutil.NopCloser(bytes.NewReader(bytes.Replace(body, []byte("john-doe"), []byte("jane-doe"), 1)))
+		app.Router.ServeHTTP(recorder, request)
+
+		expectRejected()
+	})
+
+	It("should reject replayed requests", func() {
+		request := signedRequest(clientKey, "GET", url, currentTime.Unix(), nil)
+		app.Router.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		recorder = httptest.NewRecorder()
+		request = signedRequest(clientKey, "GET", url, currentTime.Unix(), nil)
+		app.Router.ServeHTTP(recorder, request)
+
+		expectRejected()
+	})
+
+	It("should reject requests with old timestamps", func() {
+		request := signedRequest(clientKey, "GET", url, currentTime.Add(-time.Hour).Unix(), nil)
+		app.Router.ServeHTTP(recorder, request)
+
+		expectRejected()
+	})
+
+	It("should accept the previous key during the grace period", func() {
+		rotateRecorder := httptest.NewRecorder()
+		rotate, _ := http.NewRequest("POST", "/games/offers-game/client-key", nil)
+		app.Router.ServeHTTP(rotateRecorder, rotate)
+		Expect(rotateRecorder.Code).To(Equal(http.StatusOK))
+		var obj map[string]interface{}
+		err := json.Unmarshal([]byte(rotateRecorder.Body.String()), &obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj["clientKey"]).NotTo(Equal(clientKey))
+		Expect(obj["previousClientKeyExpiresAt"]).To(BeEquivalentTo(currentTime.Unix() + 86400))
+
+		request := signedRequest(clientKey, "GET", url, currentTime.Unix(), nil)
+		app.Router.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		recorder = httptest.NewRecorder()
+		request = signedRequest(obj["clientKey"].(string), "GET", url, currentTime.Unix()+1, nil)
+		app.Router.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+
+	It("should not require signatures from games without client keys", func() {
+		request, _ := http.NewRequest("GET", "/available-offers?player-id=john-doe&game-id=offers-game-2", nil)
+		app.Router.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).NotTo(Equal(http.StatusUnauthorized))
+	})
+
+	It("should require signatures from every game if signing is required", func() {
+		config.Set("signing.required", true)
+		defer config.Set("signing.required", false)
+
+		request, _ := http.NewRequest("GET", "/available-offers?player-id=john-doe&game-id=offers-game-2", nil)
+		app.Router.ServeHTTP(recorder, request)
+
+		expectRejected()
+	})
+
+	It("should reject requests to games that do not exist if signing is required", func() {
+		config.Set("signing.required", true)
+		defer config.Set("signing.required", false)
+
+		request, _ := http.NewRequest("GET", "/available-offers?player-id=john-doe&game-id=unknown-game", nil)
+		app.Router.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("should check the signature of the game of the body", func() {
+		body := []byte(`{"gameId": "offers-game", "playerId": "john-doe", "impressionId": "56b3d3e8-4e67-4d4b-8e5b-4a2fd1d3c6d1"}`)
+		request, _ := http.NewRequest("PUT", "/offers/dd21ec96-2890-4ba0-b8e2-40ea67196990/impressions?game-id=offers-game", bytes.NewReader(body))
+		app.Router.ServeHTTP(recorder, request)
+
+		expectRejected()
+	})
+
+	It("should reject requests whose game-id does not match the gameId of the body", func() {
+		body := []byte(`{"gameId": "offers-game", "playerId": "john-doe", "impressionId": "56b3d3e8-4e67-4d4b-8e5b-4a2fd1d3c6d1"}`)
+		request, _ := http.NewRequest("PUT", "/offers/dd21ec96-2890-4ba0-b8e2-40ea67196990/impressions?game-id=unknown-game", bytes.NewReader(body))
+		app.Router.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+	})
+})
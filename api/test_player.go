@@ -0,0 +1,145 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+)
+
+//TestPlayerHandler handler
+type TestPlayerHandler struct {
+	App    *App
+	Method string
+}
+
+//ServeHTTP method
+func (h *TestPlayerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch h.Method {
+	case "list":
+		h.list(w, r)
+		return
+	case "add":
+		h.add(w, r)
+		return
+	case "remove":
+		h.remove(w, r)
+		return
+	}
+}
+
+func (h *TestPlayerHandler) list(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	gameID := paramKeyFromContext(r.Context())
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "testPlayerHandler",
+		"operation": "list",
+		"userEmail": userEmail,
+		"gameID":    gameID,
+	})
+
+	var err error
+	var testPlayers []*models.TestPlayer
+	err = mr.WithSegment(models.SegmentModel, func() error {
+		testPlayers, err = models.ListTestPlayers(r.Context(), h.App.DB, gameID, mr)
+		return err
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("List test players failed.")
+		h.App.HandleError(w, http.StatusInternalServerError, "List test players failed.", err)
+		return
+	}
+
+	logger.Info("Listed test players successfully.")
+	bytesRes, _ := json.Marshal(map[string]interface{}{"testPlayers": testPlayers})
+	WriteBytes(w, http.StatusOK, bytesRes)
+}
+
+func (h *TestPlayerHandler) add(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	testPlayer := testPlayerFromCtx(r.Context())
+	testPlayer.GameID = paramKeyFromContext(r.Context())
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "testPlayerHandler",
+		"operation": "add",
+		"userEmail": userEmail,
+		"gameID":    testPlayer.GameID,
+		"playerID":  testPlayer.PlayerID,
+	})
+
+	err := mr.WithSegment(models.SegmentModel, func() error {
+		return models.AddTestPlayer(r.Context(), h.App.DB, testPlayer, h.App.Cache, h.App.Clock.GetTime(), mr)
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Add test player failed.")
+		if invalidModel, ok := err.(*errors.InvalidModelError); ok {
+			h.App.HandleError(w, http.StatusUnprocessableEntity, invalidModel.Error(), invalidModel)
+			return
+		}
+		h.App.HandleError(w, http.StatusInternalServerError, "Add test player failed", err)
+		return
+	}
+
+	logger.Info("Added test player successfully.")
+	bytesRes, _ := json.Marshal(testPlayer)
+	WriteBytes(w, http.StatusOK, bytesRes)
+}
+
+func (h *TestPlayerHandler) remove(w http.ResponseWriter, r *http.Request) {
+	mr := metricsReporterFromCtx(r.Context())
+	gameID := paramKeyFromContext(r.Context())
+	playerID := r.URL.Query().Get("player-id")
+	userEmail := userEmailFromContext(r.Context())
+
+	logger := h.App.Logger.WithFields(logrus.Fields{
+		"source":    "testPlayerHandler",
+		"operation": "remove",
+		"userEmail": userEmail,
+		"gameID":    gameID,
+		"playerID":  playerID,
+	})
+
+	if playerID == "" {
+		err := fmt.Errorf("The player-id parameter cannot be empty")
+		logger.WithError(err).Error("Remove test player failed.")
+		h.App.HandleError(w, http.StatusBadRequest, "The player-id parameter cannot be empty.", err)
+		return
+	}
+
+	err := mr.WithSegment(models.SegmentModel, func() error {
+		return models.RemoveTestPlayer(r.Context(), h.App.DB, gameID, playerID, h.App.Cache, mr)
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Remove test player failed.")
+		if modelNotFound, ok := err.(*errors.ModelNotFoundError); ok {
+			h.App.HandleError(w, http.StatusNotFound, modelNotFound.Error(), modelNotFound)
+			return
+		}
+		h.App.HandleError(w, http.StatusInternalServerError, "Remove test player failed", err)
+		return
+	}
+
+	logger.Info("Removed test player successfully.")
+	bytesRes, _ := json.Marshal(map[string]interface{}{
+		"gameId":   gameID,
+		"playerId": playerID,
+	})
+	WriteBytes(w, http.StatusOK, bytesRes)
+}
@@ -0,0 +1,117 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/topfreegames/offers/models"
+	. "github.com/topfreegames/offers/testing"
+)
+
+var _ = Describe("Test Player Handler", func() {
+	var recorder *httptest.ResponseRecorder
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+	})
+
+	Describe("PUT /games/{id}/test-players", func() {
+		It("should add a test player", func() {
+			request, _ := http.NewRequest("PUT", "/games/offers-game/test-players", JSONFor(JSON{
+				"playerId": "qa-player",
+			}))
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var obj map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["gameId"]).To(Equal("offers-game"))
+			Expect(obj["playerId"]).To(Equal("qa-player"))
+
+			isTestPlayer, err := models.IsTestPlayer(nil, app.DB, "offers-game", "qa-player", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isTestPlayer).To(BeTrue())
+		})
+
+		It("should return status code 422 if the player id is missing", func() {
+			request, _ := http.NewRequest("PUT", "/games/offers-game/test-players", JSONFor(JSON{}))
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		})
+
+		It("should return status code 422 if the game does not exist", func() {
+			request, _ := http.NewRequest("PUT", "/games/non-existing-game/test-players", JSONFor(JSON{
+				"playerId": "qa-player",
+			}))
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnprocessableEntity))
+		})
+	})
+
+	Describe("GET /games/{id}/test-players", func() {
+		It("should list the test players of the game", func() {
+			request, _ := http.NewRequest("PUT", "/games/offers-game/test-players", JSONFor(JSON{
+				"playerId": "qa-player",
+			}))
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/games/offers-game/test-players", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var obj map[string][]map[string]interface{}
+			err := json.Unmarshal([]byte(recorder.Body.String()), &obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj["testPlayers"]).To(HaveLen(1))
+			Expect(obj["testPlayers"][0]["playerId"]).To(Equal("qa-player"))
+		})
+	})
+
+	Describe("DELETE /games/{id}/test-players", func() {
+		It("should remove a test player", func() {
+			request, _ := http.NewRequest("PUT", "/games/offers-game/test-players", JSONFor(JSON{
+				"playerId": "qa-player",
+			}))
+			app.Router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest("DELETE", "/games/offers-game/test-players?player-id=qa-player", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			isTestPlayer, err := models.IsTestPlayer(nil, app.DB, "offers-game", "qa-player", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isTestPlayer).To(BeFalse())
+		})
+
+		It("should return status code 404 if the player is not a test player", func() {
+			request, _ := http.NewRequest("DELETE", "/games/offers-game/test-players?player-id=qa-player", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should return status code 400 if player-id is missing", func() {
+			request, _ := http.NewRequest("DELETE", "/games/offers-game/test-players", nil)
+			app.Router.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
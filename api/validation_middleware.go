@@ -62,6 +62,46 @@ func offerFromCtx(ctx context.Context) *models.Offer {
 	return offer.(*models.Offer)
 }
 
+func scheduledOperationFromCtx(ctx context.Context) *models.ScheduledOperation {
+	op := ctx.Value(payloadString)
+	if op == nil {
+		return nil
+	}
+	return op.(*models.ScheduledOperation)
+}
+
+func gameRoleFromCtx(ctx context.Context) *models.GameRole {
+	role := ctx.Value(payloadString)
+	if role == nil {
+		return nil
+	}
+	return role.(*models.GameRole)
+}
+
+func testPlayerFromCtx(ctx context.Context) *models.TestPlayer {
+	testPlayer := ctx.Value(payloadString)
+	if testPlayer == nil {
+		return nil
+	}
+	return testPlayer.(*models.TestPlayer)
+}
+
+func placementFromCtx(ctx context.Context) *models.Placement {
+	placement := ctx.Value(payloadString)
+	if placement == nil {
+		return nil
+	}
+	return placement.(*models.Placement)
+}
+
+func apiKeyFromCtx(ctx context.Context) *models.APIKey {
+	apiKey := ctx.Value(payloadString)
+	if apiKey == nil {
+		return nil
+	}
+	return apiKey.(*models.APIKey)
+}
+
 func offerImpressionPayloadFromCtx(ctx context.Context) *models.OfferImpressionPayload {
 	payload := ctx.Value(payloadString)
 	if payload == nil {
@@ -70,6 +110,30 @@ func offerImpressionPayloadFromCtx(ctx context.Context) *models.OfferImpressionP
 	return payload.(*models.OfferImpressionPayload)
 }
 
+func revokeClaimPayloadFromCtx(ctx context.Context) *models.RevokeClaimPayload {
+	payload := ctx.Value(payloadString)
+	if payload == nil {
+		return nil
+	}
+	return payload.(*models.RevokeClaimPayload)
+}
+
+func previewOffersPayloadFromCtx(ctx context.Context) *models.PreviewOffersPayload {
+	payload := ctx.Value(payloadString)
+	if payload == nil {
+		return nil
+	}
+	return payload.(*models.PreviewOffersPayload)
+}
+
+func mergePlayersPayloadFromCtx(ctx context.Context) *models.MergePlayersPayload {
+	payload := ctx.Value(payloadString)
+	if payload == nil {
+		return nil
+	}
+	return payload.(*models.MergePlayersPayload)
+}
+
 func claimOfferPayloadFromCtx(ctx context.Context) *models.ClaimOfferPayload {
 	payload := ctx.Value(payloadString)
 	if payload == nil {
@@ -170,6 +234,30 @@ func (m *ValidationMiddleware) configureCustomValidators() {
 			},
 		),
 	)
+	govalidator.CustomTypeTagMap.Set(
+		"NonNegativeNullInt",
+		govalidator.CustomTypeValidator(
+			func(i interface{}, context interface{}) bool {
+				switch v := i.(type) {
+				case dat.NullInt64:
+					return !v.Valid || v.Int64 >= 0
+				}
+				return false
+			},
+		),
+	)
+	govalidator.CustomTypeTagMap.Set(
+		"NullUUIDv4",
+		govalidator.CustomTypeValidator(
+			func(i interface{}, context interface{}) bool {
+				switch v := i.(type) {
+				case dat.NullString:
+					return !v.Valid || govalidator.IsUUIDv4(v.String)
+				}
+				return false
+			},
+		),
+	)
 	govalidator.CustomTypeTagMap.Set(
 		"JSONObject",
 		govalidator.CustomTypeValidator(
@@ -0,0 +1,66 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/topfreegames/offers/models"
+)
+
+var exportGameID string
+
+//RunExport writes the snapshot of a game as JSON into writer
+func RunExport(gameID string, writer io.Writer) error {
+	if gameID == "" {
+		return fmt.Errorf("the game flag cannot be empty")
+	}
+	if writer == nil {
+		writer = os.Stdout
+	}
+
+	database, err := getDBForConvert()
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := models.ExportGame(nil, database, gameID, nil)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snapshot)
+}
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "exports a game snapshot",
+	Long:  `Exports a game, its offers and their current versions as JSON to stdout`,
+	Run: func(cmd *cobra.Command, args []string) {
+		InitConfig()
+		err := RunExport(exportGameID, nil)
+		if err != nil {
+			log.Println(err)
+			panic(err.Error())
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVarP(&exportGameID, "game", "g", "", "ID of the game to export")
+}
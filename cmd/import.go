@@ -0,0 +1,85 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/topfreegames/offers/models"
+)
+
+var importTargetGameID string
+
+//RunImport reads a game snapshot from reader and stores it under targetGameID
+func RunImport(reader io.Reader, targetGameID string, writer io.Writer) error {
+	if writer == nil {
+		writer = os.Stdout
+	}
+
+	var snapshot models.GameSnapshot
+	err := json.NewDecoder(reader).Decode(&snapshot)
+	if err != nil {
+		return err
+	}
+	if snapshot.Game == nil {
+		return fmt.Errorf("the snapshot does not contain a game")
+	}
+	if targetGameID == "" {
+		targetGameID = snapshot.Game.ID
+	}
+
+	database, err := getDBForConvert()
+	if err != nil {
+		return err
+	}
+
+	result, err := models.ImportGame(nil, database, &snapshot, targetGameID, time.Now(), nil)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import <snapshot.json>",
+	Short: "imports a game snapshot",
+	Long: `Imports a game snapshot created with the export command. IDs are kept
+when possible and remapped otherwise; the mapping is printed to stdout`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		InitConfig()
+		file, err := os.Open(args[0])
+		if err != nil {
+			log.Println(err)
+			panic(err.Error())
+		}
+		defer file.Close()
+
+		err = RunImport(file, importTargetGameID, nil)
+		if err != nil {
+			log.Println(err)
+			panic(err.Error())
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVarP(&importTargetGameID, "target-game", "t", "", "ID of the game to import into (defaults to the exported game ID)")
+}
@@ -0,0 +1,156 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/topfreegames/offers/models"
+)
+
+var purgeFile string
+var purgeGameID string
+var playerExportID string
+var playerExportGameID string
+
+//RunPurge erases the players whose ids are read, one per line, from reader. If gameID is empty
+//they are erased from every game. The result of each erasure is written as JSON into writer.
+func RunPurge(reader io.Reader, gameID string, writer io.Writer) error {
+	if writer == nil {
+		writer = os.Stdout
+	}
+
+	playerIDs := []string{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		playerID := strings.TrimSpace(scanner.Text())
+		if playerID != "" {
+			playerIDs = append(playerIDs, playerID)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	database, err := getDBForConvert()
+	if err != nil {
+		return err
+	}
+
+	gameIDs := []string{gameID}
+	if gameID == "" {
+		games, err := models.ListGames(nil, database, nil)
+		if err != nil {
+			return err
+		}
+		gameIDs = []string{}
+		for _, game := range games {
+			gameIDs = append(gameIDs, game.ID)
+		}
+	}
+
+	ctx := models.NewContextWithAuditInfo(nil, "cli:purge", "")
+	encoder := json.NewEncoder(writer)
+	for _, playerID := range playerIDs {
+		for _, id := range gameIDs {
+			erasure, err := models.ErasePlayer(ctx, database, id, playerID, nil)
+			if err != nil {
+				return fmt.Errorf("failed to erase player from game %s: %s", id, err.Error())
+			}
+			err = encoder.Encode(erasure)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//RunPlayerExport writes as JSON into writer everything stored about a player in a game,
+//or in every game if gameID is empty
+func RunPlayerExport(playerID, gameID string, writer io.Writer) error {
+	if playerID == "" {
+		return fmt.Errorf("the player flag cannot be empty")
+	}
+	if writer == nil {
+		writer = os.Stdout
+	}
+
+	database, err := getDBForConvert()
+	if err != nil {
+		return err
+	}
+
+	return models.ExportPlayer(nil, database, gameID, playerID, time.Now(), writer, nil)
+}
+
+// playersCmd represents the players command
+var playersCmd = &cobra.Command{
+	Use:   "players",
+	Short: "manages player data",
+	Long:  `Manages the data stored about players`,
+}
+
+// purgeCmd represents the players purge command
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "erases players",
+	Long: `Erases the players whose ids are listed, one per line, in the given file.
+Their state is removed and their claims are anonymized; the result of each
+erasure is printed to stdout`,
+	Run: func(cmd *cobra.Command, args []string) {
+		InitConfig()
+		file, err := os.Open(purgeFile)
+		if err != nil {
+			log.Println(err)
+			panic(err.Error())
+		}
+		defer file.Close()
+
+		err = RunPurge(file, purgeGameID, nil)
+		if err != nil {
+			log.Println(err)
+			panic(err.Error())
+		}
+	},
+}
+
+// playerExportCmd represents the players export command
+var playerExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "exports the data of a player",
+	Long: `Exports everything stored about a player, in one game or in every game,
+as JSON to stdout`,
+	Run: func(cmd *cobra.Command, args []string) {
+		InitConfig()
+		err := RunPlayerExport(playerExportID, playerExportGameID, nil)
+		if err != nil {
+			log.Println(err)
+			panic(err.Error())
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(playersCmd)
+	playersCmd.AddCommand(purgeCmd)
+	playersCmd.AddCommand(playerExportCmd)
+
+	purgeCmd.Flags().StringVarP(&purgeFile, "file", "f", "", "file with the ids of the players to erase, one per line")
+	purgeCmd.Flags().StringVarP(&purgeGameID, "game", "g", "", "ID of the game to erase the players from (defaults to every game)")
+	playerExportCmd.Flags().StringVarP(&playerExportID, "player", "p", "", "ID of the player to export")
+	playerExportCmd.Flags().StringVarP(&playerExportGameID, "game", "g", "", "ID of the game to export the player from (defaults to every game)")
+}
@@ -0,0 +1,50 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+//AccessDeniedError happens when a principal lacks the role required by a route
+type AccessDeniedError struct {
+	Principal    string
+	GameID       string
+	RequiredRole string
+}
+
+//NewAccessDeniedError ctor
+func NewAccessDeniedError(principal, gameID, requiredRole string) *AccessDeniedError {
+	return &AccessDeniedError{
+		Principal:    principal,
+		GameID:       gameID,
+		RequiredRole: requiredRole,
+	}
+}
+
+func (e *AccessDeniedError) Error() string {
+	if e.GameID == "" {
+		return fmt.Sprintf("%s is not allowed to manage all games.", e.Principal)
+	}
+	return fmt.Sprintf("%s requires role %s in game %s.", e.Principal, e.RequiredRole, e.GameID)
+}
+
+//Serialize returns the error serialized
+func (e *AccessDeniedError) Serialize() []byte {
+	g, _ := json.Marshal(map[string]interface{}{
+		"code":         "OFF-005",
+		"error":        "AccessDeniedError",
+		"description":  e.Error(),
+		"principal":    e.Principal,
+		"gameId":       e.GameID,
+		"requiredRole": e.RequiredRole,
+	})
+
+	return g
+}
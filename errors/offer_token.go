@@ -0,0 +1,42 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+//InvalidOfferTokenError happens when an offer is claimed or viewed without a valid token from available offers
+type InvalidOfferTokenError struct {
+	OfferInstanceID string
+	Reason          string
+}
+
+//NewInvalidOfferTokenError ctor
+func NewInvalidOfferTokenError(offerInstanceID, reason string) *InvalidOfferTokenError {
+	return &InvalidOfferTokenError{
+		OfferInstanceID: offerInstanceID,
+		Reason:          reason,
+	}
+}
+
+func (e *InvalidOfferTokenError) Error() string {
+	return fmt.Sprintf("Offer %s rejected: %s.", e.OfferInstanceID, e.Reason)
+}
+
+//Serialize returns the error serialized
+func (e *InvalidOfferTokenError) Serialize() []byte {
+	g, _ := json.Marshal(map[string]interface{}{
+		"code":        "OFF-007",
+		"error":       "InvalidOfferTokenError",
+		"description": e.Error(),
+	})
+
+	return g
+}
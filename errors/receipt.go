@@ -0,0 +1,43 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+//ReceiptRejectedError happens when the store does not confirm the purchase of a claimed offer
+type ReceiptRejectedError struct {
+	TransactionID string
+	Reason        string
+}
+
+//NewReceiptRejectedError ctor
+func NewReceiptRejectedError(transactionID, reason string) *ReceiptRejectedError {
+	return &ReceiptRejectedError{
+		TransactionID: transactionID,
+		Reason:        reason,
+	}
+}
+
+func (e *ReceiptRejectedError) Error() string {
+	return fmt.Sprintf("Receipt of transaction %s rejected: %s.", e.TransactionID, e.Reason)
+}
+
+//Serialize returns the error serialized
+func (e *ReceiptRejectedError) Serialize() []byte {
+	g, _ := json.Marshal(map[string]interface{}{
+		"code":          "OFF-008",
+		"error":         "ReceiptRejectedError",
+		"description":   e.Error(),
+		"transactionId": e.TransactionID,
+	})
+
+	return g
+}
@@ -0,0 +1,43 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+//InvalidSignatureError happens when a player request is not signed with a valid client key of its game
+type InvalidSignatureError struct {
+	GameID string
+	Reason string
+}
+
+//NewInvalidSignatureError ctor
+func NewInvalidSignatureError(gameID, reason string) *InvalidSignatureError {
+	return &InvalidSignatureError{
+		GameID: gameID,
+		Reason: reason,
+	}
+}
+
+func (e *InvalidSignatureError) Error() string {
+	return fmt.Sprintf("Request to game %s rejected: %s.", e.GameID, e.Reason)
+}
+
+//Serialize returns the error serialized
+func (e *InvalidSignatureError) Serialize() []byte {
+	g, _ := json.Marshal(map[string]interface{}{
+		"code":        "OFF-006",
+		"error":       "InvalidSignatureError",
+		"description": e.Error(),
+		"gameId":      e.GameID,
+	})
+
+	return g
+}
@@ -0,0 +1,41 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+//OfferSoldOutError happens when an offer with a limited stock is claimed after all of it was sold
+type OfferSoldOutError struct {
+	OfferID string
+}
+
+//NewOfferSoldOutError ctor
+func NewOfferSoldOutError(offerID string) *OfferSoldOutError {
+	return &OfferSoldOutError{
+		OfferID: offerID,
+	}
+}
+
+func (e *OfferSoldOutError) Error() string {
+	return fmt.Sprintf("Offer %s is sold out.", e.OfferID)
+}
+
+//Serialize returns the error serialized
+func (e *OfferSoldOutError) Serialize() []byte {
+	g, _ := json.Marshal(map[string]interface{}{
+		"code":        "OFF-009",
+		"error":       "OfferSoldOutError",
+		"description": e.Error(),
+		"offerId":     e.OfferID,
+	})
+
+	return g
+}
@@ -0,0 +1,20 @@
+CREATE TABLE offer_drafts (
+    id char(36) PRIMARY KEY DEFAULT uuid_generate_v4(),
+    game_id varchar(255) NOT NULL REFERENCES games(id),
+    offer_id char(36) NOT NULL REFERENCES offers(id),
+    name varchar(255) NOT NULL,
+    period JSONB NOT NULL DEFAULT '{}'::JSONB,
+    frequency JSONB NOT NULL DEFAULT '{}'::JSONB,
+    trigger JSONB NOT NULL DEFAULT '{}'::JSONB,
+    placement varchar(255) NOT NULL,
+    metadata JSONB NOT NULL DEFAULT '{}'::JSONB,
+    product_id varchar(255),
+    contents JSONB NOT NULL DEFAULT '{}'::JSONB,
+    filters JSONB NOT NULL DEFAULT '{}'::JSONB,
+    cost JSONB NOT NULL DEFAULT '{}'::JSONB,
+    author varchar(255) NOT NULL DEFAULT '',
+    created_at timestamp WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at timestamp WITH TIME ZONE NULL
+);
+
+CREATE UNIQUE INDEX offer_drafts_game_id_offer_id ON offer_drafts (game_id, offer_id);
@@ -0,0 +1,15 @@
+CREATE TABLE scheduled_operations (
+    id char(36) PRIMARY KEY DEFAULT uuid_generate_v4(),
+    game_id varchar(255) NOT NULL REFERENCES games(id),
+    offer_id char(36) NOT NULL REFERENCES offers(id),
+    operation varchar(255) NOT NULL,
+    patch JSONB NOT NULL DEFAULT '{}'::JSONB,
+    execute_at bigint NOT NULL,
+    status varchar(255) NOT NULL DEFAULT 'pending',
+    error text NOT NULL DEFAULT '',
+    executed_at timestamp WITH TIME ZONE NULL,
+    created_at timestamp WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX scheduled_operations_status_execute_at ON scheduled_operations (status, execute_at);
+CREATE INDEX scheduled_operations_game_id_offer_id ON scheduled_operations (game_id, offer_id);
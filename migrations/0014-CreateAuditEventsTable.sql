@@ -0,0 +1,14 @@
+CREATE TABLE audit_events (
+    id char(36) PRIMARY KEY DEFAULT uuid_generate_v4(),
+    game_id varchar(255) NOT NULL,
+    offer_id varchar(36) NOT NULL DEFAULT '',
+    actor varchar(255) NOT NULL DEFAULT '',
+    action varchar(255) NOT NULL,
+    before JSONB NOT NULL DEFAULT 'null'::JSONB,
+    after JSONB NOT NULL DEFAULT 'null'::JSONB,
+    request_id varchar(255) NOT NULL DEFAULT '',
+    created_at timestamp WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX audit_events_game_id_created_at ON audit_events (game_id, created_at);
+CREATE INDEX audit_events_game_id_offer_id_created_at ON audit_events (game_id, offer_id, created_at);
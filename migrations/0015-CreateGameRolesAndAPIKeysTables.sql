@@ -0,0 +1,21 @@
+CREATE TABLE game_roles (
+    id char(36) PRIMARY KEY DEFAULT uuid_generate_v4(),
+    game_id varchar(255) NOT NULL REFERENCES games(id),
+    principal varchar(255) NOT NULL,
+    role varchar(255) NOT NULL,
+    created_at timestamp WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at timestamp WITH TIME ZONE NULL
+);
+
+CREATE UNIQUE INDEX game_roles_game_id_principal ON game_roles (game_id, principal);
+CREATE INDEX game_roles_principal ON game_roles (principal);
+
+CREATE TABLE api_keys (
+    id char(36) PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name varchar(255) NOT NULL,
+    key_hash char(64) NOT NULL,
+    created_at timestamp WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX api_keys_name ON api_keys (name);
+CREATE UNIQUE INDEX api_keys_key_hash ON api_keys (key_hash);
@@ -0,0 +1,12 @@
+ALTER TABLE games ADD COLUMN client_key varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE games ADD COLUMN previous_client_key varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE games ADD COLUMN previous_client_key_expires_at timestamp WITH TIME ZONE NULL;
+
+CREATE TABLE request_signatures (
+    game_id varchar(255) NOT NULL,
+    signature char(64) NOT NULL,
+    expires_at timestamp WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (game_id, signature)
+);
+
+CREATE INDEX request_signatures_expires_at ON request_signatures (expires_at);
@@ -0,0 +1 @@
+ALTER TABLE offer_players ADD COLUMN receipts JSONB NOT NULL DEFAULT '{}'::JSONB;
@@ -0,0 +1,3 @@
+ALTER TABLE offer_players ADD COLUMN revocations JSONB NOT NULL DEFAULT '{}'::JSONB;
+
+CREATE INDEX offer_players_transactions ON offer_players USING GIN (transactions jsonb_path_ops);
@@ -0,0 +1,18 @@
+CREATE TABLE claims (
+    id char(36) PRIMARY KEY DEFAULT uuid_generate_v4(),
+    game_id varchar(255) NOT NULL REFERENCES games(id),
+    player_id varchar(1000) NOT NULL,
+    offer_id char(36) NOT NULL REFERENCES offers(id),
+    offer_version_id char(36) NOT NULL,
+    offer_version integer NOT NULL DEFAULT 0,
+    product_id varchar(255) NOT NULL DEFAULT '',
+    cost JSONB NOT NULL DEFAULT '{}'::JSONB,
+    contents JSONB NOT NULL DEFAULT '{}'::JSONB,
+    transaction_id varchar(1000) NOT NULL,
+    client_timestamp timestamp WITH TIME ZONE NOT NULL,
+    claimed_at timestamp WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX claims_game_id_player_id_offer_id_transaction_id ON claims (game_id, player_id, offer_id, transaction_id);
+CREATE INDEX claims_game_id_player_id_claimed_at ON claims (game_id, player_id, claimed_at);
+CREATE INDEX claims_game_id_claimed_at ON claims (game_id, claimed_at);
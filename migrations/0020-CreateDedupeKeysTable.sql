@@ -0,0 +1,26 @@
+CREATE TABLE dedupe_keys (
+    game_id varchar(255) NOT NULL,
+    player_id varchar(1000) NOT NULL,
+    offer_id varchar(255) NOT NULL,
+    kind varchar(255) NOT NULL,
+    key varchar(1000) NOT NULL,
+    created_at timestamp WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (game_id, player_id, offer_id, kind, key)
+);
+
+CREATE INDEX dedupe_keys_game_id_kind_key ON dedupe_keys (game_id, kind, key);
+CREATE INDEX dedupe_keys_created_at ON dedupe_keys (created_at);
+CREATE INDEX claims_game_id_transaction_id ON claims (game_id, transaction_id);
+
+INSERT INTO dedupe_keys (game_id, player_id, offer_id, kind, key)
+    SELECT op.game_id, op.player_id, op.offer_id, 'transaction', t.key
+    FROM offer_players op, jsonb_array_elements_text(op.transactions) AS t(key)
+    ON CONFLICT DO NOTHING;
+
+INSERT INTO dedupe_keys (game_id, player_id, offer_id, kind, key)
+    SELECT op.game_id, op.player_id, op.offer_id, 'impression', i.key
+    FROM offer_players op, jsonb_array_elements_text(op.impressions) AS i(key)
+    ON CONFLICT DO NOTHING;
+
+DROP INDEX offer_players_transactions;
+ALTER TABLE offer_players DROP COLUMN transactions, DROP COLUMN impressions;
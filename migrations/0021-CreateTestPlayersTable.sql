@@ -0,0 +1,9 @@
+CREATE TABLE test_players (
+    game_id varchar(255) NOT NULL REFERENCES games(id),
+    player_id varchar(1000) NOT NULL,
+    created_at timestamp WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (game_id, player_id)
+);
+
+ALTER TABLE claims ADD COLUMN test_player boolean NOT NULL DEFAULT false;
+ALTER TABLE offer_players ADD COLUMN test_player boolean NOT NULL DEFAULT false;
@@ -0,0 +1,3 @@
+ALTER TABLE offers ADD COLUMN stock integer NULL CHECK (stock >= 0);
+ALTER TABLE offers ADD COLUMN stock_sold integer NOT NULL DEFAULT 0;
+ALTER TABLE offer_drafts ADD COLUMN stock integer NULL;
@@ -0,0 +1,11 @@
+ALTER TABLE offer_players ADD COLUMN first_view_timestamp timestamp WITH TIME ZONE NULL;
+
+UPDATE offer_players op SET first_view_timestamp = i.first_view_timestamp
+    FROM (
+        SELECT game_id, player_id, offer_id, MIN(created_at) AS first_view_timestamp
+        FROM dedupe_keys
+        WHERE kind = 'impression'
+        GROUP BY game_id, player_id, offer_id
+    ) i
+    WHERE op.game_id = i.game_id AND op.player_id = i.player_id AND op.offer_id = i.offer_id
+    AND i.first_view_timestamp <= op.view_timestamp;
@@ -0,0 +1,2 @@
+ALTER TABLE offers ADD COLUMN prerequisites JSONB NOT NULL DEFAULT '{}'::JSONB;
+ALTER TABLE offer_drafts ADD COLUMN prerequisites JSONB NOT NULL DEFAULT '{}'::JSONB;
@@ -0,0 +1,4 @@
+ALTER TABLE offers ADD COLUMN priority integer NOT NULL DEFAULT 0;
+ALTER TABLE offers ADD COLUMN exclusion_group varchar(255) NOT NULL DEFAULT '';
+ALTER TABLE offer_drafts ADD COLUMN priority integer NOT NULL DEFAULT 0;
+ALTER TABLE offer_drafts ADD COLUMN exclusion_group varchar(255) NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+CREATE TABLE placements (
+    game_id varchar(255) NOT NULL REFERENCES games(id),
+    name varchar(255) NOT NULL,
+    ordering varchar(255) NOT NULL DEFAULT 'priority',
+    max_offers integer NULL CHECK (max_offers >= 0),
+    fallback_offer_id char(36) NULL REFERENCES offers(id),
+    created_at timestamp WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at timestamp WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (game_id, name)
+);
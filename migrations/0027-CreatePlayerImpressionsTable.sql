@@ -0,0 +1,10 @@
+ALTER TABLE placements ADD COLUMN impression_cap JSONB NOT NULL DEFAULT '{}'::JSONB;
+
+CREATE TABLE player_impressions (
+    game_id varchar(255) NOT NULL REFERENCES games(id),
+    player_id varchar(1000) NOT NULL,
+    placement varchar(255) NOT NULL DEFAULT '',
+    window_start timestamp WITH TIME ZONE NOT NULL,
+    counter integer NOT NULL DEFAULT 0,
+    PRIMARY KEY (game_id, player_id, placement)
+);
@@ -0,0 +1 @@
+ALTER TABLE placements ADD COLUMN rotation JSONB NOT NULL DEFAULT '{}'::JSONB;
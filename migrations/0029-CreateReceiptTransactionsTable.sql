@@ -0,0 +1,10 @@
+CREATE TABLE receipt_transactions (
+    game_id varchar(255) NOT NULL REFERENCES games(id),
+    transaction_id varchar(1000) NOT NULL,
+    player_id varchar(1000) NOT NULL,
+    offer_id char(36) NOT NULL REFERENCES offers(id),
+    created_at timestamp WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (game_id, transaction_id)
+);
+
+CREATE INDEX receipt_transactions_game_id_player_id ON receipt_transactions (game_id, player_id);
@@ -0,0 +1,9 @@
+ALTER TABLE claims ADD COLUMN revoked_at timestamp WITH TIME ZONE NULL;
+ALTER TABLE claims ADD COLUMN revocation_reason varchar(1000) NOT NULL DEFAULT '';
+
+UPDATE claims c SET
+    revoked_at = to_timestamp((r.value->>'revokedAt')::bigint),
+    revocation_reason = COALESCE(r.value->>'reason', '')
+    FROM offer_players op, jsonb_each(op.revocations) AS r
+    WHERE c.game_id = op.game_id AND c.player_id = op.player_id AND c.offer_id::varchar = op.offer_id
+    AND c.transaction_id = r.key;
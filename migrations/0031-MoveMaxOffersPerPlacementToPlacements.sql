@@ -0,0 +1,16 @@
+INSERT INTO placements (game_id, name)
+    SELECT DISTINCT o.game_id, o.placement
+    FROM offers o JOIN games g ON g.id = o.game_id
+    WHERE jsonb_typeof(g.metadata->'maxOffersPerPlacement') = 'object'
+    AND g.metadata->'maxOffersPerPlacement' <> '{}'::JSONB
+    AND NOT EXISTS (SELECT 1 FROM placements p WHERE p.game_id = o.game_id)
+    ON CONFLICT DO NOTHING;
+
+INSERT INTO placements (game_id, name, max_offers)
+    SELECT g.id, l.key, (l.value::text)::integer
+    FROM games g, jsonb_each(g.metadata->'maxOffersPerPlacement') AS l
+    WHERE jsonb_typeof(g.metadata->'maxOffersPerPlacement') = 'object'
+    AND jsonb_typeof(l.value) = 'number' AND l.value::text ~ '^[0-9]+$'
+    ON CONFLICT (game_id, name) DO UPDATE SET max_offers = LEAST(placements.max_offers, EXCLUDED.max_offers);
+
+UPDATE games SET metadata = metadata - 'maxOffersPerPlacement' WHERE metadata ? 'maxOffersPerPlacement';
@@ -0,0 +1 @@
+ALTER TABLE scheduled_operations ADD COLUMN created_by varchar(255) NOT NULL DEFAULT '';
@@ -11,6 +11,27 @@
 // migrations/0009-AddCostToOfferInstances.sql
 // migrations/0010-CreateOfferPlayerTable.sql
 // migrations/0011-CreateOfferVersionTable.sql
+// migrations/0012-CreateOfferDraftsTable.sql
+// migrations/0013-CreateScheduledOperationsTable.sql
+// migrations/0014-CreateAuditEventsTable.sql
+// migrations/0015-CreateGameRolesAndAPIKeysTables.sql
+// migrations/0016-AddClientKeysToGames.sql
+// migrations/0017-AddReceiptsToOfferPlayers.sql
+// migrations/0018-AddRevocationsToOfferPlayers.sql
+// migrations/0019-CreateClaimsTable.sql
+// migrations/0020-CreateDedupeKeysTable.sql
+// migrations/0021-CreateTestPlayersTable.sql
+// migrations/0022-AddStockToOffers.sql
+// migrations/0023-AddFirstViewToOfferPlayers.sql
+// migrations/0024-AddPrerequisitesToOffers.sql
+// migrations/0025-AddPriorityAndExclusionGroupToOffers.sql
+// migrations/0026-CreatePlacementsTable.sql
+// migrations/0027-CreatePlayerImpressionsTable.sql
+// migrations/0028-AddRotationToPlacements.sql
+// migrations/0029-CreateReceiptTransactionsTable.sql
+// migrations/0030-AddRevocationToClaims.sql
+// migrations/0031-MoveMaxOffersPerPlacementToPlacements.sql
+// migrations/0032-AddCreatedByToScheduledOperations.sql
 // DO NOT EDIT!
 
 package migrations
@@ -298,6 +319,426 @@ func migrations0011CreateofferversiontableSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations0012CreateofferdraftstableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8d\x93\x4d\x4f\x83\x40\x10\x86\xef\xfc\x8a\xb9\x15\x92\x9e\xd4\x7a\xa8\x27\xda\x6e\x23\x4a\x17\xa5\x90\x5a\x2f\x9b\x0d\xbb\x50\x92\xf2\xe1\x32\x34\x31\xc6\xff\xee\x06\xe8\x87\xb5\x2a\x7b\x9d\xf7\x79\x67\x76\xf2\xce\xd4\x27\x76\x40\x20\xb0\x27\x2e\x81\x22\x8e\xa5\x62\x42\xf1\x18\x2b\x30\x0d\xd0\x2f\x15\x10\x6d\xb8\x32\xaf\x6f\x2d\x78\xf2\x9d\x85\xed\xaf\xe1\x91\xac\x61\x46\xe6\x76\xe8\x06\x50\xd7\xa9\x60\x89\xcc\xa5\xe2\x28\xd9\xee\xc6\xb4\x86\x0d\x97\xf0\x4c\x32\x0d\xef\xb8\x6a\xf8\xab\xd1\xc8\x02\xea\x05\x40\x43\xd7\x05\x9f\xcc\x89\x4f\xe8\x94\x2c\x1b\x61\x65\xa6\xa2\xe3\xda\x11\x4e\xbb\x5e\x82\x1a\xd5\x09\x95\x6b\x93\xcb\xad\xda\x7a\x29\x55\x5a\x08\x78\x58\x7a\x74\x72\x34\xdc\xff\x61\xf0\xf1\x39\x18\x8f\x9b\x62\x2b\x8f\x95\x7c\xab\x65\x1e\xbd\xf7\x26\x50\xa5\x49\x22\x55\x6f\x7d\xb9\xe5\x91\xcc\x64\x8e\x7f\x4d\x9d\x49\xe4\x82\x23\xef\xef\xaa\x0a\x51\x47\x78\xbe\xf7\xb6\x18\x15\x39\xea\x86\x55\xff\x2d\xa4\x5b\xd4\x5b\xee\xad\x8f\x8a\x0a\x7b\x8b\x79\x8d\x9b\x42\xfd\x12\x8f\x03\x35\xe8\x9c\x95\xd4\xe9\x12\x8c\x23\x60\xaa\xe3\x82\x3c\x2b\x61\xe5\x04\xf7\x10\x38\x0b\x02\xaf\x1e\x25\x3f\x61\xea\xad\xf6\x61\xac\x4b\xf1\x3f\xaf\x59\xc3\xba\x33\x8c\x69\x7b\x11\x21\x75\x9e\x43\x02\x0e\x9d\x91\x97\x6f\x87\xc1\xba\x68\xb3\x43\x54\x3d\x7a\x76\x39\x9d\x62\x78\x48\xb3\xf6\xfd\x02\x46\x7b\x07\x12\x69\x03\x00\x00")
+
+func migrations0012CreateofferdraftstableSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0012CreateofferdraftstableSql,
+		"migrations/0012-CreateOfferDraftsTable.sql",
+	)
+}
+
+func migrations0012CreateofferdraftstableSql() (*asset, error) {
+	bytes, err := migrations0012CreateofferdraftstableSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0012-CreateOfferDraftsTable.sql", size: 873, mode: os.FileMode(420), modTime: time.Unix(1526900000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0013CreatescheduledoperationstableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x85\x92\xc1\x6f\x82\x30\x18\xc5\xef\xfc\x15\xdf\x0d\x48\x3c\x6d\x73\x07\x77\x42\xac\x19\x1b\x96\x05\x6b\x9c\xbb\x34\x1d\xad\xd0\x64\x02\x29\xc5\x98\x2c\xfb\xdf\x87\x80\x8c\x19\x88\xbd\xf6\xf7\xde\xeb\xf7\xf5\xb9\x21\x72\x08\x02\xe2\xcc\x7d\x04\x45\x94\x08\x5e\x7e\x09\x4e\xb3\x5c\x28\xa6\x65\x96\x16\x60\x19\x50\x1d\xc9\x21\x4a\x98\xb2\xee\x1f\x6d\x78\x0b\xbd\x95\x13\xee\xe0\x15\xed\x60\x81\x96\xce\xc6\x27\x50\x96\x92\xd3\x58\xa4\x67\x99\xa0\xc7\x07\xcb\x9e\xd4\xba\x98\x1d\x04\xad\xc4\x47\xa6\x6a\xfd\xdd\x74\x6a\x03\x0e\x08\xe0\x8d\xef\x43\x88\x96\x28\x44\xd8\x45\xeb\x1a\x2c\x2c\xc9\x5b\x5d\xb6\xdf\x0b\x45\xfb\xa9\x43\xa2\x9a\xea\xab\x2e\xcf\x1e\xce\x6b\xa0\x9c\xe9\x28\x81\x97\x75\x80\xe7\x7f\xa6\x97\x39\xcc\xef\x1f\x73\x36\xab\x2f\x1b\x5a\x9c\x44\x54\x56\x23\x31\x0d\x9f\x32\x96\xa9\xbe\x72\x2b\x34\xd3\x65\x31\x32\x5f\xe7\x9a\x8b\x94\xcb\x34\x36\x5b\x4f\xa5\x32\x05\x5a\x9c\xf4\x00\x6a\xfe\xcb\xe5\xe7\x60\x2d\xab\xdd\x68\x76\xc8\x61\xeb\x91\x67\x20\xde\x0a\xc1\x47\x80\x51\xef\x19\x91\x12\xec\x26\x7d\x9d\x85\x83\xad\x65\x1b\xf6\x93\x61\xb8\x4d\x0d\x3c\xbc\x40\xef\x83\x35\xa0\xcd\x9c\xb4\xb7\x8e\x00\x8f\x14\xa6\x41\x27\xbd\xd5\x55\x11\xb7\x13\xda\xaa\xd0\xee\xeb\x47\x03\x5a\x72\xd2\xb5\xa4\xf2\xff\x05\x87\x43\x61\x18\xc9\x02\x00\x00")
+
+func migrations0013CreatescheduledoperationstableSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0013CreatescheduledoperationstableSql,
+		"migrations/0013-CreateScheduledOperationsTable.sql",
+	)
+}
+
+func migrations0013CreatescheduledoperationstableSql() (*asset, error) {
+	bytes, err := migrations0013CreatescheduledoperationstableSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0013-CreateScheduledOperationsTable.sql", size: 713, mode: os.FileMode(420), modTime: time.Unix(1527000000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0014CreateauditeventstableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x95\x91\x4b\x4f\xc3\x30\x10\x84\xef\xf9\x15\x7b\x4b\x2c\xf5\x04\x94\x43\x39\xa5\xad\x11\x81\xd4\x46\xc1\x55\x29\x17\xcb\x24\x9b\x12\xa9\x49\xc0\xb5\xf3\xfb\x09\x79\xa0\xd0\x86\xd7\x5e\xf7\x9b\x99\xd5\xce\x22\xa2\xbe\xa0\x20\xfc\x79\x48\x41\xd9\x24\x33\x12\x2b\x2c\xcc\x01\x3c\x07\xea\xc9\x12\x88\x5f\x94\xf6\xce\x2f\x09\xdc\x47\xc1\xca\x8f\xb6\x70\x47\xb7\xb0\xa4\xd7\xfe\x3a\x14\x60\x6d\x96\xc8\x1d\x16\xa8\x95\x41\x59\x5d\x78\x64\xd2\xe8\x76\x2a\x47\x59\x8b\x2b\xa5\x1b\xfd\xd9\x74\x4a\x80\x71\x01\x6c\x1d\x86\x2d\x52\xa6\x29\xea\x21\xf3\x91\xd1\x23\x9f\x01\xae\xdb\xd2\x2a\x36\xa5\x1e\xb7\x1b\x63\xb3\xb2\xf8\x29\xfb\x19\xd3\x52\x23\xdc\x3e\x70\x36\x1f\xf1\x29\xec\x7e\xef\xce\x66\xcd\xba\xb3\x4c\x0d\xea\x7f\xf0\x1a\xdf\x2c\x1e\xcc\xb7\x2f\x38\xb9\x39\xd6\x58\x7f\x30\x91\xca\x80\xc9\xf2\x5a\xaa\xf2\x57\xd8\x04\xe2\x06\x44\xb0\xa2\xf0\xc4\x19\x3d\x15\x33\xbe\xf1\x88\x43\xae\x1c\x67\xd1\xf6\x18\xb0\x25\x7d\xfc\xd2\xa3\xec\x9a\x90\x83\x00\xce\x8e\xaa\xee\x98\xc9\xe0\x8a\xda\xf4\x77\xcf\xbe\xc2\xbf\x99\xf7\xf4\x51\xcc\x3b\x9e\x31\x7e\x20\x83\x02\x00\x00")
+
+func migrations0014CreateauditeventstableSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0014CreateauditeventstableSql,
+		"migrations/0014-CreateAuditEventsTable.sql",
+	)
+}
+
+func migrations0014CreateauditeventstableSql() (*asset, error) {
+	bytes, err := migrations0014CreateauditeventstableSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0014-CreateAuditEventsTable.sql", size: 643, mode: os.FileMode(420), modTime: time.Unix(1527100000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0015CreategamerolesandapikeystablesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xad\x91\xc1\x6f\x82\x30\x14\xc6\xef\xfc\x15\xef\x08\x89\xa7\x4d\xbd\xec\xc4\xf0\x99\x91\x61\xd9\x58\x89\x73\x97\xa6\x81\x46\x9a\x29\x6b\x0a\x98\xec\xbf\x5f\x05\x3a\x34\x53\x77\xd0\x1e\xdb\xf7\xfb\xfa\x7d\xdf\x0b\x12\xf4\x29\x02\xf5\x1f\x23\x84\x35\xdf\x0a\xa6\xbf\x36\xa2\x02\xd7\x01\x73\x64\x0e\x59\xc1\xb5\x7b\x3f\xf5\xe0\x25\x09\x17\x7e\xb2\x82\x67\x5c\xc1\x0c\xe7\x7e\x1a\x51\x68\x1a\x99\xb3\xb5\x28\x85\xe6\xb5\x60\xbb\xb1\xeb\x8d\x5a\xae\x15\x32\xf0\x8e\xeb\x96\xbf\x9b\x4c\x3c\x20\x31\x05\x92\x46\x11\x24\x38\xc7\x04\x49\x80\x6f\xed\x60\xe5\xca\xbc\xe7\x94\x96\x65\x26\x15\xdf\x9c\x26\xbb\xa1\xbd\xc1\x4b\xef\x99\x16\xc6\x4d\xce\x78\x0d\xb5\x34\xf2\x35\xdf\x2a\x58\x86\xf4\x09\x68\xb8\x40\xf8\x88\x09\x0e\x5e\x6c\x12\x12\x2f\xad\xf9\x46\xe5\xff\xf3\x86\x75\xbc\x07\xc7\x09\xba\xfe\x52\x12\xbe\xa6\x08\x21\x99\xe1\xfb\x41\x8d\xac\x2f\x82\x0d\xc1\x62\x72\x54\x73\x3f\x30\x1a\xa2\x1b\xd5\x5e\xf4\x8f\xda\x59\x95\x43\xd8\xd2\xdd\x4a\xb9\x92\xec\x53\x7c\x5f\xbb\xd0\xd2\x7c\x76\xa9\x73\xf3\x05\x2b\x78\x55\x74\xea\xd3\xf1\xed\x76\x72\xb6\x65\x9b\x8c\xb5\xde\x4c\x21\x43\xd4\xfd\xcd\x50\xe3\x69\xea\xd7\xf1\x11\x69\x6f\x0d\xfd\x03\xb1\x99\x55\x7b\x1a\x03\x00\x00")
+
+func migrations0015CreategamerolesandapikeystablesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0015CreategamerolesandapikeystablesSql,
+		"migrations/0015-CreateGameRolesAndAPIKeysTables.sql",
+	)
+}
+
+func migrations0015CreategamerolesandapikeystablesSql() (*asset, error) {
+	bytes, err := migrations0015CreategamerolesandapikeystablesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0015-CreateGameRolesAndAPIKeysTables.sql", size: 794, mode: os.FileMode(420), modTime: time.Unix(1527200000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0016AddclientkeystogamesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xb5\x90\x41\x4f\xc2\x30\x18\x86\xef\xfd\x15\xef\x8d\x2d\xe1\x64\xc4\x0b\xa7\xca\x3e\xe3\x62\xd7\x91\xa5\x8b\xe0\xa5\x69\xb0\xc1\x46\x81\xd1\x75\x04\xff\xbd\x15\x09\xd3\x48\x0c\x17\x7b\x7e\xbe\xe7\x7d\xdf\x72\xa1\xa8\x82\xe2\xb7\x82\xb0\x34\x2b\xdb\x82\x67\x19\x26\xa5\xa8\x0b\x89\xc5\x9b\xb3\xeb\xa0\x5f\xed\x3b\x76\xc6\x2f\x5e\x8c\x4f\x6e\xae\x53\xc8\x52\x41\xd6\x42\x20\xa3\x3b\x5e\x0b\x85\xc1\x60\xcc\xf8\x5f\xa2\xc6\xdb\x9d\xdb\x74\xad\xfe\x4f\xa3\xb6\xfb\xc6\x79\xdb\x6a\x13\x10\x5c\xe4\x83\x59\x35\x78\xcc\xd5\x3d\x54\x5e\x10\x9e\x4a\x49\x87\x94\x31\x63\x93\x8a\xb8\xa2\xa3\xdc\xdb\x6d\x17\x69\xdd\xba\xe5\xda\x84\x2e\x2a\x90\x30\xc4\xf7\x99\xaa\xdd\xf3\xa9\xea\xd5\x68\xd4\x77\x1d\x1e\x90\xd3\x0d\x7e\x8d\xf9\x02\x2e\x2a\xf5\xe3\x62\x5a\xe5\x05\xaf\xe6\x78\xa0\x39\x92\x63\x85\x61\x1f\x94\xb2\xb4\x1f\x90\xcb\x8c\x66\x67\x06\x7c\xff\x8b\x52\x9e\x5d\xd8\x13\xd1\xf7\x01\x18\x4f\x49\x82\x07\x02\x00\x00")
+
+func migrations0016AddclientkeystogamesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0016AddclientkeystogamesSql,
+		"migrations/0016-AddClientKeysToGames.sql",
+	)
+}
+
+func migrations0016AddclientkeystogamesSql() (*asset, error) {
+	bytes, err := migrations0016AddclientkeystogamesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0016-AddClientKeysToGames.sql", size: 519, mode: os.FileMode(420), modTime: time.Unix(1527300000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0017AddreceiptstoofferplayersSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\xc8\x4f\x4b\x4b\x2d\x8a\x2f\xc8\x49\xac\x4c\x2d\x2a\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x28\x4a\x4d\x4e\xcd\x2c\x28\x29\x56\xf0\x0a\xf6\xf7\x73\x52\xf0\xf3\x0f\x51\xf0\x0b\xf5\xf1\x51\x70\x71\x75\x73\x0c\xf5\x09\x51\x50\xaf\xae\x55\xb7\xb2\x02\x4b\x5a\x73\x01\x00\x63\x0c\xd2\xde\x52\x00\x00\x00")
+
+func migrations0017AddreceiptstoofferplayersSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0017AddreceiptstoofferplayersSql,
+		"migrations/0017-AddReceiptsToOfferPlayers.sql",
+	)
+}
+
+func migrations0017AddreceiptstoofferplayersSql() (*asset, error) {
+	bytes, err := migrations0017AddreceiptstoofferplayersSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0017-AddReceiptsToOfferPlayers.sql", size: 82, mode: os.FileMode(420), modTime: time.Unix(1527400000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0018AddrevocationstoofferplayersSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x5d\x8d\x41\x0b\x82\x30\x1c\x47\xef\x7e\x8a\xdf\xcd\xfa\x0a\x7a\x9a\x6e\x89\xb1\xfe\x03\xdd\xa0\xdb\x58\xa2\x58\xc4\x26\x9b\x04\x11\x7d\xf7\xa0\x4e\x76\x7e\x8f\xf7\x98\xd4\xa2\x83\x66\x95\x14\x08\xd3\x34\x46\xbb\xdc\xdd\x73\x8c\x09\x8c\x73\xd4\x4a\x9a\x13\x21\x8e\x8f\x30\xb8\xf5\x1a\x7c\xc2\xb1\x57\x54\x81\x94\x06\x19\x29\xc1\xc5\x81\x19\xa9\x91\xbf\xde\x79\x51\x7c\x61\x99\x65\x75\x27\x98\x16\x68\x89\x8b\xf3\x36\x6b\xd7\xe8\x7c\x72\xc3\x2f\xa6\xe8\x6f\x6a\xfa\x96\x1a\x34\x2d\x61\xb7\x11\x6f\x29\xf8\x8b\x5d\xdc\x3a\xdb\xb0\xa4\x7d\x99\x7d\x00\xad\x6b\x62\xdf\xb8\x00\x00\x00")
+
+func migrations0018AddrevocationstoofferplayersSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0018AddrevocationstoofferplayersSql,
+		"migrations/0018-AddRevocationsToOfferPlayers.sql",
+	)
+}
+
+func migrations0018AddrevocationstoofferplayersSql() (*asset, error) {
+	bytes, err := migrations0018AddrevocationstoofferplayersSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0018-AddRevocationsToOfferPlayers.sql", size: 184, mode: os.FileMode(420), modTime: time.Unix(1527500000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0019CreateclaimstableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8d\x92\x5f\x6f\x82\x30\x14\xc5\xdf\xf9\x14\xf7\x4d\x48\x78\x60\x7f\xdc\x83\x7b\x42\xad\x19\x1b\x96\x0d\x21\xce\xbd\x34\x0d\x54\xd7\x44\xc1\x94\x6a\xb2\x2c\xfb\xee\x6b\xa0\x80\x3a\x36\xc6\x13\x81\xf3\x3b\xe7\xde\xf6\x4c\x42\xe4\x46\x08\x22\x77\xec\x23\x48\xb6\x94\xef\x0a\x30\x0d\x50\x0f\x4f\x21\x79\xa7\xc2\xbc\xb9\xb3\xe0\x39\xf4\xe6\x6e\xb8\x82\x27\xb4\x82\x29\x9a\xb9\xb1\x1f\xc1\xe1\xc0\x53\xb2\x61\x19\x13\x54\x32\x72\xbc\x35\x2d\xbb\xe4\x36\x74\xc7\x88\x82\x8f\x54\x94\xfc\xf5\x70\x68\x01\x0e\x22\xc0\xb1\xef\x43\x88\x66\x28\x44\x78\x82\x16\xa5\xb0\x30\x79\xaa\xb9\xfd\x96\x7e\x30\x71\x4a\x5e\x39\x8e\xd3\xa2\x95\x2a\x5f\xaf\x2b\x51\x33\x5b\x97\x75\xa9\x3a\xf1\xae\xa8\xa3\xfa\xc6\xf3\xac\x93\xee\xd0\x01\xcf\x24\xdb\x30\xd1\x26\xd4\xab\x3b\x7a\x62\x91\xa7\x87\x44\xfe\xbe\x6c\xad\x1f\x0c\x2a\x20\xc9\x0b\x09\x8f\x8b\x00\x8f\x3b\x34\x9f\x5f\x83\xd1\xa8\xfc\x59\x8b\x55\x7c\x26\x8b\x7f\x03\x52\xd0\xac\xa0\x89\xd4\x2b\xfe\x75\x8a\xc9\x96\x2b\x6b\x22\xb9\xba\x01\x49\x77\x7b\x68\xdf\x96\x5e\xf4\x00\x91\x37\x47\xf0\x16\x60\xf4\x83\x53\x05\x61\x29\xa1\xb2\x9f\x68\x06\xc5\xc1\xd2\xb4\x0c\xeb\xde\x30\x26\x55\xd9\x62\xec\xbd\xc4\x08\x3c\x3c\x45\xaf\xba\x73\x44\xb7\x86\x34\x2d\x20\xf5\x4d\x93\x8b\xbd\x02\xdc\xf4\x54\x43\x76\xdb\x1d\xbb\x29\x88\x7d\x71\x1e\x2a\x5f\xc7\xf7\xe4\x9e\xec\xd8\x13\xd5\x2a\x7b\xcc\x7b\x2c\xcf\x7c\xbe\x01\xe1\x4c\x8f\x7e\x91\x03\x00\x00")
+
+func migrations0019CreateclaimstableSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0019CreateclaimstableSql,
+		"migrations/0019-CreateClaimsTable.sql",
+	)
+}
+
+func migrations0019CreateclaimstableSql() (*asset, error) {
+	bytes, err := migrations0019CreateclaimstableSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0019-CreateClaimsTable.sql", size: 913, mode: os.FileMode(420), modTime: time.Unix(1527600000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0020CreatededupekeystableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xc5\x94\x4f\x6f\x82\x40\x10\xc5\xef\x7c\x8a\xb9\x09\x09\x31\xb6\x89\x27\x4f\x14\xd6\x4a\x0a\xbb\x06\xd7\x58\x7b\x21\x5b\x5d\x5b\xaa\x20\x61\xb7\x4d\xfd\xf6\x5d\xfe\x04\x16\x53\x6d\x93\x1e\xca\x09\xd8\x37\xbf\x79\x6f\x98\xe0\x46\xc8\xa1\x08\xa8\x73\x17\x20\xd8\xf2\xed\x7b\xce\xe3\x3d\x3f\x09\x30\x0d\x50\xd7\x0b\x4b\x79\x9c\x6c\xe1\x83\x15\x9b\x57\x56\x98\xb7\xe3\xb1\x05\x98\x50\xc0\xcb\x20\xb0\x2b\x49\x7e\x60\x27\x5e\xe8\xa2\x9b\xd1\x68\x74\xae\x3a\xee\x76\x7d\xd1\x37\xa4\x7d\x92\x5d\x3f\xe7\xa7\xab\x3d\x36\x05\x67\x92\x6f\x63\x26\x41\x26\x29\x17\x92\xa5\x39\xac\x7c\x3a\x03\xea\x87\x08\x9e\x08\x46\x6d\x05\x78\x68\xea\x2c\x03\xf5\x40\x56\xa6\x55\xd7\xcf\x23\x3f\x74\xa2\x35\x3c\xa0\x35\x98\x4d\x72\xbb\xcb\x67\xb7\x21\xec\xca\xaa\x5d\x1a\xb2\x0c\x6b\x62\x18\x6e\x3d\x45\x1f\x7b\xe8\x51\x9f\x62\xdc\x50\xe2\x52\x5f\xbe\x01\x82\xfb\x53\x6e\xdb\x74\xc4\xc9\x65\x9c\x96\xf0\x1c\xd4\x1d\x9d\x03\x36\x07\x96\xa4\x9d\x15\x59\xb0\x4c\xb0\x8d\x4c\x8e\x59\xf9\x3d\x14\xa7\x16\x68\x5e\xfa\x92\x32\xa0\x8f\x17\x28\xa2\x0a\x48\xc9\x05\xff\x3f\x8d\xa9\x1c\xf0\x02\x05\xc8\xa5\x70\xcc\x87\x6d\x99\xba\xd7\x2b\xf3\x61\x57\x3c\xd0\x6c\x0c\x94\xa9\xa1\xe2\x54\x98\x69\x44\xc2\xa6\x49\x5d\x2b\x54\xa1\x0d\x6f\xe2\x98\x3d\xc7\xac\x28\xd8\x29\xe6\x07\x9e\xf2\x4c\x8a\x58\xf2\x4f\x69\x2a\xac\xc6\x12\x16\x38\x0b\x90\x66\x6b\x4b\x8d\xc0\x25\x78\x1a\xf8\xca\x9b\x47\xca\x15\x99\xf9\xf8\xfe\xbf\x52\x27\x69\x5e\x70\x21\xea\xd0\xc9\x9f\x42\x77\xa8\x3a\x73\xf2\x8b\xcc\x5e\x44\xe6\xcd\xe2\xf4\xba\xe9\x7b\x23\x26\x86\x13\x50\x14\x35\xbf\x8d\xbe\xab\x0a\xe0\x92\x60\x19\x62\x7d\x91\x84\xdd\x3b\xd1\xac\x4d\x8c\x2f\x97\xea\xfd\x09\x85\x04\x00\x00")
+
+func migrations0020CreatededupekeystableSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0020CreatededupekeystableSql,
+		"migrations/0020-CreateDedupeKeysTable.sql",
+	)
+}
+
+func migrations0020CreatededupekeystableSql() (*asset, error) {
+	bytes, err := migrations0020CreatededupekeystableSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0020-CreateDedupeKeysTable.sql", size: 1157, mode: os.FileMode(420), modTime: time.Unix(1527700000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0021CreatetestplayerstableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x9d\x90\x41\x8b\xc2\x30\x10\x85\xef\xfd\x15\x73\x4c\xc1\x43\x15\x3c\x79\x8a\xed\x88\xc5\x34\x95\x6c\x8a\xe8\xa5\x8c\x6d\xaa\x85\xd6\x4a\x13\x04\xff\xfd\x16\xed\x52\x97\xbd\xed\xdc\x06\xde\x7b\xdf\xbc\x09\x15\x72\x8d\xa0\xf9\x5a\x20\x38\x63\x5d\x7e\x6f\xe8\x69\x7a\x0b\xcc\x83\x61\x2e\xd4\x9a\xbc\x2e\xe1\x41\x7d\x71\xa5\x9e\x2d\x96\x4b\x1f\x64\xaa\x41\x66\x42\x80\xc2\x0d\x2a\x94\x21\x7e\xbd\x84\x96\xd5\xa5\x3f\x7b\xf9\xde\x29\x9f\xce\x79\x10\x04\x93\xf5\xad\x2a\x7a\x43\xce\x94\x39\x39\x70\xf5\xe0\x77\xd4\xde\xe1\x10\xeb\x2d\xe8\x38\x41\x38\xa5\x12\x27\x58\x84\x1b\x9e\x89\x61\x49\x0f\x6c\xa4\xec\x55\x9c\x70\x75\x84\x1d\x1e\x81\x8d\xa7\xce\x26\xb6\xef\xf9\x2b\xcf\xe3\x42\xa3\x1a\x1b\x16\x0d\xd5\xad\x05\x1e\x45\x10\xa6\x22\x4b\xe4\x67\x67\x38\x77\x5d\x63\xe8\xf6\x17\x59\x51\x63\xcd\xea\x57\x52\x57\x55\x03\xe3\xe7\x59\xff\x0c\xfc\x06\x95\x5c\x39\x89\x7e\x01\x00\x00")
+
+func migrations0021CreatetestplayerstableSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0021CreatetestplayerstableSql,
+		"migrations/0021-CreateTestPlayersTable.sql",
+	)
+}
+
+func migrations0021CreatetestplayerstableSql() (*asset, error) {
+	bytes, err := migrations0021CreatetestplayerstableSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0021-CreateTestPlayersTable.sql", size: 382, mode: os.FileMode(420), modTime: time.Unix(1527800000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0022AddstocktooffersSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\xc8\x4f\x4b\x4b\x2d\x2a\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x28\x2e\xc9\x4f\xce\x56\xc8\xcc\x2b\x49\x4d\x4f\x2d\x52\xf0\x0b\xf5\xf1\x51\x70\xf6\x70\x75\xf6\x56\xd0\x80\xc8\xd8\xd9\x2a\x18\x68\x5a\x73\x39\x12\x36\x24\xbe\x38\x3f\x27\x05\x61\x92\x7f\x08\xc4\x34\x17\x57\x37\xc7\x50\x9f\x10\x05\x03\x2c\x86\xc4\xa7\x14\x25\xa6\x95\x10\x70\x8f\x35\x17\x00\xc7\x47\x88\x9b\xc2\x00\x00\x00")
+
+func migrations0022AddstocktooffersSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0022AddstocktooffersSql,
+		"migrations/0022-AddStockToOffers.sql",
+	)
+}
+
+func migrations0022AddstocktooffersSql() (*asset, error) {
+	bytes, err := migrations0022AddstocktooffersSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0022-AddStockToOffers.sql", size: 194, mode: os.FileMode(420), modTime: time.Unix(1527900000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0023AddfirstviewtoofferplayersSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x7d\x90\x51\x6f\x82\x30\x14\x85\xdf\xf9\x15\xe7\x4d\x4d\x16\xff\x80\xfa\x50\xa5\x9b\x24\x05\x0c\x94\x98\xed\xa5\x21\xe3\xb2\x34\x8a\x34\x94\x6d\xf1\xdf\x4f\xaa\x42\x5c\xd8\xee\xd3\x69\xcf\xbd\xdf\xb9\x2d\x13\x92\x27\x90\x6c\x2d\x38\xea\xb2\xa4\x46\x99\x63\x7e\xa6\xc6\x82\xf9\x3e\x36\xb1\xc8\xc2\x08\xa5\x6e\x6c\xab\xbe\x34\x7d\xab\x56\x57\x64\xdb\xbc\x32\x18\xd4\x3e\x90\x5b\xc8\x20\xe4\x78\x8b\x23\x8e\x28\x13\x62\xe1\x79\xd9\xce\x67\xf2\x37\xb4\x36\x48\xb9\x1c\x07\xae\xa0\xe7\x63\x86\x87\x4b\x3d\x27\x71\x88\xa9\x93\x5d\xa5\x5c\xf0\x8d\xc4\x47\x5e\x91\xd2\xc5\x13\xae\x7c\x27\xaf\x81\x9d\x0a\x83\x68\xfa\xde\x50\xde\x52\xa1\xf2\x76\x06\x96\xe2\x4f\x7e\x9f\x51\x50\xf1\x69\x48\x1d\xe8\x6c\x7b\x63\xbf\xe5\x09\xc7\x41\x9f\x8a\xcb\x92\x13\x5d\x99\x86\xac\xd5\xf5\x69\xd2\x77\xbc\x24\x71\xb6\xc3\xfa\xf5\xdf\x8d\x5c\xf7\x0c\xda\x1b\x98\xb5\x99\xdf\x26\xdc\xf3\xef\x9a\x45\x7e\x67\xf5\x0c\x67\x0e\xa7\x9b\x7d\xe7\x3a\xf7\x21\xa4\x6b\x18\xff\x4c\x2c\x57\xdd\xe8\xe3\xe5\xc2\xfb\x01\x0b\x2a\xa5\xda\x07\x02\x00\x00")
+
+func migrations0023AddfirstviewtoofferplayersSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0023AddfirstviewtoofferplayersSql,
+		"migrations/0023-AddFirstViewToOfferPlayers.sql",
+	)
+}
+
+func migrations0023AddfirstviewtoofferplayersSql() (*asset, error) {
+	bytes, err := migrations0023AddfirstviewtoofferplayersSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0023-AddFirstViewToOfferPlayers.sql", size: 519, mode: os.FileMode(420), modTime: time.Unix(1528000000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0024AddprerequisitestooffersSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\xc8\x4f\x4b\x4b\x2d\x2a\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x28\x28\x4a\x2d\x4a\x2d\x2c\xcd\x2c\xce\x2c\x49\x2d\x56\xf0\x0a\xf6\xf7\x73\x52\xf0\xf3\x0f\x51\xf0\x0b\xf5\xf1\x51\x70\x71\x75\x73\x0c\xf5\x09\x51\x50\xaf\xae\x55\xb7\xb2\x02\x4b\x5a\x73\x39\xa2\x9b\x17\x9f\x52\x94\x98\x56\x42\xa1\xa9\x00\x2f\x33\xfb\xf4\xa6\x00\x00\x00")
+
+func migrations0024AddprerequisitestooffersSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0024AddprerequisitestooffersSql,
+		"migrations/0024-AddPrerequisitesToOffers.sql",
+	)
+}
+
+func migrations0024AddprerequisitestooffersSql() (*asset, error) {
+	bytes, err := migrations0024AddprerequisitestooffersSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0024-AddPrerequisitesToOffers.sql", size: 166, mode: os.FileMode(420), modTime: time.Unix(1528100000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0025AddpriorityandexclusiongrouptooffersSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\xc8\x4f\x4b\x4b\x2d\x2a\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x28\x28\xca\xcc\x2f\xca\x2c\xa9\x54\xc8\xcc\x2b\x49\x4d\x4f\x2d\x52\xf0\xf3\x0f\x51\xf0\x0b\xf5\xf1\x51\x70\x71\x75\x73\x0c\xf5\x09\x51\x30\xb0\xe6\x72\xc4\x6b\x44\x6a\x45\x72\x4e\x69\x71\x66\x7e\x5e\x7c\x7a\x51\x7e\x69\x81\x42\x59\x62\x51\x72\x46\x62\x91\x86\x91\xa9\xa9\x26\xa6\x71\xea\xea\x58\xcc\x8b\x4f\x29\x4a\x4c\x2b\xa1\x82\xc3\xb0\x18\x44\xba\xf3\x00\x50\xed\x69\xf2\x32\x01\x00\x00")
+
+func migrations0025AddpriorityandexclusiongrouptooffersSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0025AddpriorityandexclusiongrouptooffersSql,
+		"migrations/0025-AddPriorityAndExclusionGroupToOffers.sql",
+	)
+}
+
+func migrations0025AddpriorityandexclusiongrouptooffersSql() (*asset, error) {
+	bytes, err := migrations0025AddpriorityandexclusiongrouptooffersSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0025-AddPriorityAndExclusionGroupToOffers.sql", size: 306, mode: os.FileMode(420), modTime: time.Unix(1528200000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0026CreateplacementstableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xa5\x91\x4d\x4f\xc3\x30\x0c\x86\xef\xfd\x15\xbe\x2d\x95\x76\x40\xa0\x71\x41\x20\x95\xe2\x69\xd5\xba\x14\x85\x4c\xd3\xb8\x54\xa6\x49\x4b\x44\xbf\x94\x06\x04\xff\x9e\xd2\x54\x02\x69\x70\xc2\x37\xeb\xf5\x63\xfb\xb5\x63\x81\x91\x44\x90\xd1\x6d\x8a\xd0\xd7\x54\xe8\x46\xb7\x6e\x00\x16\xc0\x18\x15\x35\x3a\x37\x0a\xde\xc8\x16\xcf\x64\xd9\xf9\x6a\x15\x02\xcf\x24\xf0\x7d\x9a\x82\xc0\x35\x0a\xe4\x31\x3e\x4c\x85\x03\x33\x2a\x5c\x4e\x5c\x3b\xa6\xbf\x43\x5e\xef\xac\xd2\xd6\xb4\xd5\x1f\x8d\xef\x70\x1d\xed\x53\x09\x8b\xde\x9a\xce\x1a\xf7\xb1\xf0\x58\x43\xef\x79\x57\x96\xda\x0e\x60\x5a\xa7\x2b\x6d\x7d\x7d\xbc\xc1\x78\x0b\xec\x87\x7c\x73\x0d\x67\xf3\x2e\x25\xd5\xf5\x13\x15\x2f\x5e\xfa\x72\x33\x4d\xbc\xb8\x0c\x4f\x5c\x78\xf8\xdb\x46\x61\x35\x39\xad\x72\x72\xe0\xcc\x68\xd0\x51\xd3\xc3\x21\x91\x1b\x90\xc9\x0e\xe1\x31\xe3\x78\xba\x34\xcf\x0e\x6c\xe6\x5f\x7b\xf5\x2f\xfe\x5e\x24\xbb\x48\x1c\x61\x8b\x47\x60\xf3\x2f\x96\xd3\x71\xc3\x20\xbc\x0a\x3e\x01\x1c\xe5\xde\x3f\xbc\x01\x00\x00")
+
+func migrations0026CreateplacementstableSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0026CreateplacementstableSql,
+		"migrations/0026-CreatePlacementsTable.sql",
+	)
+}
+
+func migrations0026CreateplacementstableSql() (*asset, error) {
+	bytes, err := migrations0026CreateplacementstableSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0026-CreatePlacementsTable.sql", size: 444, mode: os.FileMode(420), modTime: time.Unix(1528300000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0027CreateplayerimpressionstableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x75\x8f\x4f\x6b\xc3\x30\x0c\xc5\xef\xf9\x14\xba\x35\x81\x1c\xb2\x41\x2f\xed\xc9\x4d\x54\x96\xcd\x71\x86\xeb\x50\xba\x4b\x30\x89\xe9\x0c\xcb\x1f\x6c\x6f\x65\x8c\x7d\xf7\x85\x64\xab\x61\x63\x3a\x48\x07\xbd\xf7\x93\x1e\xa1\x02\x39\x08\xb2\xa3\x08\xe3\x8b\x6c\x54\xa7\x7a\x67\x81\x64\x19\xa4\x25\xad\x0a\x06\xba\x1b\x8d\xb2\x56\x0f\x7d\xdd\xc8\x11\xee\x0f\x25\xdb\x01\x2b\x05\xb0\x8a\x52\xc8\x70\x4f\x2a\x2a\x60\xf5\xf1\xb9\xda\x6c\xe6\xe5\x36\x08\x52\x8e\x44\xa0\xc7\xbe\x2b\x53\x7b\x8e\x85\x30\x80\xa9\xce\xb2\x53\xb5\x6e\xe1\x4d\x9a\xe6\x59\x9a\xf0\x76\xbd\x8e\x3c\x99\xe3\x1e\x39\xb2\x14\x0f\xb3\xd0\x86\xba\x8d\xe2\xd9\xf7\x03\xf4\xce\x9b\x24\x49\xbc\xf5\xaa\x5a\xd2\xfc\xc3\xbf\x7e\xbe\x5a\xf4\x17\xdd\xb7\xc3\xa5\xb6\x4e\x1a\x07\x4e\x4f\x17\x9d\xec\x46\x38\xe6\xe2\x0e\x44\x5e\x20\x3c\x95\x0c\x7f\xdd\x68\x86\xd7\xde\x29\x03\x7a\xea\xe7\x69\xfe\x81\x27\x8b\xee\x91\xe7\x05\xe1\x27\x78\xc0\x13\x84\xdf\xb1\x63\x9f\x23\xf6\xcf\x46\x41\xb4\x0d\xbe\x00\xbf\x2d\xd2\x04\x95\x01\x00\x00")
+
+func migrations0027CreateplayerimpressionstableSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0027CreateplayerimpressionstableSql,
+		"migrations/0027-CreatePlayerImpressionsTable.sql",
+	)
+}
+
+func migrations0027CreateplayerimpressionstableSql() (*asset, error) {
+	bytes, err := migrations0027CreateplayerimpressionstableSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0027-CreatePlayerImpressionsTable.sql", size: 405, mode: os.FileMode(420), modTime: time.Unix(1528400000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0028AddrotationtoplacementsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\xc8\x49\x4c\x4e\xcd\x4d\xcd\x2b\x29\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x28\xca\x2f\x49\x2c\xc9\xcc\xcf\x53\xf0\x0a\xf6\xf7\x73\x52\xf0\xf3\x0f\x51\xf0\x0b\xf5\xf1\x51\x70\x71\x75\x73\x0c\xf5\x09\x51\x50\xaf\xae\x55\xb7\xb2\x02\x4b\x5a\x73\x01\x00\x80\x34\x54\x39\x4f\x00\x00\x00")
+
+func migrations0028AddrotationtoplacementsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0028AddrotationtoplacementsSql,
+		"migrations/0028-AddRotationToPlacements.sql",
+	)
+}
+
+func migrations0028AddrotationtoplacementsSql() (*asset, error) {
+	bytes, err := migrations0028AddrotationtoplacementsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0028-AddRotationToPlacements.sql", size: 79, mode: os.FileMode(420), modTime: time.Unix(1528500000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0029CreatereceipttransactionstableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x85\x90\xc1\x8a\xc2\x30\x10\x86\xef\x7d\x8a\x39\xa6\xe0\xa1\xae\xb8\x97\x3d\xd5\x3a\xb2\x65\x6b\x2a\x31\xa2\xf5\x12\x86\x36\xba\x81\x55\x4b\x1a\x16\x7c\x7b\x4b\x5b\x6c\x11\xc5\xdc\x02\xdf\x37\xf3\xcf\x1f\x09\x0c\x25\x82\x0c\x67\x09\x82\xd5\xb9\x36\xa5\x53\xce\xd2\xb9\xa2\xdc\x99\xcb\xb9\x02\xe6\x41\xfd\x8e\x74\xd2\xca\x14\xf0\x4f\x36\xff\x25\xcb\x3e\xa6\x53\x1f\x78\x2a\x81\x6f\x92\x04\x04\x2e\x50\x20\x8f\x70\xdd\x80\x15\x33\x85\x3f\x6a\xbc\xc1\xa8\xa1\x3e\x0e\x82\xa0\xf7\x5b\xb4\xfc\xa3\xab\xb6\xef\xa8\xcb\xe1\xd0\x42\x0d\x31\xf9\x7c\x9e\xa2\xa1\x06\x31\x72\xab\xc9\xe9\x42\x91\x03\x67\xea\x80\x8e\x4e\x25\x6c\x63\xf9\x0d\x32\x5e\x22\xec\x53\x8e\xfd\x9c\x39\x2e\xc2\x4d\x52\x7f\xd2\x2d\xeb\xfc\x95\x88\x97\xa1\xc8\xe0\x07\x33\x60\x5d\x17\xa3\x87\xe3\x7c\xcf\xff\xf2\xbc\xa8\x2d\x34\xe6\x73\xdc\x3d\x2d\x54\x75\xba\xea\xef\x4d\xf9\x8b\xea\xef\x9b\xee\x6c\xbd\xe2\x06\x9a\x31\xa9\x05\xb3\x01\x00\x00")
+
+func migrations0029CreatereceipttransactionstableSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0029CreatereceipttransactionstableSql,
+		"migrations/0029-CreateReceiptTransactionsTable.sql",
+	)
+}
+
+func migrations0029CreatereceipttransactionstableSql() (*asset, error) {
+	bytes, err := migrations0029CreatereceipttransactionstableSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0029-CreateReceiptTransactionsTable.sql", size: 435, mode: os.FileMode(420), modTime: time.Unix(1528600000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0030AddrevocationtoclaimsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x85\x90\x41\x8f\x82\x30\x10\x85\xef\xfc\x8a\xb9\x01\x89\x4b\xdc\x2b\x46\x93\xae\xd4\x68\x52\x60\xa3\x25\x26\x7b\x21\x23\x56\xed\x0a\x94\x94\xae\x89\xff\x7e\xbb\xa0\xa8\xa7\xed\x6d\xfa\xde\x7c\xf3\x66\x08\xe3\x74\x0d\x9c\x7c\x30\x0a\x45\x89\xb2\x6a\x81\x44\x11\xcc\x53\x96\xc5\x09\x68\x71\x51\x67\xb1\xcf\xd1\x80\x91\x95\x68\x0d\x56\x0d\x6c\x57\x7c\x09\x7c\x15\x53\xf8\x4a\x13\x0a\x49\xc6\xd8\xc4\x21\xff\x72\x0a\x34\x52\xd5\xb9\x16\xd8\xaa\x1a\x2e\xa8\x8b\x13\x6a\xef\x7d\x3c\x1e\xfb\x90\xa4\xbc\xe3\x40\x44\x17\x24\x63\x1c\x5c\x77\xe2\x38\xd9\x67\x44\xf8\x80\x2b\x60\x43\xb9\x03\xf6\x3d\xa5\x9a\x82\x51\xf9\x10\xcd\xf3\x74\x70\xc1\xf2\x47\xbc\xcd\x66\xee\xcd\x45\x8c\xeb\x87\xe1\x4e\x1e\x65\x6d\xfc\xd1\xd0\xff\x9a\x66\x6a\x83\x12\x46\x37\x73\xfa\x4a\xf8\x13\xdd\x91\x4d\xe3\x77\x8d\x8b\x75\x1a\x83\x3a\x1c\x84\xce\x9b\x12\xaf\x42\xb7\xa0\x9a\x11\x7c\x5b\xd7\x2e\x17\x58\x9c\x3c\xd5\x04\x0f\x7a\xeb\x03\xd9\x80\xee\x5a\xb7\x4b\xba\xb6\xab\x04\x47\xac\x44\x2e\xf7\x76\xa2\xb5\xde\x0b\x92\x44\x56\xea\x91\x83\xf8\x28\x7b\xb9\x9f\x2b\xf7\x61\x78\xbb\x5d\x6f\xbb\x7f\x77\x53\x7a\xa7\xd1\x58\xb7\x58\x74\x0b\x76\x34\x1d\x9c\xc5\x75\xe2\xfc\x02\x09\xcd\x46\x25\xec\x01\x00\x00")
+
+func migrations0030AddrevocationtoclaimsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0030AddrevocationtoclaimsSql,
+		"migrations/0030-AddRevocationToClaims.sql",
+	)
+}
+
+func migrations0030AddrevocationtoclaimsSql() (*asset, error) {
+	bytes, err := migrations0030AddrevocationtoclaimsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0030-AddRevocationToClaims.sql", size: 492, mode: os.FileMode(420), modTime: time.Unix(1528700000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0031MovemaxoffersperplacementtoplacementsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xad\x92\x5b\x4f\xc2\x40\x10\x85\xdf\xf9\x15\xf3\x60\xb2\x6d\x2c\x8d\x3e\x5a\x14\x53\xe9\x22\x25\x75\x97\xd0\x12\x4d\x8c\x92\x02\x4b\x05\x7b\x4b\xbb\x18\x88\xd1\xdf\xee\xf6\x42\x5b\x34\x24\x3c\xd8\xa7\x49\xf7\xcc\xd9\x39\xdf\xac\x49\x6c\x3c\x76\xc0\x24\x0e\x85\xd8\x77\xe7\x2c\x60\x21\x4f\x41\xf2\xdc\x80\x4d\x57\x0b\x05\x42\x51\xc8\x2d\x10\x9f\x8d\x2d\xdc\x73\xc0\x30\x6d\xc7\x24\xa2\x88\xd4\x4a\x14\xa9\x55\x6f\x2e\xed\x8f\xe9\x03\x44\xcb\x25\x4b\x52\x88\x60\x48\x4d\x02\x99\x36\x05\x0f\xa8\x28\xd5\xd5\x02\x6e\xea\xfe\xbc\xe5\x71\x80\xc7\x18\xd6\x69\x14\xce\xa6\x7c\x17\xb3\x68\x29\x79\x6a\xc0\xb8\xbb\x70\xb9\xdb\xee\xa2\xc0\xdd\xd2\xdc\x70\xc4\x92\xd1\xfe\x32\x24\x0b\x1f\x14\xcd\xd6\x6c\xce\x51\x6e\xa3\x13\x03\x4e\xe8\x83\xeb\x2e\xa0\xcf\x2f\xa4\x69\x43\x9b\x92\xbb\xaa\x95\x50\x07\xf0\x93\x48\x68\x83\x54\xe6\xbd\x2c\xd2\x34\xe0\xc4\xe5\xb0\xf1\x3e\x40\x33\x4c\xc1\x4a\xa4\xec\x51\xd2\xb7\xcc\x0c\x18\xcd\x6c\x07\x26\xb9\xef\xb4\x5a\xe6\x29\xbc\x15\x10\x53\x4f\x0b\x7e\x07\xec\x33\x72\x0a\xf8\xea\x3b\xdb\x29\x20\xf9\xea\x87\xeb\x6f\x98\xa6\x71\xb6\xe5\xb2\xa6\xad\x42\xce\x3c\x96\xd4\x1b\x28\x99\x2b\x25\x56\xe6\xce\xdf\x4e\x83\xaa\xdb\xe0\xff\xf7\x56\x0e\x4c\xca\xd1\x73\x61\xb8\x09\x66\x2c\x41\xb9\xe8\x20\x12\x7c\x03\x7a\x7d\xbe\x68\x5f\xbd\x9c\x9f\xa1\x3f\x58\x7f\x3d\xd1\x0c\xf3\x64\x64\xe8\x0e\x16\xb0\x9c\x06\x40\x71\x85\x85\x75\xdb\x91\x6a\xdc\x6a\x7d\xaa\x88\x75\xf7\xac\x89\x81\x8d\xc6\x4f\x59\x2c\xaa\xf4\x2a\x10\xe6\x8e\x65\x6a\xe1\x57\x95\x6d\x38\xf6\xbe\x0a\x70\x95\xf0\xf6\x98\xb0\xd3\xfa\x01\x89\x63\x49\xf9\x82\x03\x00\x00")
+
+func migrations0031MovemaxoffersperplacementtoplacementsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0031MovemaxoffersperplacementtoplacementsSql,
+		"migrations/0031-MoveMaxOffersPerPlacementToPlacements.sql",
+	)
+}
+
+func migrations0031MovemaxoffersperplacementtoplacementsSql() (*asset, error) {
+	bytes, err := migrations0031MovemaxoffersperplacementtoplacementsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0031-MoveMaxOffersPerPlacementToPlacements.sql", size: 898, mode: os.FileMode(420), modTime: time.Unix(1528800000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0032AddcreatedbytoscheduledoperationsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\x4e\xce\x48\x4d\x29\xcd\x49\x4d\x89\xcf\x2f\x48\x2d\x4a\x2c\xc9\xcc\xcf\x2b\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x48\x2e\x4a\x4d\x2c\x01\xca\x26\x55\x2a\x94\x25\x16\x25\x67\x24\x16\x69\x18\x99\x9a\x6a\x2a\xf8\xf9\x87\x28\xf8\x85\xfa\xf8\x28\xb8\xb8\xba\x39\x86\xfa\x84\x28\xa8\xab\x5b\x73\x01\x00\x85\xaa\x89\x20\x59\x00\x00\x00")
+
+func migrations0032AddcreatedbytoscheduledoperationsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0032AddcreatedbytoscheduledoperationsSql,
+		"migrations/0032-AddCreatedByToScheduledOperations.sql",
+	)
+}
+
+func migrations0032AddcreatedbytoscheduledoperationsSql() (*asset, error) {
+	bytes, err := migrations0032AddcreatedbytoscheduledoperationsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0032-AddCreatedByToScheduledOperations.sql", size: 89, mode: os.FileMode(420), modTime: time.Unix(1528900000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -361,6 +802,27 @@ var _bindata = map[string]func() (*asset, error){
 	"migrations/0009-AddCostToOfferInstances.sql": migrations0009AddcosttoofferinstancesSql,
 	"migrations/0010-CreateOfferPlayerTable.sql": migrations0010CreateofferplayertableSql,
 	"migrations/0011-CreateOfferVersionTable.sql": migrations0011CreateofferversiontableSql,
+	"migrations/0012-CreateOfferDraftsTable.sql": migrations0012CreateofferdraftstableSql,
+	"migrations/0013-CreateScheduledOperationsTable.sql": migrations0013CreatescheduledoperationstableSql,
+	"migrations/0014-CreateAuditEventsTable.sql": migrations0014CreateauditeventstableSql,
+	"migrations/0015-CreateGameRolesAndAPIKeysTables.sql": migrations0015CreategamerolesandapikeystablesSql,
+	"migrations/0016-AddClientKeysToGames.sql": migrations0016AddclientkeystogamesSql,
+	"migrations/0017-AddReceiptsToOfferPlayers.sql": migrations0017AddreceiptstoofferplayersSql,
+	"migrations/0018-AddRevocationsToOfferPlayers.sql": migrations0018AddrevocationstoofferplayersSql,
+	"migrations/0019-CreateClaimsTable.sql": migrations0019CreateclaimstableSql,
+	"migrations/0020-CreateDedupeKeysTable.sql": migrations0020CreatededupekeystableSql,
+	"migrations/0021-CreateTestPlayersTable.sql": migrations0021CreatetestplayerstableSql,
+	"migrations/0022-AddStockToOffers.sql": migrations0022AddstocktooffersSql,
+	"migrations/0023-AddFirstViewToOfferPlayers.sql": migrations0023AddfirstviewtoofferplayersSql,
+	"migrations/0024-AddPrerequisitesToOffers.sql": migrations0024AddprerequisitestooffersSql,
+	"migrations/0025-AddPriorityAndExclusionGroupToOffers.sql": migrations0025AddpriorityandexclusiongrouptooffersSql,
+	"migrations/0026-CreatePlacementsTable.sql": migrations0026CreateplacementstableSql,
+	"migrations/0027-CreatePlayerImpressionsTable.sql": migrations0027CreateplayerimpressionstableSql,
+	"migrations/0028-AddRotationToPlacements.sql": migrations0028AddrotationtoplacementsSql,
+	"migrations/0029-CreateReceiptTransactionsTable.sql": migrations0029CreatereceipttransactionstableSql,
+	"migrations/0030-AddRevocationToClaims.sql": migrations0030AddrevocationtoclaimsSql,
+	"migrations/0031-MoveMaxOffersPerPlacementToPlacements.sql": migrations0031MovemaxoffersperplacementtoplacementsSql,
+	"migrations/0032-AddCreatedByToScheduledOperations.sql": migrations0032AddcreatedbytoscheduledoperationsSql,
 }
 
 // AssetDir returns the file names below a certain
@@ -415,6 +877,27 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"0009-AddCostToOfferInstances.sql": &bintree{migrations0009AddcosttoofferinstancesSql, map[string]*bintree{}},
 		"0010-CreateOfferPlayerTable.sql": &bintree{migrations0010CreateofferplayertableSql, map[string]*bintree{}},
 		"0011-CreateOfferVersionTable.sql": &bintree{migrations0011CreateofferversiontableSql, map[string]*bintree{}},
+		"0012-CreateOfferDraftsTable.sql": &bintree{migrations0012CreateofferdraftstableSql, map[string]*bintree{}},
+		"0013-CreateScheduledOperationsTable.sql": &bintree{migrations0013CreatescheduledoperationstableSql, map[string]*bintree{}},
+		"0014-CreateAuditEventsTable.sql": &bintree{migrations0014CreateauditeventstableSql, map[string]*bintree{}},
+		"0015-CreateGameRolesAndAPIKeysTables.sql": &bintree{migrations0015CreategamerolesandapikeystablesSql, map[string]*bintree{}},
+		"0016-AddClientKeysToGames.sql": &bintree{migrations0016AddclientkeystogamesSql, map[string]*bintree{}},
+		"0017-AddReceiptsToOfferPlayers.sql": &bintree{migrations0017AddreceiptstoofferplayersSql, map[string]*bintree{}},
+		"0018-AddRevocationsToOfferPlayers.sql": &bintree{migrations0018AddrevocationstoofferplayersSql, map[string]*bintree{}},
+		"0019-CreateClaimsTable.sql": &bintree{migrations0019CreateclaimstableSql, map[string]*bintree{}},
+		"0020-CreateDedupeKeysTable.sql": &bintree{migrations0020CreatededupekeystableSql, map[string]*bintree{}},
+		"0021-CreateTestPlayersTable.sql": &bintree{migrations0021CreatetestplayerstableSql, map[string]*bintree{}},
+		"0022-AddStockToOffers.sql": &bintree{migrations0022AddstocktooffersSql, map[string]*bintree{}},
+		"0023-AddFirstViewToOfferPlayers.sql": &bintree{migrations0023AddfirstviewtoofferplayersSql, map[string]*bintree{}},
+		"0024-AddPrerequisitesToOffers.sql": &bintree{migrations0024AddprerequisitestooffersSql, map[string]*bintree{}},
+		"0025-AddPriorityAndExclusionGroupToOffers.sql": &bintree{migrations0025AddpriorityandexclusiongrouptooffersSql, map[string]*bintree{}},
+		"0026-CreatePlacementsTable.sql": &bintree{migrations0026CreateplacementstableSql, map[string]*bintree{}},
+		"0027-CreatePlayerImpressionsTable.sql": &bintree{migrations0027CreateplayerimpressionstableSql, map[string]*bintree{}},
+		"0028-AddRotationToPlacements.sql": &bintree{migrations0028AddrotationtoplacementsSql, map[string]*bintree{}},
+		"0029-CreateReceiptTransactionsTable.sql": &bintree{migrations0029CreatereceipttransactionstableSql, map[string]*bintree{}},
+		"0030-AddRevocationToClaims.sql": &bintree{migrations0030AddrevocationtoclaimsSql, map[string]*bintree{}},
+		"0031-MoveMaxOffersPerPlacementToPlacements.sql": &bintree{migrations0031MovemaxoffersperplacementtoplacementsSql, map[string]*bintree{}},
+		"0032-AddCreatedByToScheduledOperations.sql": &bintree{migrations0032AddcreatedbytoscheduledoperationsSql, map[string]*bintree{}},
 	}},
 }}
 
@@ -0,0 +1,130 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+
+	edat "github.com/topfreegames/extensions/dat"
+	"gopkg.in/mgutz/dat.v2/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//APIKeyPrincipalPrefix prefixes the principal of requests authenticated with an API key
+const APIKeyPrincipalPrefix = "apikey:"
+
+//APIKey authenticates a machine client. Only a hash of the key is stored,
+//Key is set only when the key is created.
+type APIKey struct {
+	ID        string       `db:"id" json:"id" valid:"optional"`
+	Name      string       `db:"name" json:"name" valid:"matches(^[a-zA-Z0-9-_.]+$),stringlength(1|200),required"`
+	KeyHash   string       `db:"key_hash" json:"-" valid:"optional"`
+	Key       string       `db:"-" json:"key,omitempty" valid:"optional"`
+	CreatedAt dat.NullTime `db:"created_at" json:"createdAt" valid:"optional"`
+}
+
+//Principal returns the principal roles are granted to when using the key
+func (k *APIKey) Principal() string {
+	return APIKeyPrincipalPrefix + k.Name
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+//CreateAPIKey generates a new key with the given name
+func CreateAPIKey(ctx context.Context, db runner.Connection, name string, mr *MixedMetricsReporter) (*APIKey, error) {
+	raw := make([]byte, 32)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := &APIKey{
+		Name: name,
+		Key:  hex.EncodeToString(raw),
+	}
+	apiKey.KeyHash = hashAPIKey(apiKey.Key)
+	err = mr.WithDatastoreSegment("api_keys", SegmentInsert, func() error {
+		builder := db.InsertInto("api_keys")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.Columns("name", "key_hash").
+			Record(apiKey).
+			Returning("id", "created_at").
+			QueryStruct(apiKey)
+	})
+	err = handleUniqueViolationError("APIKey", err)
+	if err != nil {
+		return nil, err
+	}
+	return apiKey, nil
+}
+
+//GetAPIKeyByKey returns the API key matching key
+func GetAPIKeyByKey(ctx context.Context, db runner.Connection, key string, mr *MixedMetricsReporter) (*APIKey, error) {
+	var apiKey APIKey
+	err := mr.WithDatastoreSegment("api_keys", SegmentSelect, func() error {
+		builder := db.Select("id", "name", "key_hash", "created_at")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("api_keys").
+			Where("key_hash = $1", hashAPIKey(key)).
+			QueryStruct(&apiKey)
+	})
+
+	err = handleNotFoundError("APIKey", map[string]interface{}{}, err)
+	return &apiKey, err
+}
+
+//DeleteAPIKey revokes the API key with the given name and the roles granted to it in every game
+func DeleteAPIKey(ctx context.Context, db runner.Connection, name string, mr *MixedMetricsReporter) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.AutoRollback()
+
+	apiKey := APIKey{Name: name}
+	err = mr.WithDatastoreSegment("api_keys", SegmentDelete, func() error {
+		builder := tx.DeleteFrom("api_keys")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.
+			Where("name = $1", name).
+			Returning("id").
+			QueryStruct(&apiKey)
+	})
+	err = handleNotFoundError("APIKey", map[string]interface{}{
+		"Name": name,
+	}, err)
+	if err != nil {
+		return err
+	}
+
+	roles := []*GameRole{}
+	err = mr.WithDatastoreSegment("game_roles", SegmentDelete, func() error {
+		builder := tx.DeleteFrom("game_roles")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.
+			Where("principal = $1", apiKey.Principal()).
+			Returning("*").
+			QueryStructs(&roles)
+	})
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		err = insertAuditEvent(ctx, tx, role.GameID, "", AuditActionDeleteRole, role, nil, mr)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,83 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+)
+
+var _ = Describe("API Key Model", func() {
+	It("should create a key that can be found by its value", func() {
+		apiKey, err := models.CreateAPIKey(nil, db, "deploy-bot", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apiKey.Key).To(HaveLen(64))
+		Expect(apiKey.KeyHash).NotTo(Equal(apiKey.Key))
+
+		dbKey, err := models.GetAPIKeyByKey(nil, db, apiKey.Key, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dbKey.Name).To(Equal("deploy-bot"))
+		Expect(dbKey.Key).To(BeEmpty())
+		Expect(dbKey.Principal()).To(Equal("apikey:deploy-bot"))
+	})
+
+	It("should not find a key with a wrong value", func() {
+		_, err := models.CreateAPIKey(nil, db, "deploy-bot", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = models.GetAPIKeyByKey(nil, db, "wrong-key", nil)
+
+		Expect(err).To(HaveOccurred())
+		_, ok := err.(*errors.ModelNotFoundError)
+		Expect(ok).To(BeTrue())
+	})
+
+	It("should return conflict error if name is taken", func() {
+		_, err := models.CreateAPIKey(nil, db, "deploy-bot", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = models.CreateAPIKey(nil, db, "deploy-bot", nil)
+
+		Expect(err).To(HaveOccurred())
+		_, ok := err.(*errors.ConflictedModelError)
+		Expect(ok).To(BeTrue())
+	})
+
+	It("should delete a key", func() {
+		apiKey, err := models.CreateAPIKey(nil, db, "deploy-bot", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = models.DeleteAPIKey(nil, db, "deploy-bot", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = models.GetAPIKeyByKey(nil, db, apiKey.Key, nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should revoke the roles of a deleted key", func() {
+		apiKey, err := models.CreateAPIKey(nil, db, "deploy-bot", nil)
+		Expect(err).NotTo(HaveOccurred())
+		err = models.UpsertGameRole(nil, db, &models.GameRole{
+			GameID:    defaultGameID,
+			Principal: apiKey.Principal(),
+			Role:      models.RolePublisher,
+		}, time.Now(), nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = models.DeleteAPIKey(nil, db, "deploy-bot", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		roles, err := models.ListPrincipalRoles(nil, db, apiKey.Principal(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(roles).To(BeEmpty())
+	})
+})
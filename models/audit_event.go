@@ -0,0 +1,137 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	edat "github.com/topfreegames/extensions/dat"
+	"gopkg.in/mgutz/dat.v2/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//Audited actions
+const (
+	AuditActionInsertOffer      = "insert-offer"
+	AuditActionUpdateOffer      = "update-offer"
+	AuditActionEnableOffer      = "enable-offer"
+	AuditActionDisableOffer     = "disable-offer"
+	AuditActionUpsertGame       = "upsert-game"
+	AuditActionUpsertRole       = "upsert-role"
+	AuditActionDeleteRole       = "delete-role"
+	AuditActionRotateKey        = "rotate-client-key"
+	AuditActionAddTestPlayer    = "add-test-player"
+	AuditActionRemoveTestPlayer = "remove-test-player"
+	AuditActionErasePlayer      = "erase-player"
+	AuditActionResetPlayerOffer = "reset-player-offer"
+	AuditActionMergePlayers     = "merge-players"
+	AuditActionUpsertPlacement  = "upsert-placement"
+	AuditActionDeletePlacement  = "delete-placement"
+)
+
+//AuditEvent records a change made to a game or an offer template
+type AuditEvent struct {
+	ID        string    `db:"id" json:"id"`
+	GameID    string    `db:"game_id" json:"gameId"`
+	OfferID   string    `db:"offer_id" json:"offerId,omitempty"`
+	Actor     string    `db:"actor" json:"actor"`
+	Action    string    `db:"action" json:"action"`
+	Before    dat.JSON  `db:"before" json:"before"`
+	After     dat.JSON  `db:"after" json:"after"`
+	RequestID string    `db:"request_id" json:"requestId"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
+
+type auditContextKey string
+
+const auditInfoKey = auditContextKey("auditInfo")
+
+type auditInfo struct {
+	actor     string
+	requestID string
+}
+
+//NewContextWithAuditInfo returns a context that attributes the changes made with it to actor and requestID
+func NewContextWithAuditInfo(ctx context.Context, actor, requestID string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, auditInfoKey, auditInfo{actor: actor, requestID: requestID})
+}
+
+func auditInfoFromContext(ctx context.Context) auditInfo {
+	if ctx == nil {
+		return auditInfo{}
+	}
+	info, _ := ctx.Value(auditInfoKey).(auditInfo)
+	return info
+}
+
+func insertAuditEvent(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, offerID, action string,
+	before, after interface{},
+	mr *MixedMetricsReporter,
+) error {
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return err
+	}
+
+	info := auditInfoFromContext(ctx)
+	event := &AuditEvent{
+		GameID:    gameID,
+		OfferID:   offerID,
+		Actor:     info.actor,
+		Action:    action,
+		Before:    dat.JSON(beforeJSON),
+		After:     dat.JSON(afterJSON),
+		RequestID: info.requestID,
+	}
+	return mr.WithDatastoreSegment("audit_events", SegmentInsert, func() error {
+		builder := db.InsertInto("audit_events")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.Columns("game_id", "offer_id", "actor", "action", "before", "after", "request_id").
+			Record(event).
+			Returning("id").
+			QueryStruct(event)
+	})
+}
+
+//ListAuditEvents returns the audit events of a game created since the given time, oldest first.
+//If offerID is not empty only the events of that offer are returned.
+func ListAuditEvents(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, offerID string,
+	since time.Time,
+	limit uint64,
+	mr *MixedMetricsReporter,
+) ([]*AuditEvent, error) {
+	events := []*AuditEvent{}
+	err := mr.WithDatastoreSegment("audit_events", SegmentSelect, func() error {
+		builder := db.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		builder.From("audit_events").
+			Where("game_id = $1 AND created_at >= $2", gameID, since)
+		if offerID != "" {
+			builder.Where("offer_id = $1", offerID)
+		}
+		return builder.OrderBy("created_at, id").
+			Limit(limit).
+			QueryStructs(&events)
+	})
+	return events, err
+}
@@ -0,0 +1,136 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/topfreegames/offers/models"
+	"gopkg.in/mgutz/dat.v2/dat"
+)
+
+var _ = Describe("Audit Event Model", func() {
+	var ctx context.Context
+	since := time.Unix(0, 0)
+	offerID := "dd21ec96-2890-4ba0-b8e2-40ea67196990"
+
+	BeforeEach(func() {
+		ctx = models.NewContextWithAuditInfo(context.Background(), "admin@tfgco.com", "request-1")
+	})
+
+	It("should audit inserted offers", func() {
+		offer := &models.Offer{
+			Name:      "offer-1",
+			ProductID: "com.tfg.example",
+			GameID:    defaultGameID,
+			Contents:  dat.JSON([]byte(`{"gems": 5, "gold": 100}`)),
+			Period:    dat.JSON([]byte(`{"every": "10m"}`)),
+			Frequency: dat.JSON([]byte(`{"every": "24h"}`)),
+			Trigger:   dat.JSON([]byte(`{"from": 1487280506875}`)),
+			Placement: "popup",
+		}
+
+		offer, err := models.InsertOffer(ctx, db, offer, offersCache, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		events, err := models.ListAuditEvents(nil, db, defaultGameID, offer.ID, since, 10, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Action).To(Equal(models.AuditActionInsertOffer))
+		Expect(events[0].Actor).To(Equal("admin@tfgco.com"))
+		Expect(events[0].RequestID).To(Equal("request-1"))
+		Expect(events[0].Before).To(MatchJSON(`null`))
+		var after map[string]interface{}
+		err = events[0].After.Unmarshal(&after)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(after["id"]).To(Equal(offer.ID))
+		Expect(after["name"]).To(Equal("offer-1"))
+	})
+
+	It("should audit updated offers with their previous state", func() {
+		offer := &models.Offer{
+			ID:        offerID,
+			Name:      "updated-name",
+			ProductID: "com.tfg.example",
+			GameID:    defaultGameID,
+			Contents:  dat.JSON([]byte(`{"gems": 5}`)),
+			Period:    dat.JSON([]byte(`{"every": "10m"}`)),
+			Frequency: dat.JSON([]byte(`{"every": "24h"}`)),
+			Trigger:   dat.JSON([]byte(`{"from": 1487280506875}`)),
+			Placement: "popup",
+		}
+
+		_, err := models.UpdateOffer(ctx, db, offer, offersCache, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		events, err := models.ListAuditEvents(nil, db, defaultGameID, offerID, since, 10, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Action).To(Equal(models.AuditActionUpdateOffer))
+		var before, after map[string]interface{}
+		Expect(events[0].Before.Unmarshal(&before)).To(Succeed())
+		Expect(events[0].After.Unmarshal(&after)).To(Succeed())
+		Expect(before["name"]).To(Equal("template-1"))
+		Expect(before["version"]).To(BeEquivalentTo(1))
+		Expect(after["name"]).To(Equal("updated-name"))
+		Expect(after["version"]).To(BeEquivalentTo(2))
+	})
+
+	It("should audit enabling and disabling offers", func() {
+		err := models.SetEnabledOffer(ctx, db, defaultGameID, offerID, false, offersCache, nil)
+		Expect(err).NotTo(HaveOccurred())
+		err = models.SetEnabledOffer(ctx, db, defaultGameID, offerID, true, offersCache, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		events, err := models.ListAuditEvents(nil, db, defaultGameID, offerID, since, 10, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		actions := []string{events[0].Action, events[1].Action}
+		Expect(actions).To(ConsistOf(models.AuditActionDisableOffer, models.AuditActionEnableOffer))
+	})
+
+	It("should audit upserted games", func() {
+		game := &models.Game{
+			ID:   "audited-game",
+			Name: "Audited Game",
+		}
+
+		err := models.UpsertGame(ctx, db, game, time.Now(), nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		events, err := models.ListAuditEvents(nil, db, "audited-game", "", since, 10, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Action).To(Equal(models.AuditActionUpsertGame))
+		Expect(events[0].OfferID).To(BeEmpty())
+		Expect(events[0].Before).To(MatchJSON(`null`))
+	})
+
+	It("should not return events older than since", func() {
+		err := models.SetEnabledOffer(ctx, db, defaultGameID, offerID, false, offersCache, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		events, err := models.ListAuditEvents(nil, db, defaultGameID, offerID, time.Now().Add(time.Hour), 10, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(BeEmpty())
+	})
+
+	It("should leave the actor empty if there is no audit info in the context", func() {
+		err := models.SetEnabledOffer(nil, db, defaultGameID, offerID, false, offersCache, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		events, err := models.ListAuditEvents(nil, db, defaultGameID, offerID, since, 10, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Actor).To(BeEmpty())
+	})
+})
@@ -0,0 +1,117 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"time"
+
+	edat "github.com/topfreegames/extensions/dat"
+	"gopkg.in/mgutz/dat.v2/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//Claim is an entry of the claims ledger. It records the offer version, price and contents
+//a player received in a claim and it is only updated when the claim is revoked.
+type Claim struct {
+	ID              string    `db:"id" json:"id"`
+	GameID          string    `db:"game_id" json:"gameId"`
+	PlayerID        string    `db:"player_id" json:"playerId"`
+	OfferID         string    `db:"offer_id" json:"offerId"`
+	OfferVersionID  string    `db:"offer_version_id" json:"offerVersionId"`
+	OfferVersion    int       `db:"offer_version" json:"offerVersion"`
+	ProductID       string    `db:"product_id" json:"productId"`
+	Cost            dat.JSON  `db:"cost" json:"cost"`
+	Contents        dat.JSON  `db:"contents" json:"contents"`
+	TransactionID   string    `db:"transaction_id" json:"transactionId"`
+	ClientTimestamp time.Time `db:"client_timestamp" json:"clientTimestamp"`
+	ClaimedAt       time.Time `db:"claimed_at" json:"claimedAt"`
+	TestPlayer      bool      `db:"test_player" json:"testPlayer"`
+
+	RevokedAt        dat.NullTime `db:"revoked_at" json:"revokedAt"`
+	RevocationReason string       `db:"revocation_reason" json:"revocationReason,omitempty"`
+}
+
+//insertClaim adds the claim to the ledger.
+//It returns false if the transaction was already claimed by the player for the same offer.
+func insertClaim(ctx context.Context, db runner.Connection, claim *Claim, mr *MixedMetricsReporter) (bool, error) {
+	if claim.Cost == nil {
+		claim.Cost = dat.JSON([]byte(`{}`))
+	}
+	if claim.Contents == nil {
+		claim.Contents = dat.JSON([]byte(`{}`))
+	}
+
+	var inserted int64
+	err := mr.WithDatastoreSegment("claims", SegmentInsert, func() error {
+		builder := db.SQL(`
+			INSERT INTO claims (
+				game_id, player_id, offer_id, offer_version_id, offer_version,
+				product_id, cost, contents, transaction_id, client_timestamp, claimed_at, test_player
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			ON CONFLICT DO NOTHING`,
+			claim.GameID, claim.PlayerID, claim.OfferID, claim.OfferVersionID, claim.OfferVersion,
+			claim.ProductID, claim.Cost, claim.Contents, claim.TransactionID, claim.ClientTimestamp, claim.ClaimedAt, claim.TestPlayer,
+		)
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		res, err := builder.Exec()
+		if err != nil {
+			return err
+		}
+		inserted = res.RowsAffected
+		return nil
+	})
+	return inserted > 0, err
+}
+
+//ListClaims returns the claims of a game made since the given time, oldest first.
+//If playerID is not empty only the claims of that player are returned.
+func ListClaims(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, playerID string,
+	since time.Time,
+	limit uint64,
+	mr *MixedMetricsReporter,
+) ([]*Claim, error) {
+	claims := []*Claim{}
+	err := mr.WithDatastoreSegment("claims", SegmentSelect, func() error {
+		builder := db.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		builder.From("claims").
+			Where("game_id = $1 AND claimed_at >= $2", gameID, since)
+		if playerID != "" {
+			builder.Where("player_id = $1", playerID)
+		}
+		return builder.OrderBy("claimed_at, id").
+			Limit(limit).
+			QueryStructs(&claims)
+	})
+	return claims, err
+}
+
+//revokeClaim marks the claims of the transaction of a player for an offer as revoked
+func revokeClaim(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, playerID, offerID, transactionID, reason string,
+	t time.Time,
+	mr *MixedMetricsReporter,
+) error {
+	return mr.WithDatastoreSegment("claims", SegmentUpdate, func() error {
+		builder := db.Update("claims")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		_, err := builder.Set("revoked_at", t).
+			Set("revocation_reason", reason).
+			Where("game_id = $1 AND player_id = $2 AND offer_id = $3 AND transaction_id = $4 AND revoked_at IS NULL",
+				gameID, playerID, offerID, transactionID).
+			Exec()
+		return err
+	})
+}
@@ -0,0 +1,151 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	edat "github.com/topfreegames/extensions/dat"
+	"github.com/topfreegames/offers/errors"
+	"gopkg.in/mgutz/dat.v2/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//ClaimRevocation records that a claimed transaction was refunded or revoked.
+//It is stored in the revocations of the offer player, by transaction id, and in the claims of the transaction.
+type ClaimRevocation struct {
+	TransactionID       string `json:"transactionId"`
+	RevokedAt           int64  `json:"revokedAt"`
+	Reason              string `json:"reason,omitempty"`
+	RestoredEligibility bool   `json:"restoredEligibility"`
+}
+
+//getRevocations returns the revocations of the claims of the offer player, oldest first
+func (o *OfferPlayer) getRevocations() ([]*ClaimRevocation, error) {
+	if len(o.Revocations) == 0 {
+		return nil, nil
+	}
+	revocationsByTransaction := map[string]*ClaimRevocation{}
+	err := o.Revocations.Unmarshal(&revocationsByTransaction)
+	if err != nil {
+		return nil, err
+	}
+	revocations := make([]*ClaimRevocation, 0, len(revocationsByTransaction))
+	for _, revocation := range revocationsByTransaction {
+		revocations = append(revocations, revocation)
+	}
+	sort.Slice(revocations, func(i, j int) bool {
+		if revocations[i].RevokedAt != revocations[j].RevokedAt {
+			return revocations[i].RevokedAt < revocations[j].RevokedAt
+		}
+		return revocations[i].TransactionID < revocations[j].TransactionID
+	})
+	return revocations, nil
+}
+
+//RevokeClaim marks the claim of transactionID as revoked. If restoreEligibility is true the claim
+//counter is decremented so the player can claim the offer again. Revoking a claim twice does not
+//change it again: the first revocation is returned and alreadyRevoked is true.
+//If playerID is empty the claim is found by its transaction only.
+func RevokeClaim(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, playerID, transactionID, reason string,
+	restoreEligibility bool,
+	t time.Time,
+	mr *MixedMetricsReporter,
+) (*OfferPlayer, *ClaimRevocation, bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	defer tx.AutoRollback()
+
+	query := `
+		SELECT * FROM offer_players
+		WHERE game_id = $1 AND (player_id, offer_id) IN (
+			SELECT player_id, offer_id::varchar FROM claims WHERE game_id = $1 AND transaction_id = $2
+			UNION
+			SELECT player_id, offer_id FROM dedupe_keys WHERE game_id = $1 AND kind = $3 AND key = $2
+		)`
+	args := []interface{}{gameID, transactionID, DedupeKindTransaction}
+	if playerID != "" {
+		query += " AND player_id = $4"
+		args = append(args, playerID)
+	}
+
+	offerPlayers := []*OfferPlayer{}
+	err = mr.WithDatastoreSegment("offer_players", SegmentSelect, func() error {
+		builder := tx.SQL(query+" FOR UPDATE", args...)
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.QueryStructs(&offerPlayers)
+	})
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if len(offerPlayers) == 0 {
+		filters := map[string]interface{}{"GameID": gameID, "TransactionID": transactionID}
+		if playerID != "" {
+			filters["PlayerID"] = playerID
+		}
+		return nil, nil, false, errors.NewModelNotFoundError("Claim", filters)
+	}
+	if len(offerPlayers) > 1 {
+		return nil, nil, false, errors.NewInvalidModelError(
+			"Claim",
+			fmt.Sprintf("transaction %s was claimed by more than one player, the playerId is required", transactionID),
+		)
+	}
+	offerPlayer := offerPlayers[0]
+
+	revocations := map[string]*ClaimRevocation{}
+	err = offerPlayer.Revocations.Unmarshal(&revocations)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if previous, ok := revocations[transactionID]; ok {
+		return offerPlayer, previous, true, tx.Commit()
+	}
+
+	revocation := &ClaimRevocation{
+		TransactionID:       transactionID,
+		RevokedAt:           t.Unix(),
+		Reason:              reason,
+		RestoredEligibility: restoreEligibility && offerPlayer.ClaimCounter > 0,
+	}
+	revocations[transactionID] = revocation
+	jsonRevocations, err := dat.NewJSON(revocations)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	offerPlayer.Revocations = *jsonRevocations
+	if revocation.RestoredEligibility {
+		offerPlayer.ClaimCounter--
+	}
+
+	err = mr.WithDatastoreSegment("offer_players", SegmentUpdate, func() error {
+		builder := tx.Update("offer_players")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		_, err := builder.Set("claim_counter", offerPlayer.ClaimCounter).
+			Set("revocations", offerPlayer.Revocations).
+			Where("id = $1", offerPlayer.ID).
+			Exec()
+		return err
+	})
+	if err != nil {
+		return nil, nil, false, err
+	}
+	err = revokeClaim(ctx, tx, gameID, offerPlayer.PlayerID, offerPlayer.OfferID, transactionID, reason, t, mr)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return offerPlayer, revocation, false, tx.Commit()
+}
@@ -0,0 +1,97 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	uuid "github.com/satori/go.uuid"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+)
+
+var _ = Describe("Claim Revocation Model", func() {
+	currentTime := time.Unix(1486678000, 0)
+	offerInstanceID := "eb7e8d2a-2739-4da3-aa31-7970b63bdad7"
+	playerID := "revoked-player"
+	var transactionID string
+
+	BeforeEach(func() {
+		transactionID = uuid.NewV4().String()
+		_, _, _, err := models.ClaimOffer(nil, db, defaultGameID, offerInstanceID, playerID, "", transactionID, currentTime.Unix(), currentTime, nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should revoke a claim without restoring eligibility", func() {
+		offerPlayer, revocation, alreadyRevoked, err := models.RevokeClaim(nil, db, defaultGameID, "", transactionID, "refunded", false, currentTime, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(alreadyRevoked).To(BeFalse())
+		Expect(offerPlayer.PlayerID).To(Equal(playerID))
+		Expect(offerPlayer.ClaimCounter).To(Equal(1))
+		Expect(revocation.RevokedAt).To(Equal(currentTime.Unix()))
+		Expect(revocation.RestoredEligibility).To(BeFalse())
+	})
+
+	It("should decrement the claim counter if eligibility is restored", func() {
+		_, revocation, _, err := models.RevokeClaim(nil, db, defaultGameID, playerID, transactionID, "", true, currentTime, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(revocation.RestoredEligibility).To(BeTrue())
+
+		offerPlayers, err := models.GetOffersByPlayer(nil, db, defaultGameID, playerID, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offerPlayers).To(HaveLen(1))
+		Expect(offerPlayers[0].ClaimCounter).To(Equal(0))
+	})
+
+	It("should mark the claim in the ledger and in the player offers as revoked", func() {
+		_, _, _, err := models.RevokeClaim(nil, db, defaultGameID, playerID, transactionID, "refunded", false, currentTime, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		claims, err := models.ListClaims(nil, db, defaultGameID, playerID, time.Unix(0, 0), 10, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(claims).To(HaveLen(1))
+		Expect(claims[0].RevokedAt.Valid).To(BeTrue())
+		Expect(claims[0].RevokedAt.Time.Unix()).To(Equal(currentTime.Unix()))
+		Expect(claims[0].RevocationReason).To(Equal("refunded"))
+
+		playerOffers, err := models.GetPlayerOffers(nil, db, defaultGameID, playerID, currentTime, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(playerOffers).To(HaveLen(1))
+		Expect(playerOffers[0].Revocations).To(HaveLen(1))
+		Expect(playerOffers[0].Revocations[0].TransactionID).To(Equal(transactionID))
+		Expect(playerOffers[0].Revocations[0].Reason).To(Equal("refunded"))
+	})
+
+	It("should not revoke a claim twice", func() {
+		_, _, _, err := models.RevokeClaim(nil, db, defaultGameID, playerID, transactionID, "first", true, currentTime, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		offerPlayer, revocation, alreadyRevoked, err := models.RevokeClaim(nil, db, defaultGameID, playerID, transactionID, "second", true, currentTime.Add(time.Hour), nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(alreadyRevoked).To(BeTrue())
+		Expect(revocation.Reason).To(Equal("first"))
+		Expect(revocation.RevokedAt).To(Equal(currentTime.Unix()))
+		Expect(offerPlayer.ClaimCounter).To(Equal(0))
+	})
+
+	It("should return error if the transaction was not claimed", func() {
+		otherTransactionID := uuid.NewV4().String()
+		expectedError := errors.NewModelNotFoundError("Claim", map[string]interface{}{
+			"GameID":        defaultGameID,
+			"TransactionID": otherTransactionID,
+		})
+
+		_, _, _, err := models.RevokeClaim(nil, db, defaultGameID, "", otherTransactionID, "", false, currentTime, nil)
+
+		Expect(err).To(MatchError(expectedError))
+	})
+})
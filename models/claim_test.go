@@ -0,0 +1,68 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	uuid "github.com/satori/go.uuid"
+	"github.com/topfreegames/offers/models"
+)
+
+var _ = Describe("Claim Model", func() {
+	currentTime := time.Unix(1486678000, 0)
+	offerInstanceID := "eb7e8d2a-2739-4da3-aa31-7970b63bdad7"
+	playerID := "ledger-player"
+
+	It("should record the offer version claimed", func() {
+		transactionID := uuid.NewV4().String()
+		clientTime := currentTime.Add(-time.Minute)
+		_, _, _, err := models.ClaimOffer(nil, db, defaultGameID, offerInstanceID, playerID, "", transactionID, clientTime.Unix(), currentTime, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		claims, err := models.ListClaims(nil, db, defaultGameID, playerID, time.Unix(0, 0), 10, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(claims).To(HaveLen(1))
+		Expect(claims[0].OfferID).To(Equal("dd21ec96-2890-4ba0-b8e2-40ea67196990"))
+		Expect(claims[0].OfferVersionID).To(Equal(offerInstanceID))
+		Expect(claims[0].OfferVersion).To(Equal(3))
+		Expect(claims[0].ProductID).To(Equal("com.tfg.sample"))
+		Expect(claims[0].Cost).To(MatchJSON(`{"gems": 500}`))
+		Expect(claims[0].Contents).To(MatchJSON(`{"gems": 5, "gold": 100}`))
+		Expect(claims[0].TransactionID).To(Equal(transactionID))
+		Expect(claims[0].ClientTimestamp.Unix()).To(Equal(clientTime.Unix()))
+		Expect(claims[0].ClaimedAt.Unix()).To(Equal(currentTime.Unix()))
+	})
+
+	It("should not record replayed claims", func() {
+		transactionID := uuid.NewV4().String()
+		for i := 0; i < 2; i++ {
+			_, _, _, err := models.ClaimOffer(nil, db, defaultGameID, offerInstanceID, playerID, "", transactionID, currentTime.Unix(), currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		claims, err := models.ListClaims(nil, db, defaultGameID, playerID, time.Unix(0, 0), 10, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(claims).To(HaveLen(1))
+	})
+
+	It("should list claims since the given time", func() {
+		for i := 0; i < 2; i++ {
+			t := currentTime.Add(time.Duration(i) * time.Hour)
+			_, _, _, err := models.ClaimOffer(nil, db, defaultGameID, offerInstanceID, playerID, "", uuid.NewV4().String(), t.Unix(), t, nil)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		claims, err := models.ListClaims(nil, db, defaultGameID, "", currentTime.Add(time.Minute), 10, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(claims).To(HaveLen(1))
+		Expect(claims[0].ClaimedAt.Unix()).To(Equal(currentTime.Add(time.Hour).Unix()))
+	})
+})
@@ -0,0 +1,140 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	edat "github.com/topfreegames/extensions/dat"
+	"gopkg.in/mgutz/dat.v2/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//ClientKeysAt returns the keys that may sign requests for the game at time t:
+//the current key and, during its grace period, the previous one
+func (g *Game) ClientKeysAt(t time.Time) []string {
+	keys := []string{}
+	if g.ClientKey != "" {
+		keys = append(keys, g.ClientKey)
+	}
+	if g.PreviousClientKey != "" && g.PreviousClientKeyExpiresAt.Valid && t.Before(g.PreviousClientKeyExpiresAt.Time) {
+		keys = append(keys, g.PreviousClientKey)
+	}
+	return keys
+}
+
+//RotateGameClientKey generates a new client key for the game.
+//The replaced key keeps being accepted until t + gracePeriod.
+func RotateGameClientKey(
+	ctx context.Context,
+	db runner.Connection,
+	gameID string,
+	t time.Time,
+	gracePeriod time.Duration,
+	mr *MixedMetricsReporter,
+) (*Game, error) {
+	raw := make([]byte, 32)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.AutoRollback()
+
+	var game Game
+	err = mr.WithDatastoreSegment("games", SegmentSelect, func() error {
+		builder := tx.SQL("SELECT * FROM games WHERE id = $1 FOR UPDATE", gameID)
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.QueryStruct(&game)
+	})
+	err = handleNotFoundError("Game", map[string]interface{}{"ID": gameID}, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if game.ClientKey != "" {
+		game.PreviousClientKey = game.ClientKey
+		game.PreviousClientKeyExpiresAt = dat.NullTimeFrom(t.Add(gracePeriod))
+	}
+	game.ClientKey = hex.EncodeToString(raw)
+	game.UpdatedAt = dat.NullTimeFrom(t)
+
+	err = mr.WithDatastoreSegment("games", SegmentUpdate, func() error {
+		builder := tx.Update("games")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		_, err := builder.Set("client_key", game.ClientKey).
+			Set("previous_client_key", game.PreviousClientKey).
+			Set("previous_client_key_expires_at", game.PreviousClientKeyExpiresAt).
+			Set("updated_at", game.UpdatedAt).
+			Where("id = $1", gameID).
+			Exec()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertAuditEvent(ctx, tx, gameID, "", AuditActionRotateKey, nil, map[string]interface{}{
+		"previousClientKeyExpiresAt": game.PreviousClientKeyExpiresAt,
+	}, mr)
+	if err != nil {
+		return nil, err
+	}
+	return &game, tx.Commit()
+}
+
+//RegisterRequestSignature records a signature seen for the game until expiresAt.
+//It returns false if the signature was already registered, meaning the request is a replay.
+func RegisterRequestSignature(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, signature string,
+	expiresAt time.Time,
+	mr *MixedMetricsReporter,
+) (bool, error) {
+	var registered int64
+	err := mr.WithDatastoreSegment("request_signatures", SegmentInsert, func() error {
+		builder := db.SQL(`
+			INSERT INTO request_signatures (game_id, signature, expires_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING`,
+			gameID, signature, expiresAt,
+		)
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		res, err := builder.Exec()
+		if err != nil {
+			return err
+		}
+		registered = res.RowsAffected
+		return nil
+	})
+	return registered > 0, err
+}
+
+//DeleteExpiredRequestSignatures removes the signatures that can no longer be replayed at time t
+func DeleteExpiredRequestSignatures(ctx context.Context, db runner.Connection, t time.Time, mr *MixedMetricsReporter) (int64, error) {
+	var deleted int64
+	err := mr.WithDatastoreSegment("request_signatures", SegmentDelete, func() error {
+		builder := db.DeleteFrom("request_signatures")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		res, err := builder.Where("expires_at < $1", t).Exec()
+		if err != nil {
+			return err
+		}
+		deleted = res.RowsAffected
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,97 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+)
+
+var _ = Describe("Client Key Model", func() {
+	currentTime := time.Unix(1486678000, 0)
+
+	Describe("Rotate game client key", func() {
+		It("should generate the first key without a previous one", func() {
+			game, err := models.RotateGameClientKey(nil, db, defaultGameID, currentTime, time.Hour, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(game.ClientKey).To(HaveLen(64))
+			Expect(game.PreviousClientKey).To(BeEmpty())
+			Expect(game.ClientKeysAt(currentTime)).To(Equal([]string{game.ClientKey}))
+
+			dbGame, err := models.GetGameByID(nil, db, defaultGameID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dbGame.ClientKey).To(Equal(game.ClientKey))
+		})
+
+		It("should keep the previous key during the grace period", func() {
+			first, err := models.RotateGameClientKey(nil, db, defaultGameID, currentTime, time.Hour, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			second, err := models.RotateGameClientKey(nil, db, defaultGameID, currentTime, time.Hour, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(second.ClientKey).NotTo(Equal(first.ClientKey))
+			Expect(second.PreviousClientKey).To(Equal(first.ClientKey))
+			Expect(second.ClientKeysAt(currentTime.Add(time.Minute))).To(Equal([]string{second.ClientKey, first.ClientKey}))
+			Expect(second.ClientKeysAt(currentTime.Add(time.Hour))).To(Equal([]string{second.ClientKey}))
+		})
+
+		It("should audit the rotation", func() {
+			_, err := models.RotateGameClientKey(nil, db, defaultGameID, currentTime, time.Hour, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			events, err := models.ListAuditEvents(nil, db, defaultGameID, "", time.Unix(0, 0), 10, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Action).To(Equal(models.AuditActionRotateKey))
+		})
+
+		It("should return error if game does not exist", func() {
+			expectedError := errors.NewModelNotFoundError("Game", map[string]interface{}{
+				"ID": "non-existing-game",
+			})
+
+			_, err := models.RotateGameClientKey(nil, db, "non-existing-game", currentTime, time.Hour, nil)
+
+			Expect(err).To(MatchError(expectedError))
+		})
+	})
+
+	Describe("Register request signature", func() {
+		It("should only register a signature once", func() {
+			signature := "4f2a6b1f4c7e8d9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a"
+
+			registered, err := models.RegisterRequestSignature(nil, db, defaultGameID, signature, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(registered).To(BeTrue())
+
+			registered, err = models.RegisterRequestSignature(nil, db, defaultGameID, signature, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(registered).To(BeFalse())
+		})
+
+		It("should delete expired signatures", func() {
+			signature := "4f2a6b1f4c7e8d9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a"
+			_, err := models.RegisterRequestSignature(nil, db, defaultGameID, signature, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			deleted, err := models.DeleteExpiredRequestSignatures(nil, db, currentTime.Add(time.Second), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(BeEquivalentTo(1))
+
+			registered, err := models.RegisterRequestSignature(nil, db, defaultGameID, signature, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(registered).To(BeTrue())
+		})
+	})
+})
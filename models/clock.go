@@ -16,3 +16,13 @@ type RealClock struct{}
 func (r RealClock) GetTime() time.Time {
 	return time.Now()
 }
+
+//FixedClock always returns the same time
+type FixedClock struct {
+	Time time.Time
+}
+
+//GetTime returns the fixed time
+func (f FixedClock) GetTime() time.Time {
+	return f.Time
+}
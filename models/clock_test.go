@@ -22,5 +22,10 @@ var _ = Describe("Clock", func() {
 			t := clock.GetTime()
 			Expect(time.Now().Unix() - t.Unix()).To(BeNumerically("<", 5))
 		})
+
+		It("should return the fixed time", func() {
+			clock := models.FixedClock{Time: time.Unix(1486678000, 0)}
+			Expect(clock.GetTime().Unix()).To(Equal(int64(1486678000)))
+		})
 	})
 })
@@ -30,3 +30,6 @@ const SegmentSelect = "Database/Select"
 
 //SegmentInsect represents a segment
 const SegmentInsect = "Database/Insect"
+
+//SegmentDelete represents a segment
+const SegmentDelete = "Database/Delete"
@@ -0,0 +1,128 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"time"
+
+	edat "github.com/topfreegames/extensions/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//Kinds of dedupe keys
+const (
+	DedupeKindImpression  = "impression"
+	DedupeKindTransaction = "transaction"
+)
+
+//DedupeKey is the impression or transaction id of a player request for an offer
+type DedupeKey struct {
+	GameID    string    `db:"game_id" json:"gameId"`
+	PlayerID  string    `db:"player_id" json:"playerId"`
+	OfferID   string    `db:"offer_id" json:"offerId"`
+	Kind      string    `db:"kind" json:"kind"`
+	Key       string    `db:"key" json:"key"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
+
+//registerDedupeKey records the impression or transaction id of a player request for an offer.
+//It returns false if the key was already registered, meaning the request is a replay.
+func registerDedupeKey(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, playerID, offerID, kind, key string,
+	t time.Time,
+	mr *MixedMetricsReporter,
+) (bool, error) {
+	var registered int64
+	err := mr.WithDatastoreSegment("dedupe_keys", SegmentInsert, func() error {
+		builder := db.SQL(`
+			INSERT INTO dedupe_keys (game_id, player_id, offer_id, kind, key, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT DO NOTHING`,
+			gameID, playerID, offerID, kind, key, t,
+		)
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		res, err := builder.Exec()
+		if err != nil {
+			return err
+		}
+		registered = res.RowsAffected
+		return nil
+	})
+	return registered > 0, err
+}
+
+//dedupeKeysLockSpace namespaces the advisory lock taken to delete expired dedupe keys
+const dedupeKeysLockSpace = 7002
+
+//DeleteExpiredDedupeKeys removes the dedupe keys registered before the given time, in batches of
+//at most batchSize keys, and returns how many were removed. Each batch runs in its own transaction
+//holding an advisory lock, so concurrent schedulers skip the cleanup instead of competing for the rows.
+//Requests replayed after their keys are removed are no longer detected.
+func DeleteExpiredDedupeKeys(
+	ctx context.Context,
+	db runner.Connection,
+	before time.Time,
+	batchSize uint64,
+	mr *MixedMetricsReporter,
+) (int64, error) {
+	var deleted int64
+	for {
+		batchDeleted, err := deleteExpiredDedupeKeysBatch(ctx, db, before, batchSize, mr)
+		deleted += batchDeleted
+		if err != nil || batchDeleted == 0 || batchDeleted < int64(batchSize) {
+			return deleted, err
+		}
+	}
+}
+
+func deleteExpiredDedupeKeysBatch(
+	ctx context.Context,
+	db runner.Connection,
+	before time.Time,
+	batchSize uint64,
+	mr *MixedMetricsReporter,
+) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.AutoRollback()
+
+	var locked bool
+	err = mr.WithDatastoreSegment("dedupe_keys", SegmentSelect, func() error {
+		return tx.SQL("SELECT pg_try_advisory_xact_lock($1, 0)", dedupeKeysLockSpace).
+			QueryScalar(&locked)
+	})
+	if err != nil || !locked {
+		return 0, err
+	}
+
+	var deleted int64
+	err = mr.WithDatastoreSegment("dedupe_keys", SegmentDelete, func() error {
+		builder := tx.SQL(`
+			DELETE FROM dedupe_keys WHERE ctid IN (
+				SELECT ctid FROM dedupe_keys WHERE created_at < $1 LIMIT $2
+			)`,
+			before, batchSize,
+		)
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		res, err := builder.Exec()
+		if err != nil {
+			return err
+		}
+		deleted = res.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, tx.Commit()
+}
@@ -0,0 +1,96 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	uuid "github.com/satori/go.uuid"
+	"github.com/topfreegames/offers/models"
+)
+
+var _ = Describe("Dedupe Key Model", func() {
+	currentTime := time.Unix(1486678000, 0)
+	offerInstanceID := "eb7e8d2a-2739-4da3-aa31-7970b63bdad7"
+	offerID := "dd21ec96-2890-4ba0-b8e2-40ea67196990"
+	playerID := "dedupe-player"
+
+	It("should detect replayed impressions inside the retention window", func() {
+		impressionID := uuid.NewV4().String()
+		isReplay, _, err := models.ViewOffer(nil, db, offersCache, defaultGameID, offerInstanceID, playerID, impressionID, currentTime, cacheExpireDuration, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(isReplay).To(BeFalse())
+
+		_, err = models.DeleteExpiredDedupeKeys(nil, db, currentTime, 100, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		isReplay, _, err = models.ViewOffer(nil, db, offersCache, defaultGameID, offerInstanceID, playerID, impressionID, currentTime.Add(time.Hour), cacheExpireDuration, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(isReplay).To(BeTrue())
+
+		offerPlayer, err := models.GetOfferPlayer(nil, db, defaultGameID, playerID, offerID, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offerPlayer.ViewCounter).To(Equal(1))
+	})
+
+	It("should forget impressions older than the retention window", func() {
+		impressionID := uuid.NewV4().String()
+		_, _, err := models.ViewOffer(nil, db, offersCache, defaultGameID, offerInstanceID, playerID, impressionID, currentTime, cacheExpireDuration, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		deleted, err := models.DeleteExpiredDedupeKeys(nil, db, currentTime.Add(time.Second), 100, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(BeNumerically(">=", 1))
+
+		isReplay, _, err := models.ViewOffer(nil, db, offersCache, defaultGameID, offerInstanceID, playerID, impressionID, currentTime.Add(time.Hour), cacheExpireDuration, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(isReplay).To(BeFalse())
+
+		offerPlayer, err := models.GetOfferPlayer(nil, db, defaultGameID, playerID, offerID, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offerPlayer.ViewCounter).To(Equal(2))
+	})
+
+	It("should forget every expired impression when deleting in small batches", func() {
+		impressionIDs := []string{uuid.NewV4().String(), uuid.NewV4().String(), uuid.NewV4().String()}
+		for _, impressionID := range impressionIDs {
+			_, _, err := models.ViewOffer(nil, db, offersCache, defaultGameID, offerInstanceID, playerID, impressionID, currentTime, cacheExpireDuration, nil)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		deleted, err := models.DeleteExpiredDedupeKeys(nil, db, currentTime.Add(time.Second), 1, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(BeNumerically(">=", len(impressionIDs)))
+
+		for _, impressionID := range impressionIDs {
+			isReplay, _, err := models.ViewOffer(nil, db, offersCache, defaultGameID, offerInstanceID, playerID, impressionID, currentTime.Add(time.Hour), cacheExpireDuration, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isReplay).To(BeFalse())
+		}
+	})
+
+	It("should detect replayed claims by the ledger after their keys expire", func() {
+		transactionID := uuid.NewV4().String()
+		_, alreadyClaimed, _, err := models.ClaimOffer(nil, db, defaultGameID, offerInstanceID, playerID, "", transactionID, currentTime.Unix(), currentTime, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(alreadyClaimed).To(BeFalse())
+
+		_, err = models.DeleteExpiredDedupeKeys(nil, db, currentTime.Add(time.Second), 100, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, alreadyClaimed, _, err = models.ClaimOffer(nil, db, defaultGameID, offerInstanceID, playerID, "", transactionID, currentTime.Unix(), currentTime.Add(time.Hour), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(alreadyClaimed).To(BeTrue())
+
+		offerPlayer, err := models.GetOfferPlayer(nil, db, defaultGameID, playerID, offerID, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offerPlayer.ClaimCounter).To(Equal(1))
+	})
+})
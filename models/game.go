@@ -9,9 +9,12 @@ package models
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	edat "github.com/topfreegames/extensions/dat"
+	"github.com/topfreegames/offers/errors"
 	dat "gopkg.in/mgutz/dat.v2/dat"
 	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
 )
@@ -22,6 +25,11 @@ type Game struct {
 	Name     string   `db:"name" json:"name" valid:"ascii,stringlength(1|255),required"`
 	Metadata dat.JSON `db:"metadata" json:"metadata" valid:"JSONObject"`
 
+	//Client keys sign the requests of the player facing routes, see RotateGameClientKey
+	ClientKey                  string       `db:"client_key" json:"-" valid:"optional"`
+	PreviousClientKey          string       `db:"previous_client_key" json:"-" valid:"optional"`
+	PreviousClientKeyExpiresAt dat.NullTime `db:"previous_client_key_expires_at" json:"-" valid:"optional"`
+
 	//TODO: Validate dates
 	CreatedAt dat.NullTime `db:"created_at" json:"createdAt" valid:""`
 	UpdatedAt dat.NullTime `db:"updated_at" json:"updatedAt" valid:""`
@@ -34,6 +42,56 @@ func (g *Game) GetMetadata() (map[string]interface{}, error) {
 	return obj, err
 }
 
+//RequirePublishApproval returns true if requirePublishApproval is set in the game metadata,
+//in which case offer changes are saved as drafts that someone other than their author must publish
+func (g *Game) RequirePublishApproval() (bool, error) {
+	metadata, err := g.GetMetadata()
+	if err != nil {
+		return false, err
+	}
+	requireApproval, _ := metadata["requirePublishApproval"].(bool)
+	return requireApproval, nil
+}
+
+//GetImpressionCap returns the impression cap of the game, read from the impressionCap object
+//of the game metadata, or nil if the game has no valid impression cap
+func (g *Game) GetImpressionCap() (*ImpressionCap, error) {
+	metadata, err := g.GetMetadata()
+	if err != nil {
+		return nil, err
+	}
+	obj, _ := metadata["impressionCap"].(map[string]interface{})
+	every, _ := obj["every"].(string)
+	max, _ := obj["max"].(float64)
+	impressionCap := &ImpressionCap{Every: every, Max: int(max)}
+	if max != float64(int(max)) || impressionCap.validate() != nil {
+		return nil, nil
+	}
+	return impressionCap, nil
+}
+
+//validateImpressionCap returns an InvalidModelError if the impressionCap of the game metadata
+//is not an object with a positive every and a max of at least 1
+func (g *Game) validateImpressionCap() error {
+	var metadata map[string]json.RawMessage
+	if err := g.Metadata.Unmarshal(&metadata); err != nil {
+		return nil
+	}
+	raw, ok := metadata["impressionCap"]
+	if !ok || string(raw) == "null" {
+		return nil
+	}
+	impressionCap := &ImpressionCap{}
+	err := json.Unmarshal(raw, impressionCap)
+	if err == nil {
+		err = impressionCap.validate()
+	}
+	if err != nil {
+		return errors.NewInvalidModelError("Game", fmt.Sprintf("invalid impression cap: %s", err.Error()))
+	}
+	return nil
+}
+
 //GetGameByID returns a game by it's pk
 func GetGameByID(ctx context.Context, db runner.Connection, id string, mr *MixedMetricsReporter) (*Game, error) {
 	var game Game
@@ -61,14 +119,42 @@ func ListGames(ctx context.Context, db runner.Connection, mr *MixedMetricsReport
 	return games, err
 }
 
-//UpsertGame updates a game with new meta or insert with the new UUID
+//UpsertGame updates a game with new meta or insert with the new UUID.
+//It returns an InvalidModelError if the impression cap of the metadata is malformed.
 func UpsertGame(ctx context.Context, db runner.Connection, game *Game, t time.Time, mr *MixedMetricsReporter) error {
 	if game.Metadata == nil {
 		game.Metadata = dat.JSON([]byte(`{}`))
 	}
+	err := game.validateImpressionCap()
+	if err != nil {
+		return err
+	}
 	game.UpdatedAt = dat.NullTimeFrom(t)
-	return mr.WithDatastoreSegment("games", SegmentUpsert, func() error {
-		builder := db.Upsert("games")
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.AutoRollback()
+
+	var prevGame *Game
+	prevGames := []*Game{}
+	err = mr.WithDatastoreSegment("games", SegmentSelect, func() error {
+		builder := tx.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("games").
+			Where("id = $1", game.ID).
+			QueryStructs(&prevGames)
+	})
+	if err != nil {
+		return err
+	}
+	if len(prevGames) > 0 {
+		prevGame = prevGames[0]
+	}
+
+	err = mr.WithDatastoreSegment("games", SegmentUpsert, func() error {
+		builder := tx.Upsert("games")
 		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
 		return builder.Columns("id", "name", "updated_at", "metadata").
 			Record(game).
@@ -76,4 +162,13 @@ func UpsertGame(ctx context.Context, db runner.Connection, game *Game, t time.Ti
 			Returning("created_at", "updated_at").
 			QueryStruct(game)
 	})
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEvent(ctx, tx, game.ID, "", AuditActionUpsertGame, prevGame, game, mr)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
 }
@@ -0,0 +1,172 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"time"
+
+	edat "github.com/topfreegames/extensions/dat"
+	"gopkg.in/mgutz/dat.v2/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//Roles a principal can have in a game, from the least to the most privileged
+const (
+	RoleViewer    = "viewer"
+	RoleEditor    = "editor"
+	RolePublisher = "publisher"
+	RoleAdmin     = "admin"
+)
+
+var roleRanks = map[string]int{
+	RoleViewer:    1,
+	RoleEditor:    2,
+	RolePublisher: 3,
+	RoleAdmin:     4,
+}
+
+//RoleAllows returns true if role grants at least the permissions of required
+func RoleAllows(role, required string) bool {
+	rank, ok := roleRanks[role]
+	return ok && rank >= roleRanks[required]
+}
+
+//GameRole grants a principal (user email or API key) a role in a game
+type GameRole struct {
+	ID        string       `db:"id" json:"id" valid:"optional"`
+	GameID    string       `db:"game_id" json:"gameId" valid:"optional"`
+	Principal string       `db:"principal" json:"principal" valid:"stringlength(1|255),required"`
+	Role      string       `db:"role" json:"role" valid:"matches(^(viewer|editor|publisher|admin)$),required"`
+	CreatedAt dat.NullTime `db:"created_at" json:"createdAt" valid:"optional"`
+	UpdatedAt dat.NullTime `db:"updated_at" json:"updatedAt" valid:"optional"`
+}
+
+//GetGameRole returns the role of a principal in a game
+func GetGameRole(ctx context.Context, db runner.Connection, gameID, principal string, mr *MixedMetricsReporter) (*GameRole, error) {
+	var role GameRole
+	err := mr.WithDatastoreSegment("game_roles", SegmentSelect, func() error {
+		builder := db.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("game_roles").
+			Where("game_id = $1 AND principal = $2", gameID, principal).
+			QueryStruct(&role)
+	})
+
+	err = handleNotFoundError("GameRole", map[string]interface{}{
+		"GameID":    gameID,
+		"Principal": principal,
+	}, err)
+	return &role, err
+}
+
+//ListGameRoles returns the roles granted in a game
+func ListGameRoles(ctx context.Context, db runner.Connection, gameID string, mr *MixedMetricsReporter) ([]*GameRole, error) {
+	roles := []*GameRole{}
+	err := mr.WithDatastoreSegment("game_roles", SegmentSelect, func() error {
+		builder := db.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("game_roles").
+			Where("game_id = $1", gameID).
+			OrderBy("principal").
+			QueryStructs(&roles)
+	})
+	return roles, err
+}
+
+//ListPrincipalRoles returns the roles granted to a principal in every game
+func ListPrincipalRoles(ctx context.Context, db runner.Connection, principal string, mr *MixedMetricsReporter) ([]*GameRole, error) {
+	roles := []*GameRole{}
+	err := mr.WithDatastoreSegment("game_roles", SegmentSelect, func() error {
+		builder := db.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("game_roles").
+			Where("principal = $1", principal).
+			OrderBy("game_id").
+			QueryStructs(&roles)
+	})
+	return roles, err
+}
+
+//UpsertGameRole grants a role to a principal in a game, replacing its previous role
+func UpsertGameRole(ctx context.Context, db runner.Connection, role *GameRole, t time.Time, mr *MixedMetricsReporter) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.AutoRollback()
+
+	var prevRole *GameRole
+	prevRoles := []*GameRole{}
+	err = mr.WithDatastoreSegment("game_roles", SegmentSelect, func() error {
+		builder := tx.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("game_roles").
+			Where("game_id = $1 AND principal = $2", role.GameID, role.Principal).
+			QueryStructs(&prevRoles)
+	})
+	if err != nil {
+		return err
+	}
+	if len(prevRoles) > 0 {
+		prevRole = prevRoles[0]
+	}
+
+	role.UpdatedAt = dat.NullTimeFrom(t)
+	err = mr.WithDatastoreSegment("game_roles", SegmentUpsert, func() error {
+		builder := tx.Upsert("game_roles")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.Columns("game_id", "principal", "role", "updated_at").
+			Record(role).
+			Where("game_id = $1 AND principal = $2", role.GameID, role.Principal).
+			Returning("id", "created_at", "updated_at").
+			QueryStruct(role)
+	})
+	err = handleForeignKeyViolationError("GameRole", err)
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEvent(ctx, tx, role.GameID, "", AuditActionUpsertRole, prevRole, role, mr)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+//DeleteGameRole revokes the role of a principal in a game
+func DeleteGameRole(ctx context.Context, db runner.Connection, gameID, principal string, mr *MixedMetricsReporter) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.AutoRollback()
+
+	var role GameRole
+	err = mr.WithDatastoreSegment("game_roles", SegmentDelete, func() error {
+		builder := tx.DeleteFrom("game_roles")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.
+			Where("game_id = $1 AND principal = $2", gameID, principal).
+			Returning("*").
+			QueryStruct(&role)
+	})
+	err = handleNotFoundError("GameRole", map[string]interface{}{
+		"GameID":    gameID,
+		"Principal": principal,
+	}, err)
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEvent(ctx, tx, gameID, "", AuditActionDeleteRole, &role, nil, mr)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
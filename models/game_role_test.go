@@ -0,0 +1,105 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+)
+
+var _ = Describe("Game Role Model", func() {
+	currentTime := time.Unix(1486678000, 0)
+
+	Describe("Role allows", func() {
+		It("should allow roles at least as privileged as the required one", func() {
+			Expect(models.RoleAllows(models.RoleAdmin, models.RoleViewer)).To(BeTrue())
+			Expect(models.RoleAllows(models.RolePublisher, models.RolePublisher)).To(BeTrue())
+			Expect(models.RoleAllows(models.RoleEditor, models.RolePublisher)).To(BeFalse())
+			Expect(models.RoleAllows("owner", models.RoleViewer)).To(BeFalse())
+		})
+	})
+
+	Describe("Upsert game role", func() {
+		It("should grant and then replace a role", func() {
+			role := &models.GameRole{GameID: defaultGameID, Principal: "user@tfgco.com", Role: models.RoleViewer}
+			err := models.UpsertGameRole(nil, db, role, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(role.ID).NotTo(BeEmpty())
+
+			role = &models.GameRole{GameID: defaultGameID, Principal: "user@tfgco.com", Role: models.RoleEditor}
+			err = models.UpsertGameRole(nil, db, role, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			dbRole, err := models.GetGameRole(nil, db, defaultGameID, "user@tfgco.com", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dbRole.Role).To(Equal(models.RoleEditor))
+
+			roles, err := models.ListGameRoles(nil, db, defaultGameID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roles).To(HaveLen(1))
+
+			events, err := models.ListAuditEvents(nil, db, defaultGameID, "", time.Unix(0, 0), 10, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(2))
+			Expect(events[1].Action).To(Equal(models.AuditActionUpsertRole))
+		})
+
+		It("should return error if game does not exist", func() {
+			role := &models.GameRole{GameID: "non-existing-game", Principal: "user@tfgco.com", Role: models.RoleViewer}
+
+			err := models.UpsertGameRole(nil, db, role, currentTime, nil)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.InvalidModelError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Describe("List principal roles", func() {
+		It("should return the roles of a principal in every game", func() {
+			err := models.UpsertGameRole(nil, db, &models.GameRole{GameID: defaultGameID, Principal: "user@tfgco.com", Role: models.RoleViewer}, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+			err = models.UpsertGameRole(nil, db, &models.GameRole{GameID: defaultGameID, Principal: "other@tfgco.com", Role: models.RoleAdmin}, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			roles, err := models.ListPrincipalRoles(nil, db, "user@tfgco.com", nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roles).To(HaveLen(1))
+			Expect(roles[0].GameID).To(Equal(defaultGameID))
+		})
+	})
+
+	Describe("Delete game role", func() {
+		It("should revoke a role", func() {
+			err := models.UpsertGameRole(nil, db, &models.GameRole{GameID: defaultGameID, Principal: "user@tfgco.com", Role: models.RoleViewer}, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = models.DeleteGameRole(nil, db, defaultGameID, "user@tfgco.com", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = models.GetGameRole(nil, db, defaultGameID, "user@tfgco.com", nil)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should return error if principal has no role", func() {
+			expectedError := errors.NewModelNotFoundError("GameRole", map[string]interface{}{
+				"GameID":    defaultGameID,
+				"Principal": "user@tfgco.com",
+			})
+
+			err := models.DeleteGameRole(nil, db, defaultGameID, "user@tfgco.com", nil)
+
+			Expect(err).To(MatchError(expectedError))
+		})
+	})
+})
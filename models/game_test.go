@@ -168,6 +168,22 @@ var _ = Describe("Games Model", func() {
 			Expect(gameFromDB.Name).To(Equal(name))
 		})
 
+		It("should return error if the impression cap of the metadata is invalid", func() {
+			game := models.Game{
+				ID:       uuid.NewV4().String(),
+				Name:     "game-name",
+				Metadata: dat.JSON([]byte(`{"impressionCap": {"every": "forever", "max": 1}}`)),
+			}
+
+			var c models.RealClock
+
+			err := models.UpsertGame(nil, db, &game, c.GetTime(), nil)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.InvalidModelError)
+			Expect(ok).To(BeTrue())
+		})
+
 		It("should return error when inserting game with very big name", func() {
 			//Given
 			id := uuid.NewV4().String()
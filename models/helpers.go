@@ -29,6 +29,25 @@ type ClaimOfferPayload struct {
 	Timestamp       int64  `json:"timestamp" valid:"int64,required"`
 	TransactionID   string `json:"transactionId" valid:"ascii,stringlength(1|1000),required"`
 	OfferInstanceID string `json:"id" valid:"uuidv4,optional"`
+	Token           string `json:"token" valid:"optional"`
+	Store           string `json:"store" valid:"optional"`
+	Receipt         string `json:"receipt" valid:"optional"`
+}
+
+//RevokeClaimPayload has required fields for revoking a claim
+type RevokeClaimPayload struct {
+	GameID             string `json:"gameId" valid:"matches(^[^-][a-zA-Z0-9-_]*$),stringlength(1|255),required"`
+	PlayerID           string `json:"playerId" valid:"ascii,stringlength(1|1000),optional"`
+	TransactionID      string `json:"transactionId" valid:"ascii,stringlength(1|1000),required"`
+	Reason             string `json:"reason" valid:"stringlength(0|255),optional"`
+	RestoreEligibility bool   `json:"restoreEligibility" valid:"optional"`
+}
+
+//MergePlayersPayload has required fields for merging the state of a player into another
+type MergePlayersPayload struct {
+	GameID       string `json:"gameId" valid:"matches(^[^-][a-zA-Z0-9-_]*$),stringlength(1|255),required"`
+	FromPlayerID string `json:"fromPlayerId" valid:"ascii,stringlength(1|1000),required"`
+	ToPlayerID   string `json:"toPlayerId" valid:"ascii,stringlength(1|1000),required"`
 }
 
 //OfferImpressionPayload has required fields for an offer impression
@@ -36,6 +55,26 @@ type OfferImpressionPayload struct {
 	GameID       string `json:"gameId" valid:"matches(^[^-][a-zA-Z0-9-_]*$),stringlength(1|255),required"`
 	PlayerID     string `json:"playerId" valid:"ascii,stringlength(1|1000),required"`
 	ImpressionID string `json:"impressionId" valid:"uuidv4,required"`
+	Token        string `json:"token" valid:"optional"`
+}
+
+//PreviewOffersPayload has the fields to preview the available offers of a game at a given time
+type PreviewOffersPayload struct {
+	GameID       string                  `json:"gameId" valid:"matches(^[^-][a-zA-Z0-9-_]*$),stringlength(1|255),required"`
+	PlayerID     string                  `json:"playerId" valid:"ascii,stringlength(1|1000),optional"`
+	At           int64                   `json:"at" valid:"int64,required"`
+	Attributes   map[string]string       `json:"attributes" valid:"optional"`
+	OfferPlayers []*SimulatedOfferPlayer `json:"offerPlayers" valid:"optional"`
+}
+
+//SimulatedOfferPlayer is the state of an offer for the hypothetical player of a preview
+type SimulatedOfferPlayer struct {
+	OfferID            string `json:"offerId" valid:"uuidv4,required"`
+	ViewCounter        int    `json:"viewCounter" valid:"optional"`
+	ViewTimestamp      int64  `json:"viewTimestamp" valid:"optional"`
+	FirstViewTimestamp int64  `json:"firstViewTimestamp" valid:"optional"`
+	ClaimCounter       int    `json:"claimCounter" valid:"optional"`
+	ClaimTimestamp     int64  `json:"claimTimestamp" valid:"optional"`
 }
 
 //GetEnabledOffersKey returns the key of the current enabled offers
@@ -43,6 +82,21 @@ func GetEnabledOffersKey(gameID string) string {
 	return fmt.Sprintf("offers:enabled:%s", gameID)
 }
 
+//GetPlacementsKey returns the key of the placements of a game
+func GetPlacementsKey(gameID string) string {
+	return fmt.Sprintf("placements:%s", gameID)
+}
+
+//GetImpressionCapKey returns the key of the impression cap of a game
+func GetImpressionCapKey(gameID string) string {
+	return fmt.Sprintf("impression-cap:%s", gameID)
+}
+
+//GetTestPlayersKey returns the key of the test players of a game
+func GetTestPlayersKey(gameID string) string {
+	return fmt.Sprintf("test-players:%s", gameID)
+}
+
 //GetDB Connection using the given properties
 func GetDB(
 	host string, user string, port int, sslmode string,
@@ -100,6 +154,18 @@ func IsForeignKeyViolationError(err error) (*pq.Error, bool) {
 	return pqErr, pqErr.Code == "23503" && strings.Contains(pqErr.Message, "violates foreign key constraint")
 }
 
+//IsUniqueViolationError returns true if the error is a pq error stating a unique constraint has been violated
+func IsUniqueViolationError(err error) (*pq.Error, bool) {
+	var pqErr *pq.Error
+	var ok bool
+
+	if pqErr, ok = err.(*pq.Error); !ok {
+		return nil, false
+	}
+
+	return pqErr, pqErr.Code == "23505"
+}
+
 //ShouldPing the database
 func ShouldPing(db *sql.DB, timeout time.Duration) error {
 	var err error
@@ -132,6 +198,16 @@ func handleNotFoundError(model string, filters map[string]interface{}, err error
 	return nil
 }
 
+func handleUniqueViolationError(model string, err error) error {
+	if err != nil {
+		if pqErr, ok := IsUniqueViolationError(err); ok {
+			return errors.NewConflictedModelError(model, pqErr.Message)
+		}
+		return err
+	}
+	return nil
+}
+
 func handleForeignKeyViolationError(model string, err error) error {
 	if err != nil {
 		if pqErr, ok := IsForeignKeyViolationError(err); ok {
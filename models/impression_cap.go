@@ -0,0 +1,278 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pmylund/go-cache"
+	edat "github.com/topfreegames/extensions/dat"
+	"gopkg.in/mgutz/dat.v2/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//ImpressionCap limits how many impressions of any offer of a game, or of a placement, a player
+//has in a window of time. The window starts at the first impression after the previous one ended.
+type ImpressionCap struct {
+	Every string `json:"every"`
+	Max   int    `json:"max"`
+}
+
+func (c *ImpressionCap) validate() error {
+	duration, err := time.ParseDuration(c.Every)
+	if err != nil {
+		return err
+	}
+	if duration <= 0 {
+		return fmt.Errorf("every must be positive")
+	}
+	if c.Max < 1 {
+		return fmt.Errorf("max must be at least 1")
+	}
+	return nil
+}
+
+//PlayerImpressions counts the impressions of a player in the current window of an impression cap.
+//Placement is empty for the impression cap of the game.
+type PlayerImpressions struct {
+	GameID      string       `db:"game_id" json:"gameId"`
+	PlayerID    string       `db:"player_id" json:"playerId"`
+	Placement   string       `db:"placement" json:"placement"`
+	WindowStart dat.NullTime `db:"window_start" json:"windowStart"`
+	Counter     int          `db:"counter" json:"counter"`
+}
+
+//ImpressionCapState is the state of an impression cap for a player at a time.
+//Placement is empty for the impression cap of the game.
+type ImpressionCapState struct {
+	Placement string `json:"placement"`
+	Every     string `json:"every"`
+	Max       int    `json:"max"`
+	Count     int    `json:"count"`
+	Reached   bool   `json:"reached"`
+	ResetAt   int64  `json:"resetAt,omitempty"`
+}
+
+//GetImpressionCapStates returns the state at time t of the impression caps of the game and of its placements for a player
+func GetImpressionCapStates(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, playerID string,
+	t time.Time,
+	mr *MixedMetricsReporter,
+) ([]*ImpressionCapState, error) {
+	placements, err := ListPlacements(ctx, db, gameID, mr)
+	if err != nil {
+		return nil, err
+	}
+	impressionCaps, err := getImpressionCaps(ctx, db, gameID, placements, nil, 0, mr)
+	if err != nil {
+		return nil, err
+	}
+	playerImpressions, err := getPlayerImpressions(ctx, db, gameID, playerID, mr)
+	if err != nil {
+		return nil, err
+	}
+	return impressionCapStates(impressionCaps, playerImpressions, t), nil
+}
+
+//getGameImpressionCap returns the impression cap of a game, or nil if it has none,
+//from offersCache, if it is not nil, or from the database
+func getGameImpressionCap(
+	ctx context.Context,
+	db runner.Connection,
+	gameID string,
+	offersCache *cache.Cache,
+	expireDuration time.Duration,
+	mr *MixedMetricsReporter,
+) (*ImpressionCap, error) {
+	impressionCapKey := GetImpressionCapKey(gameID)
+	if offersCache != nil {
+		if impressionCap, found := offersCache.Get(impressionCapKey); found {
+			return impressionCap.(*ImpressionCap), nil
+		}
+	}
+	game, err := GetGameByID(ctx, db, gameID, mr)
+	if err != nil {
+		return nil, err
+	}
+	impressionCap, err := game.GetImpressionCap()
+	if err != nil {
+		return nil, err
+	}
+	if offersCache != nil {
+		offersCache.Set(impressionCapKey, impressionCap, expireDuration)
+	}
+	return impressionCap, nil
+}
+
+//getImpressionCaps returns the impression caps of a game by placement, with the cap of the game at the empty placement.
+//The cap of the game is read from offersCache, if it is not nil.
+func getImpressionCaps(
+	ctx context.Context,
+	db runner.Connection,
+	gameID string,
+	placements []*Placement,
+	offersCache *cache.Cache,
+	expireDuration time.Duration,
+	mr *MixedMetricsReporter,
+) (map[string]*ImpressionCap, error) {
+	impressionCaps := map[string]*ImpressionCap{}
+	gameImpressionCap, err := getGameImpressionCap(ctx, db, gameID, offersCache, expireDuration, mr)
+	if err != nil {
+		return nil, err
+	}
+	if gameImpressionCap != nil {
+		impressionCaps[""] = gameImpressionCap
+	}
+	for _, placement := range placements {
+		placementImpressionCap, err := placement.getImpressionCap()
+		if err != nil {
+			return nil, err
+		}
+		if placementImpressionCap != nil {
+			impressionCaps[placement.Name] = placementImpressionCap
+		}
+	}
+	return impressionCaps, nil
+}
+
+func getPlayerImpressions(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, playerID string,
+	mr *MixedMetricsReporter,
+) ([]*PlayerImpressions, error) {
+	playerImpressions := []*PlayerImpressions{}
+	err := mr.WithDatastoreSegment("player_impressions", SegmentSelect, func() error {
+		builder := db.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("player_impressions").
+			Where("game_id = $1 AND player_id = $2", gameID, playerID).
+			OrderBy("placement").
+			QueryStructs(&playerImpressions)
+	})
+	return playerImpressions, err
+}
+
+//impressionCapStates returns the state of the impression caps at time t, the cap of the game first.
+//Impressions of a window that ended at time t are not counted.
+func impressionCapStates(
+	impressionCaps map[string]*ImpressionCap,
+	playerImpressions []*PlayerImpressions,
+	t time.Time,
+) []*ImpressionCapState {
+	impressionsByPlacement := map[string]*PlayerImpressions{}
+	for _, impressions := range playerImpressions {
+		impressionsByPlacement[impressions.Placement] = impressions
+	}
+
+	states := []*ImpressionCapState{}
+	for placement, impressionCap := range impressionCaps {
+		state := &ImpressionCapState{
+			Placement: placement,
+			Every:     impressionCap.Every,
+			Max:       impressionCap.Max,
+		}
+		duration, _ := time.ParseDuration(impressionCap.Every)
+		if impressions, ok := impressionsByPlacement[placement]; ok {
+			resetAt := impressions.WindowStart.Time.Add(duration)
+			if resetAt.After(t) {
+				state.Count = impressions.Counter
+				state.ResetAt = resetAt.Unix()
+			}
+		}
+		state.Reached = state.Count >= state.Max
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Placement < states[j].Placement
+	})
+	return states
+}
+
+//applyImpressionCaps removes every placement if the impression cap of the game is reached,
+//or else the placements whose impression caps are reached
+func applyImpressionCaps(offersByPlacement map[string][]*OfferToReturn, states []*ImpressionCapState) {
+	for _, state := range states {
+		if !state.Reached {
+			continue
+		}
+		if state.Placement == "" {
+			for placement := range offersByPlacement {
+				delete(offersByPlacement, placement)
+			}
+			return
+		}
+		delete(offersByPlacement, state.Placement)
+	}
+}
+
+//reachedImpressionCapsByPlacement returns the reached impression caps by placement,
+//with the cap of the game at the empty placement
+func reachedImpressionCapsByPlacement(states []*ImpressionCapState) map[string]*ImpressionCapState {
+	reached := map[string]*ImpressionCapState{}
+	for _, state := range states {
+		if state.Reached {
+			reached[state.Placement] = state
+		}
+	}
+	return reached
+}
+
+//countImpression counts an impression of an offer of a placement in the impression caps of
+//the game and of the placement. Nothing is stored for caps that are not configured.
+//The caps are read from offersCache, if it is not nil.
+func countImpression(
+	ctx context.Context,
+	db runner.Connection,
+	offersCache *cache.Cache,
+	gameID, playerID, placement string,
+	t time.Time,
+	expireDuration time.Duration,
+	mr *MixedMetricsReporter,
+) error {
+	placements, err := getPlacements(ctx, db, gameID, offersCache, expireDuration, mr)
+	if err != nil {
+		return err
+	}
+	impressionCaps, err := getImpressionCaps(ctx, db, gameID, placements, offersCache, expireDuration, mr)
+	if err != nil {
+		return err
+	}
+
+	for _, scope := range []string{"", placement} {
+		impressionCap, ok := impressionCaps[scope]
+		if !ok {
+			continue
+		}
+		duration, _ := time.ParseDuration(impressionCap.Every)
+		err = mr.WithDatastoreSegment("player_impressions", SegmentUpsert, func() error {
+			builder := db.SQL(`
+				INSERT INTO player_impressions (game_id, player_id, placement, window_start, counter)
+				VALUES ($1, $2, $3, $4, 1)
+				ON CONFLICT (game_id, player_id, placement) DO UPDATE SET
+					window_start = CASE WHEN player_impressions.window_start + $5 * INTERVAL '1 second' <= $4
+						THEN $4 ELSE player_impressions.window_start END,
+					counter = CASE WHEN player_impressions.window_start + $5 * INTERVAL '1 second' <= $4
+						THEN 1 ELSE player_impressions.counter + 1 END`,
+				gameID, playerID, scope, t, duration.Seconds(),
+			)
+			builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+			_, err := builder.Exec()
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+	"gopkg.in/mgutz/dat.v2/dat"
+)
+
+var _ = Describe("Impression Cap Model", func() {
+	currentTime := time.Unix(1486678000, 0)
+	expireDuration := 300 * time.Second
+	// the popup offer can be seen again one second after it is viewed
+	nextSecond := currentTime.Add(time.Second)
+	playerID := "capped-player"
+	popupOfferInstanceID := "56fc0477-39f1-485c-898e-4909e9155eb1"
+
+	upsertPlacement := func(name, impressionCap string) {
+		err := models.UpsertPlacement(nil, db, &models.Placement{
+			GameID:        defaultGameID,
+			Name:          name,
+			ImpressionCap: dat.JSON([]byte(impressionCap)),
+		}, offersCache, currentTime, nil)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	setGameImpressionCap := func(impressionCap string) {
+		game, err := models.GetGameByID(nil, db, defaultGameID, nil)
+		Expect(err).NotTo(HaveOccurred())
+		game.Metadata = dat.JSON([]byte(`{"impressionCap": ` + impressionCap + `}`))
+		err = models.UpsertGame(nil, db, game, currentTime, nil)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	viewPopup := func(impressionID string) {
+		_, _, err := models.ViewOffer(nil, db, offersCache, defaultGameID, popupOfferInstanceID, playerID, impressionID, currentTime, cacheExpireDuration, nil)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	getAvailableOffers := func(t time.Time) map[string][]*models.OfferToReturn {
+		offers, err := models.GetAvailableOffers(nil, db, offersCache, defaultGameID, playerID, t, expireDuration, map[string]string{}, false, nil)
+		Expect(err).NotTo(HaveOccurred())
+		return offers
+	}
+
+	Describe("Placement impression cap", func() {
+		It("should remove the placement once the player reached its cap", func() {
+			upsertPlacement("popup", `{"every": "1h", "max": 2}`)
+			upsertPlacement("store", `{}`)
+
+			viewPopup("impression-1")
+			Expect(getAvailableOffers(nextSecond)["popup"]).NotTo(BeEmpty())
+
+			viewPopup("impression-2")
+			offers := getAvailableOffers(nextSecond)
+			Expect(offers["popup"]).To(BeEmpty())
+			Expect(offers["store"]).NotTo(BeEmpty())
+
+			states, err := models.GetImpressionCapStates(nil, db, defaultGameID, playerID, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(states).To(HaveLen(1))
+			Expect(states[0].Placement).To(Equal("popup"))
+			Expect(states[0].Count).To(Equal(2))
+			Expect(states[0].Reached).To(BeTrue())
+			Expect(states[0].ResetAt).To(Equal(currentTime.Add(time.Hour).Unix()))
+		})
+
+		It("should not count replayed impressions", func() {
+			upsertPlacement("popup", `{"every": "1h", "max": 2}`)
+
+			viewPopup("impression-1")
+			viewPopup("impression-1")
+
+			Expect(getAvailableOffers(nextSecond)["popup"]).NotTo(BeEmpty())
+		})
+
+		It("should return the placement once the window ends", func() {
+			upsertPlacement("popup", `{"every": "1h", "max": 1}`)
+			viewPopup("impression-1")
+
+			states, err := models.GetImpressionCapStates(nil, db, defaultGameID, playerID, currentTime.Add(time.Hour), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(states[0].Count).To(Equal(0))
+			Expect(states[0].Reached).To(BeFalse())
+		})
+
+		It("should return error if the impression cap is invalid", func() {
+			err := models.UpsertPlacement(nil, db, &models.Placement{
+				GameID:        defaultGameID,
+				Name:          "popup",
+				ImpressionCap: dat.JSON([]byte(`{"every": "1h", "max": 0}`)),
+			}, offersCache, currentTime, nil)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.InvalidModelError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Describe("Game impression cap", func() {
+		It("should remove every placement once the player reached the cap of the game", func() {
+			setGameImpressionCap(`{"every": "6h", "max": 1}`)
+			Expect(getAvailableOffers(nextSecond)["store"]).NotTo(BeEmpty())
+
+			viewPopup("impression-1")
+
+			Expect(getAvailableOffers(nextSecond)).To(BeEmpty())
+		})
+
+		It("should explain the offers removed by the cap", func() {
+			setGameImpressionCap(`{"every": "6h", "max": 1}`)
+			viewPopup("impression-1")
+
+			explanations, err := models.ExplainAvailableOffers(nil, db, defaultGameID, playerID, currentTime, map[string]string{}, false, nil)
+			Expect(err).NotTo(HaveOccurred())
+			for _, explanation := range explanations {
+				Expect(explanation.Included).To(BeFalse())
+				if explanation.Placement == "store" {
+					Expect(explanation.Rule).To(Equal(models.UnavailableImpressionCap))
+					Expect(explanation.Until).To(Equal(currentTime.Add(6 * time.Hour).Unix()))
+				}
+			}
+		})
+	})
+
+	It("should not count impressions if the game has no impression caps", func() {
+		viewPopup("impression-1")
+
+		states, err := models.GetImpressionCapStates(nil, db, defaultGameID, playerID, currentTime, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states).To(BeEmpty())
+		erasure, err := models.ErasePlayer(nil, db, defaultGameID, playerID, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(erasure.PlayerImpressions).To(BeZero())
+	})
+})
@@ -7,7 +7,10 @@
 
 package models
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 //MetricsReporter is a contract for reporters of metrics
 type MetricsReporter interface {
@@ -21,6 +24,13 @@ type MetricsReporter interface {
 	EndExternalSegment(map[string]interface{})
 }
 
+//ReceiptVerifier checks with a store that the purchase of a claimed offer happened.
+//It returns an error only if the store could not be asked, rejected receipts are
+//returned as a verification that is not valid.
+type ReceiptVerifier interface {
+	Verify(ctx context.Context, receipt *Receipt) (*ReceiptVerification, error)
+}
+
 //Clock returns the time
 type Clock interface {
 	GetTime() time.Time
@@ -23,6 +23,7 @@ import (
 var conn runner.Connection
 var db *runner.Tx
 var offersCache *cache.Cache
+var cacheExpireDuration = 300 * time.Second
 
 func TestApi(t *testing.T) {
 	RegisterFailHandler(Fail)
@@ -37,7 +38,7 @@ var _ = BeforeSuite(func() {
 	err = oTesting.LoadFixtures(conn)
 	Expect(err).NotTo(HaveOccurred())
 
-	offersCache = cache.New(300*time.Second, 30*time.Second)
+	offersCache = cache.New(cacheExpireDuration, 30*time.Second)
 })
 
 var _ = BeforeEach(func() {
@@ -23,21 +23,41 @@ import (
 
 //Offer contains the parameters of an offer
 type Offer struct {
-	ID        string    `db:"id" json:"id" valid:"uuidv4"`
-	GameID    string    `db:"game_id" json:"gameId" valid:"matches(^[^-][a-zA-Z0-9-_]*$),stringlength(1|255),required"`
-	Name      string    `db:"name" json:"name" valid:"ascii,stringlength(1|255),required"`
-	Period    dat.JSON  `db:"period" json:"period" valid:"RequiredJSONObject"`
-	Frequency dat.JSON  `db:"frequency" json:"frequency" valid:"RequiredJSONObject"`
-	Trigger   dat.JSON  `db:"trigger" json:"trigger" valid:"RequiredJSONObject"`
-	Placement string    `db:"placement" json:"placement" valid:"ascii,stringlength(1|255),required"`
-	Metadata  dat.JSON  `db:"metadata" json:"metadata" valid:"JSONObject"`
-	ProductID string    `db:"product_id" json:"productId,omitempty" valid:"ascii,stringlength(1|255)"`
-	Contents  dat.JSON  `db:"contents" json:"contents" valid:"RequiredJSONObject"`
-	Enabled   bool      `db:"enabled" json:"enabled" valid:"matches(^(true|false)$),optional"`
-	Version   int       `db:"version" json:"version" valid:"int,optional"`
-	CreatedAt time.Time `db:"created_at" json:"createdAt" valid:"optional"`
-	Filters   dat.JSON  `db:"filters" json:"filters" valid:"FilterJSONObject"`
-	Cost      dat.JSON  `db:"cost" json:"cost,omitempty" valid:"JSONObject"`
+	ID             string        `db:"id" json:"id" valid:"uuidv4"`
+	GameID         string        `db:"game_id" json:"gameId" valid:"matches(^[^-][a-zA-Z0-9-_]*$),stringlength(1|255),required"`
+	Name           string        `db:"name" json:"name" valid:"ascii,stringlength(1|255),required"`
+	Period         dat.JSON      `db:"period" json:"period" valid:"RequiredJSONObject"`
+	Frequency      dat.JSON      `db:"frequency" json:"frequency" valid:"RequiredJSONObject"`
+	Trigger        dat.JSON      `db:"trigger" json:"trigger" valid:"RequiredJSONObject"`
+	Placement      string        `db:"placement" json:"placement" valid:"ascii,stringlength(1|255),required"`
+	Priority       int           `db:"priority" json:"priority" valid:"int,optional"`
+	ExclusionGroup string        `db:"exclusion_group" json:"exclusionGroup" valid:"ascii,stringlength(1|255),optional"`
+	Metadata       dat.JSON      `db:"metadata" json:"metadata" valid:"JSONObject"`
+	ProductID      string        `db:"product_id" json:"productId,omitempty" valid:"ascii,stringlength(1|255)"`
+	Contents       dat.JSON      `db:"contents" json:"contents" valid:"RequiredJSONObject"`
+	Enabled        bool          `db:"enabled" json:"enabled" valid:"matches(^(true|false)$),optional"`
+	Version        int           `db:"version" json:"version" valid:"int,optional"`
+	CreatedAt      time.Time     `db:"created_at" json:"createdAt" valid:"optional"`
+	Filters        dat.JSON      `db:"filters" json:"filters" valid:"FilterJSONObject"`
+	Cost           dat.JSON      `db:"cost" json:"cost,omitempty" valid:"JSONObject"`
+	Prerequisites  dat.JSON      `db:"prerequisites" json:"prerequisites" valid:"JSONObject"`
+	Stock          dat.NullInt64 `db:"stock" json:"stock" valid:"NonNegativeNullInt"`
+	StockSold      int64         `db:"stock_sold" json:"stockSold" valid:"optional"`
+	RemainingStock *int64        `db:"-" json:"remainingStock,omitempty" valid:"-"`
+	HasDraft       bool          `db:"-" json:"-" valid:"-"`
+}
+
+//remainingStock returns how many units of the offer can still be claimed,
+//or nil if the offer has no stock limit
+func (o *Offer) remainingStock() *int64 {
+	if !o.Stock.Valid {
+		return nil
+	}
+	remaining := o.Stock.Int64 - o.StockSold
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &remaining
 }
 
 const enabledOffers = `
@@ -108,7 +128,24 @@ func buildEfficientScope(enabledOffers string, filterAttrs map[string]string) st
 func GetOfferByID(ctx context.Context, db runner.Connection, gameID, id string, mr *MixedMetricsReporter) (*Offer, error) {
 	var offer Offer
 	err := mr.WithDatastoreSegment("offers", SegmentSelect, func() error {
-		builder := db.Select("id, frequency, period, version, enabled")
+		builder := db.Select("id, frequency, period, version, enabled, stock")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("offers").
+			Where("id=$1 AND game_id=$2", id, gameID).
+			QueryStruct(&offer)
+	})
+
+	err = handleNotFoundError("Offer", map[string]interface{}{
+		"ID":     id,
+		"GameID": gameID,
+	}, err)
+	return &offer, err
+}
+
+func getFullOffer(ctx context.Context, db runner.Connection, gameID, id string, mr *MixedMetricsReporter) (*Offer, error) {
+	var offer Offer
+	err := mr.WithDatastoreSegment("offers", SegmentSelect, func() error {
+		builder := db.Select("*")
 		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
 		return builder.From("offers").
 			Where("id=$1 AND game_id=$2", id, gameID).
@@ -150,7 +187,9 @@ func GetEnabledOffers(ctx context.Context, db runner.Connection, gameID string,
 			Select(`
 		id, game_id, name, period, frequency,
 		trigger, placement, metadata,
-		product_id, contents, version, cost
+		product_id, contents, version, cost,
+		stock, stock_sold, prerequisites,
+		priority, exclusion_group
 		`)
 		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
 		return builder.From("offers").
@@ -209,6 +248,9 @@ func ListOffers(
 		if err != nil {
 			return offers, 0, err
 		}
+		for _, offer := range offers {
+			offer.RemainingStock = offer.remainingStock()
+		}
 	}
 
 	return offers, pages, nil
@@ -225,17 +267,32 @@ func InsertOffer(ctx context.Context, db runner.Connection, offer *Offer, offers
 	if offer.Cost == nil {
 		offer.Cost = dat.JSON([]byte(`{}`))
 	}
-	err := mr.WithDatastoreSegment("offers", SegmentInsert, func() error {
+	if offer.Prerequisites == nil {
+		offer.Prerequisites = dat.JSON([]byte(`{}`))
+	}
+	err := validateTrigger(offer)
+	if err != nil {
+		return offer, err
+	}
+	err = mr.WithDatastoreSegment("offers", SegmentInsert, func() error {
 		tx, errInt := db.Begin()
 		if errInt != nil {
 			return errInt
 		}
 		defer tx.AutoRollback()
+		errInt = validateOfferPlacement(ctx, tx, offer, mr)
+		if errInt != nil {
+			return errInt
+		}
+		errInt = validatePrerequisites(ctx, tx, offer, mr)
+		if errInt != nil {
+			return errInt
+		}
 		builder := tx.InsertInto("offers")
 		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
-		errInt = builder.Columns("game_id", "name", "period", "frequency", "trigger", "placement", "metadata", "product_id", "contents", "filters", "cost").
+		errInt = builder.Columns("game_id", "name", "period", "frequency", "trigger", "placement", "metadata", "product_id", "contents", "filters", "cost", "stock", "prerequisites", "priority", "exclusion_group").
 			Record(offer).
-			Returning("id, enabled, version").
+			Returning("id, enabled, version, created_at, stock_sold").
 			QueryStruct(offer)
 		if errInt != nil {
 			return errInt
@@ -250,6 +307,10 @@ func InsertOffer(ctx context.Context, db runner.Connection, offer *Offer, offers
 		if errInt != nil {
 			return errInt
 		}
+		errInt = insertAuditEvent(ctx, tx, offer.GameID, offer.ID, AuditActionInsertOffer, nil, offer, mr)
+		if errInt != nil {
+			return errInt
+		}
 		tx.Commit()
 		return nil
 	})
@@ -264,7 +325,11 @@ func InsertOffer(ctx context.Context, db runner.Connection, offer *Offer, offers
 
 // UpdateOffer updates a given offer
 func UpdateOffer(ctx context.Context, db runner.Connection, offer *Offer, offersCache *cache.Cache, mr *MixedMetricsReporter) (*Offer, error) {
-	prevOffer, err := GetOfferByID(ctx, db, offer.GameID, offer.ID, mr)
+	prevOffer, err := getFullOffer(ctx, db, offer.GameID, offer.ID, mr)
+	if err != nil {
+		return nil, err
+	}
+	err = validateTrigger(offer)
 	if err != nil {
 		return nil, err
 	}
@@ -277,18 +342,25 @@ func UpdateOffer(ctx context.Context, db runner.Connection, offer *Offer, offers
 	if offer.Cost == nil {
 		offer.Cost = dat.JSON([]byte(`{}`))
 	}
+	if offer.Prerequisites == nil {
+		offer.Prerequisites = dat.JSON([]byte(`{}`))
+	}
 	offersMap := map[string]interface{}{
-		"name":       offer.Name,
-		"period":     offer.Period,
-		"frequency":  offer.Frequency,
-		"trigger":    offer.Trigger,
-		"placement":  offer.Placement,
-		"metadata":   offer.Metadata,
-		"product_id": offer.ProductID,
-		"contents":   offer.Contents,
-		"filters":    offer.Filters,
-		"cost":       offer.Cost,
-		"version":    prevOffer.Version + 1,
+		"name":            offer.Name,
+		"period":          offer.Period,
+		"frequency":       offer.Frequency,
+		"trigger":         offer.Trigger,
+		"placement":       offer.Placement,
+		"metadata":        offer.Metadata,
+		"product_id":      offer.ProductID,
+		"contents":        offer.Contents,
+		"filters":         offer.Filters,
+		"cost":            offer.Cost,
+		"stock":           offer.Stock,
+		"prerequisites":   offer.Prerequisites,
+		"priority":        offer.Priority,
+		"exclusion_group": offer.ExclusionGroup,
+		"version":         prevOffer.Version + 1,
 	}
 	offer.Version = prevOffer.Version + 1
 	err = mr.WithDatastoreSegment("offers", SegmentUpdate, func() error {
@@ -297,11 +369,19 @@ func UpdateOffer(ctx context.Context, db runner.Connection, offer *Offer, offers
 			return errInt
 		}
 		defer tx.AutoRollback()
+		errInt = validateOfferPlacement(ctx, tx, offer, mr)
+		if errInt != nil {
+			return errInt
+		}
+		errInt = validatePrerequisites(ctx, tx, offer, mr)
+		if errInt != nil {
+			return errInt
+		}
 		builder := tx.Update("offers")
 		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
 		errInt = builder.SetMap(offersMap).
 			Where("id = $1 AND game_id = $2", offer.ID, offer.GameID).
-			Returning("id, version").
+			Returning("id, version, enabled, created_at, stock_sold").
 			QueryStruct(offer)
 		if errInt != nil {
 			return errInt
@@ -316,6 +396,10 @@ func UpdateOffer(ctx context.Context, db runner.Connection, offer *Offer, offers
 		if errInt != nil {
 			return errInt
 		}
+		errInt = insertAuditEvent(ctx, tx, offer.GameID, offer.ID, AuditActionUpdateOffer, prevOffer, offer, mr)
+		if errInt != nil {
+			return errInt
+		}
 		tx.Commit()
 		return nil
 	})
@@ -328,13 +412,24 @@ func UpdateOffer(ctx context.Context, db runner.Connection, offer *Offer, offers
 
 //SetEnabledOffer can enable or disable an offer template
 func SetEnabledOffer(ctx context.Context, db runner.Connection, gameID, id string, enabled bool, offersCache *cache.Cache, mr *MixedMetricsReporter) error {
-	var offerTemplate Offer
-	err := mr.WithDatastoreSegment("offers", SegmentUpdate, func() error {
-		builder := db.Update("offers")
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.AutoRollback()
+
+	prevOffer, err := getFullOffer(ctx, tx, gameID, id, mr)
+	if err != nil {
+		return err
+	}
+
+	offerTemplate := *prevOffer
+	err = mr.WithDatastoreSegment("offers", SegmentUpdate, func() error {
+		builder := tx.Update("offers")
 		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
 		return builder.Set("enabled", enabled).
 			Where("id=$1 AND game_id=$2", id, gameID).
-			Returning("id").
+			Returning("id, enabled").
 			QueryStruct(&offerTemplate)
 	})
 
@@ -342,6 +437,20 @@ func SetEnabledOffer(ctx context.Context, db runner.Connection, gameID, id strin
 		"ID":     id,
 		"GameID": gameID,
 	}, err)
+	if err != nil {
+		return err
+	}
+
+	action := AuditActionDisableOffer
+	if enabled {
+		action = AuditActionEnableOffer
+	}
+	err = insertAuditEvent(ctx, tx, gameID, id, action, prevOffer, &offerTemplate, mr)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
 	if err == nil {
 		enabledOffersKey := GetEnabledOffersKey(gameID)
 		offersCache.Delete(enabledOffersKey)
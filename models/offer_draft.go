@@ -0,0 +1,209 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/pmylund/go-cache"
+	edat "github.com/topfreegames/extensions/dat"
+	"github.com/topfreegames/offers/errors"
+	"gopkg.in/mgutz/dat.v2/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//OfferDraft holds unpublished changes to an offer template
+type OfferDraft struct {
+	ID             string        `db:"id" json:"id"`
+	GameID         string        `db:"game_id" json:"gameId"`
+	OfferID        string        `db:"offer_id" json:"offerId"`
+	Name           string        `db:"name" json:"name"`
+	Period         dat.JSON      `db:"period" json:"period"`
+	Frequency      dat.JSON      `db:"frequency" json:"frequency"`
+	Trigger        dat.JSON      `db:"trigger" json:"trigger"`
+	Placement      string        `db:"placement" json:"placement"`
+	Priority       int           `db:"priority" json:"priority"`
+	ExclusionGroup string        `db:"exclusion_group" json:"exclusionGroup"`
+	Metadata       dat.JSON      `db:"metadata" json:"metadata"`
+	ProductID      string        `db:"product_id" json:"productId,omitempty"`
+	Contents       dat.JSON      `db:"contents" json:"contents"`
+	Filters        dat.JSON      `db:"filters" json:"filters"`
+	Cost           dat.JSON      `db:"cost" json:"cost,omitempty"`
+	Stock          dat.NullInt64 `db:"stock" json:"stock"`
+	Prerequisites  dat.JSON      `db:"prerequisites" json:"prerequisites"`
+	Author         string        `db:"author" json:"author"`
+	CreatedAt      dat.NullTime  `db:"created_at" json:"createdAt"`
+	UpdatedAt      dat.NullTime  `db:"updated_at" json:"updatedAt"`
+}
+
+//OfferDraftFromOffer builds a draft with the editable fields of an offer
+func OfferDraftFromOffer(offer *Offer, author string) *OfferDraft {
+	return &OfferDraft{
+		GameID:         offer.GameID,
+		OfferID:        offer.ID,
+		Name:           offer.Name,
+		Period:         offer.Period,
+		Frequency:      offer.Frequency,
+		Trigger:        offer.Trigger,
+		Placement:      offer.Placement,
+		Priority:       offer.Priority,
+		ExclusionGroup: offer.ExclusionGroup,
+		Metadata:       offer.Metadata,
+		ProductID:      offer.ProductID,
+		Contents:       offer.Contents,
+		Filters:        offer.Filters,
+		Cost:           offer.Cost,
+		Stock:          offer.Stock,
+		Prerequisites:  offer.Prerequisites,
+		Author:         author,
+	}
+}
+
+func offerFromDraft(draft *OfferDraft) *Offer {
+	return &Offer{
+		ID:             draft.OfferID,
+		GameID:         draft.GameID,
+		Name:           draft.Name,
+		Period:         draft.Period,
+		Frequency:      draft.Frequency,
+		Trigger:        draft.Trigger,
+		Placement:      draft.Placement,
+		Priority:       draft.Priority,
+		ExclusionGroup: draft.ExclusionGroup,
+		Metadata:       draft.Metadata,
+		ProductID:      draft.ProductID,
+		Contents:       draft.Contents,
+		Filters:        draft.Filters,
+		Cost:           draft.Cost,
+		Stock:          draft.Stock,
+		Prerequisites:  draft.Prerequisites,
+	}
+}
+
+//GetOfferDraft returns the draft of an offer
+func GetOfferDraft(ctx context.Context, db runner.Connection, gameID, offerID string, mr *MixedMetricsReporter) (*OfferDraft, error) {
+	var draft OfferDraft
+	err := mr.WithDatastoreSegment("offer_drafts", SegmentSelect, func() error {
+		builder := db.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("offer_drafts").
+			Where("game_id = $1 AND offer_id = $2", gameID, offerID).
+			QueryStruct(&draft)
+	})
+
+	err = handleNotFoundError("OfferDraft", map[string]interface{}{
+		"GameID":  gameID,
+		"OfferID": offerID,
+	}, err)
+	return &draft, err
+}
+
+//UpsertOfferDraft creates or replaces the draft of an existing offer
+func UpsertOfferDraft(ctx context.Context, db runner.Connection, draft *OfferDraft, t time.Time, mr *MixedMetricsReporter) error {
+	_, err := GetOfferByID(ctx, db, draft.GameID, draft.OfferID, mr)
+	if err != nil {
+		return err
+	}
+	err = validateTrigger(offerFromDraft(draft))
+	if err != nil {
+		return err
+	}
+	err = validateOfferPlacement(ctx, db, offerFromDraft(draft), mr)
+	if err != nil {
+		return err
+	}
+	if draft.Metadata == nil {
+		draft.Metadata = dat.JSON([]byte(`{}`))
+	}
+	if draft.Filters == nil {
+		draft.Filters = dat.JSON([]byte(`{}`))
+	}
+	if draft.Cost == nil {
+		draft.Cost = dat.JSON([]byte(`{}`))
+	}
+	if draft.Prerequisites == nil {
+		draft.Prerequisites = dat.JSON([]byte(`{}`))
+	}
+	draft.UpdatedAt = dat.NullTimeFrom(t)
+	return mr.WithDatastoreSegment("offer_drafts", SegmentUpsert, func() error {
+		builder := db.Upsert("offer_drafts")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.Columns(
+			"game_id", "offer_id", "name", "period", "frequency", "trigger", "placement", "priority",
+			"exclusion_group", "metadata", "product_id", "contents", "filters", "cost", "stock", "prerequisites", "author", "updated_at",
+		).
+			Record(draft).
+			Where("game_id = $1 AND offer_id = $2", draft.GameID, draft.OfferID).
+			Returning("id", "created_at", "updated_at").
+			QueryStruct(draft)
+	})
+}
+
+//DeleteOfferDraft discards the draft of an offer
+func DeleteOfferDraft(ctx context.Context, db runner.Connection, gameID, offerID string, mr *MixedMetricsReporter) error {
+	var draft OfferDraft
+	err := mr.WithDatastoreSegment("offer_drafts", SegmentDelete, func() error {
+		builder := db.DeleteFrom("offer_drafts")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.
+			Where("game_id = $1 AND offer_id = $2", gameID, offerID).
+			Returning("id").
+			QueryStruct(&draft)
+	})
+
+	return handleNotFoundError("OfferDraft", map[string]interface{}{
+		"GameID":  gameID,
+		"OfferID": offerID,
+	}, err)
+}
+
+//PublishOfferDraft promotes the draft of an offer to a new offer version and discards the draft.
+//If requireApproval is true the draft author and the publisher must be known and differ.
+func PublishOfferDraft(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, offerID, publisher string,
+	requireApproval bool,
+	offersCache *cache.Cache,
+	mr *MixedMetricsReporter,
+) (*Offer, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.AutoRollback()
+
+	draft, err := GetOfferDraft(ctx, tx, gameID, offerID, mr)
+	if err != nil {
+		return nil, err
+	}
+
+	if requireApproval && draft.Author == "" {
+		return nil, errors.NewInvalidModelError("OfferDraft", "the draft has no author to be approved")
+	}
+	if requireApproval && (publisher == "" || publisher == draft.Author) {
+		return nil, errors.NewInvalidModelError("OfferDraft", "the publisher must differ from the draft author")
+	}
+
+	offer, err := UpdateOffer(ctx, tx, offerFromDraft(draft), offersCache, mr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = DeleteOfferDraft(ctx, tx, gameID, offerID, mr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
@@ -0,0 +1,169 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	uuid "github.com/satori/go.uuid"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+	"gopkg.in/mgutz/dat.v2/dat"
+)
+
+var _ = Describe("Offer Draft Model", func() {
+	currentTime := time.Unix(1486678000, 0)
+	offerID := "a411fbcf-dddc-4153-b42b-3f9b2684c965"
+
+	var draft *models.OfferDraft
+	BeforeEach(func() {
+		draft = models.OfferDraftFromOffer(&models.Offer{
+			ID:        offerID,
+			GameID:    defaultGameID,
+			Name:      "drafted-name",
+			ProductID: "com.tfg.sample",
+			Contents:  dat.JSON([]byte(`{"gems": 42}`)),
+			Period:    dat.JSON([]byte(`{"max": 1}`)),
+			Frequency: dat.JSON([]byte(`{"every": "24h"}`)),
+			Trigger:   dat.JSON([]byte(`{"from": 1486678000, "to": 1486679000}`)),
+			Placement: "popup",
+		}, "author@tfgco.com")
+	})
+
+	Describe("Upsert offer draft", func() {
+		It("should create a draft without changing the offer", func() {
+			err := models.UpsertOfferDraft(nil, db, draft, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(draft.ID).NotTo(BeEmpty())
+
+			dbDraft, err := models.GetOfferDraft(nil, db, defaultGameID, offerID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dbDraft.Name).To(Equal("drafted-name"))
+			Expect(dbDraft.Author).To(Equal("author@tfgco.com"))
+
+			offer, err := models.GetOfferByID(nil, db, defaultGameID, offerID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offer.Version).To(Equal(1))
+		})
+
+		It("should replace an existing draft", func() {
+			err := models.UpsertOfferDraft(nil, db, draft, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+			draft.Name = "another-name"
+			err = models.UpsertOfferDraft(nil, db, draft, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			dbDraft, err := models.GetOfferDraft(nil, db, defaultGameID, offerID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dbDraft.ID).To(Equal(draft.ID))
+			Expect(dbDraft.Name).To(Equal("another-name"))
+		})
+
+		It("should return error if offer does not exist", func() {
+			draft.OfferID = uuid.NewV4().String()
+			expectedError := errors.NewModelNotFoundError("Offer", map[string]interface{}{
+				"GameID": defaultGameID,
+				"ID":     draft.OfferID,
+			})
+
+			err := models.UpsertOfferDraft(nil, db, draft, currentTime, nil)
+
+			Expect(err).To(MatchError(expectedError))
+		})
+	})
+
+	Describe("Delete offer draft", func() {
+		It("should discard the draft", func() {
+			err := models.UpsertOfferDraft(nil, db, draft, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = models.DeleteOfferDraft(nil, db, defaultGameID, offerID, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = models.GetOfferDraft(nil, db, defaultGameID, offerID, nil)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should return error if there is no draft", func() {
+			expectedError := errors.NewModelNotFoundError("OfferDraft", map[string]interface{}{
+				"GameID":  defaultGameID,
+				"OfferID": offerID,
+			})
+
+			err := models.DeleteOfferDraft(nil, db, defaultGameID, offerID, nil)
+
+			Expect(err).To(MatchError(expectedError))
+		})
+	})
+
+	Describe("Publish offer draft", func() {
+		BeforeEach(func() {
+			err := models.UpsertOfferDraft(nil, db, draft, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should create a new offer version and discard the draft", func() {
+			offer, err := models.PublishOfferDraft(nil, db, defaultGameID, offerID, "author@tfgco.com", false, offersCache, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offer.Name).To(Equal("drafted-name"))
+			Expect(offer.Version).To(Equal(2))
+
+			var contents dat.JSON
+			err = db.Select("contents").
+				From("offer_versions").
+				Where("offer_id = $1 AND offer_version = $2", offerID, 2).
+				QueryScalar(&contents)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(contents).To(MatchJSON(`{"gems": 42}`))
+
+			_, err = models.GetOfferDraft(nil, db, defaultGameID, offerID, nil)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should not let the author publish if approval is required", func() {
+			expectedError := errors.NewInvalidModelError("OfferDraft", "the publisher must differ from the draft author")
+
+			_, err := models.PublishOfferDraft(nil, db, defaultGameID, offerID, "author@tfgco.com", true, offersCache, nil)
+
+			Expect(err).To(MatchError(expectedError))
+		})
+
+		It("should not publish a draft without author if approval is required", func() {
+			draft.Author = ""
+			err := models.UpsertOfferDraft(nil, db, draft, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+			expectedError := errors.NewInvalidModelError("OfferDraft", "the draft has no author to be approved")
+
+			_, err = models.PublishOfferDraft(nil, db, defaultGameID, offerID, "reviewer@tfgco.com", true, offersCache, nil)
+
+			Expect(err).To(MatchError(expectedError))
+		})
+
+		It("should let another user publish if approval is required", func() {
+			offer, err := models.PublishOfferDraft(nil, db, defaultGameID, offerID, "reviewer@tfgco.com", true, offersCache, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offer.Version).To(Equal(2))
+		})
+
+		It("should return error if there is no draft", func() {
+			otherOfferID := "dd21ec96-2890-4ba0-b8e2-40ea67196990"
+			expectedError := errors.NewModelNotFoundError("OfferDraft", map[string]interface{}{
+				"GameID":  defaultGameID,
+				"OfferID": otherOfferID,
+			})
+
+			_, err := models.PublishOfferDraft(nil, db, defaultGameID, otherOfferID, "", false, offersCache, nil)
+
+			Expect(err).To(MatchError(expectedError))
+		})
+	})
+})
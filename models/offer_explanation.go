@@ -0,0 +1,296 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pmylund/go-cache"
+	edat "github.com/topfreegames/extensions/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//OfferExplanation tells whether an offer is included in the available offers of a player and,
+//if it is not, the rule that excluded it
+type OfferExplanation struct {
+	OfferID   string `json:"offerId"`
+	Name      string `json:"name"`
+	Placement string `json:"placement"`
+	Included  bool   `json:"included"`
+	Rule      string `json:"rule,omitempty"`
+	FilterKey string `json:"filterKey,omitempty"`
+	Until     int64  `json:"until,omitempty"`
+}
+
+//ExplainAvailableOffers runs the same rules as GetAvailableOffers at time t and explains,
+//for every offer template of the game, whether it is included and why not.
+//The enabled offers cache is not used, so offers changed recently may differ from GetAvailableOffers.
+func ExplainAvailableOffers(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, playerID string,
+	t time.Time,
+	filterAttrs map[string]string,
+	allowInefficientQueries bool,
+	mr *MixedMetricsReporter,
+) ([]*OfferExplanation, error) {
+	offers := []*Offer{}
+	err := mr.WithDatastoreSegment("offers", SegmentSelect, func() error {
+		builder := db.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("offers").
+			Where("game_id = $1", gameID).
+			OrderBy("placement, name, id").
+			QueryStructs(&offers)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	enabledOffers, err := GetEnabledOffers(
+		ctx,
+		db,
+		gameID,
+		cache.New(cache.NoExpiration, 0),
+		cache.NoExpiration,
+		t,
+		filterAttrs,
+		allowInefficientQueries,
+		mr,
+	)
+	if err != nil {
+		return nil, err
+	}
+	offersByPlayer, err := GetOffersByPlayer(ctx, db, gameID, playerID, mr)
+	if err != nil {
+		return nil, err
+	}
+	filteredOffers, err := filterOffersByFrequencyAndPeriod(playerID, enabledOffers, offersByPlayer, t, mr)
+	if err != nil {
+		return nil, err
+	}
+	filteredOffers, err = filterOffersByPrerequisites(filteredOffers, offersByPlayer)
+	if err != nil {
+		return nil, err
+	}
+	filteredOffers, err = filterSoldOutOffers(ctx, db, gameID, filteredOffers, mr)
+	if err != nil {
+		return nil, err
+	}
+	eligibleOfferIDs := map[string]bool{}
+	for _, offer := range filteredOffers {
+		eligibleOfferIDs[offer.ID] = true
+	}
+	filteredOffers = filterOffersByExclusionGroup(filteredOffers)
+
+	enabledOfferIDs := map[string]bool{}
+	for _, offer := range enabledOffers {
+		enabledOfferIDs[offer.ID] = true
+	}
+	includedOfferIDs := map[string]bool{}
+	for _, offer := range filteredOffers {
+		includedOfferIDs[offer.ID] = true
+	}
+	playerOffersByOfferID := map[string]*OfferPlayer{}
+	for _, playerOffer := range offersByPlayer {
+		playerOffersByOfferID[playerOffer.OfferID] = playerOffer
+	}
+
+	impressionCapStates, err := GetImpressionCapStates(ctx, db, gameID, playerID, t, mr)
+	if err != nil {
+		return nil, err
+	}
+	reachedImpressionCaps := reachedImpressionCapsByPlacement(impressionCapStates)
+	rotatedOutUntil, err := explainRotations(ctx, db, gameID, playerID, filteredOffers, t, mr)
+	if err != nil {
+		return nil, err
+	}
+
+	explanations := []*OfferExplanation{}
+	for _, offer := range offers {
+		explanation := &OfferExplanation{
+			OfferID:   offer.ID,
+			Name:      offer.Name,
+			Placement: offer.Placement,
+			Included:  includedOfferIDs[offer.ID],
+		}
+		explanations = append(explanations, explanation)
+		if explanation.Included {
+			reachedImpressionCap, ok := reachedImpressionCaps[""]
+			if !ok {
+				reachedImpressionCap, ok = reachedImpressionCaps[offer.Placement]
+			}
+			if ok {
+				explanation.Included = false
+				explanation.Rule = UnavailableImpressionCap
+				explanation.Until = reachedImpressionCap.ResetAt
+			} else if until, ok := rotatedOutUntil[offer.ID]; ok {
+				explanation.Included = false
+				explanation.Rule = UnavailableRotation
+				explanation.Until = until
+			}
+			continue
+		}
+
+		offerPlayer := &OfferPlayer{}
+		if val, ok := playerOffersByOfferID[offer.ID]; ok {
+			offerPlayer = val
+		}
+
+		if enabledOfferIDs[offer.ID] {
+			var until time.Time
+			explanation.Rule, until, err = frequencyAndPeriodReason(offer, offerPlayer, t)
+			if err != nil {
+				return nil, err
+			}
+			if !until.IsZero() {
+				explanation.Until = until.Unix()
+			}
+			if explanation.Rule == "" {
+				explanation.Rule, err = prerequisitesReason(offer, playerOffersByOfferID)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if explanation.Rule == "" && eligibleOfferIDs[offer.ID] {
+				explanation.Rule = UnavailableExclusionGroup
+			}
+			if explanation.Rule == "" {
+				explanation.Rule = UnavailableSoldOut
+			}
+			continue
+		}
+
+		explanation.Rule, err = offerUnavailableReason(offer, &OfferPlayer{}, t)
+		if err != nil {
+			return nil, err
+		}
+		if explanation.Rule == "" {
+			explanation.Rule = UnavailableFilter
+			explanation.FilterKey, err = mismatchedFilterKey(offer, filterAttrs, allowInefficientQueries)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return explanations, nil
+}
+
+//explainRotations returns, by offer id, when the rotation of their placement changes for the
+//included offers that the rotation did not pick for the player at time t
+func explainRotations(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, playerID string,
+	includedOffers []*Offer,
+	t time.Time,
+	mr *MixedMetricsReporter,
+) (map[string]int64, error) {
+	rotatedOutUntil := map[string]int64{}
+	placements, err := ListPlacements(ctx, db, gameID, mr)
+	if err != nil {
+		return nil, err
+	}
+	offerIDsByPlacement := map[string][]string{}
+	for _, offer := range includedOffers {
+		offerIDsByPlacement[offer.Placement] = append(offerIDsByPlacement[offer.Placement], offer.ID)
+	}
+	var pools map[string][]string
+	for _, placement := range placements {
+		rotation, err := placement.getRotation()
+		if err != nil {
+			return nil, err
+		}
+		offerIDs := offerIDsByPlacement[placement.Name]
+		if rotation == nil || len(offerIDs) == 0 {
+			continue
+		}
+		if pools == nil {
+			pools, err = getRotationPools(ctx, db, gameID, mr)
+			if err != nil {
+				return nil, err
+			}
+		}
+		state := rotation.state(gameID, playerID, placement.Name, t)
+		pool := uniqueStrings(append(append([]string{}, pools[placement.Name]...), offerIDs...))
+		picked := pickRotatedOfferIDs(state.Seed, pool, rotation.Size)
+		for _, offerID := range offerIDs {
+			if !picked[offerID] {
+				rotatedOutUntil[offerID] = state.ResetAt
+			}
+		}
+	}
+	return rotatedOutUntil, nil
+}
+
+//mismatchedFilterKey returns the first attribute, in key order, whose value is not accepted by
+//the filters of the offer. It mirrors the scopes built by GetEnabledOffers.
+func mismatchedFilterKey(offer *Offer, filterAttrs map[string]string, allowInefficientQueries bool) (string, error) {
+	keys := []string{}
+	for k, v := range filterAttrs {
+		if !ValidateString(k) || !ValidateString(v) {
+			// invalid attributes make GetEnabledOffers ignore all of them
+			return "", nil
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	filters := map[string]map[string]interface{}{}
+	if offer.Filters != nil {
+		if err := offer.Filters.Unmarshal(&filters); err != nil {
+			return "", err
+		}
+	}
+
+	for _, k := range keys {
+		v := filterAttrs[k]
+		filter, hasFilter := filters[k]
+		if !allowInefficientQueries {
+			if hasFilter && filter["eq"] == v {
+				continue
+			}
+			return k, nil
+		}
+
+		if !hasFilter || filter["eq"] == v || filter["neq"] == v {
+			continue
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil && matchesFilterRange(filter, f) {
+			continue
+		}
+		return k, nil
+	}
+	return "", nil
+}
+
+func matchesFilterRange(filter map[string]interface{}, value float64) bool {
+	geq, hasGeq := filterNumber(filter["geq"])
+	if !hasGeq || value < geq {
+		return false
+	}
+	if _, ok := filter["lt"]; ok {
+		lt, hasLt := filterNumber(filter["lt"])
+		return hasLt && value < lt
+	}
+	return true
+}
+
+func filterNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
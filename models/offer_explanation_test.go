@@ -0,0 +1,101 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	uuid "github.com/satori/go.uuid"
+	"github.com/topfreegames/offers/models"
+	"gopkg.in/mgutz/dat.v2/dat"
+)
+
+var _ = Describe("Offer Explanation Model", func() {
+	currentTime := time.Unix(1486678000, 0)
+	playerID := "explain-player"
+
+	explain := func(t time.Time, filterAttrs map[string]string, allowInefficientQueries bool) map[string]*models.OfferExplanation {
+		explanations, err := models.ExplainAvailableOffers(nil, db, defaultGameID, playerID, t, filterAttrs, allowInefficientQueries, nil)
+		Expect(err).NotTo(HaveOccurred())
+		byName := map[string]*models.OfferExplanation{}
+		for _, explanation := range explanations {
+			byName[explanation.Name] = explanation
+		}
+		return byName
+	}
+
+	insertFilteredOffer := func(filters string) {
+		_, err := models.InsertOffer(nil, db, &models.Offer{
+			Name:      "filtered-offer",
+			ProductID: "com.tfg.filtered",
+			GameID:    defaultGameID,
+			Contents:  dat.JSON([]byte(`{"gems": 5}`)),
+			Period:    dat.JSON([]byte(`{}`)),
+			Frequency: dat.JSON([]byte(`{}`)),
+			Trigger:   dat.JSON([]byte(`{"from": 1486678000, "to": 1486679000}`)),
+			Filters:   dat.JSON([]byte(filters)),
+			Placement: "popup",
+		}, offersCache, nil)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	It("should explain every offer of the game", func() {
+		explanations := explain(currentTime, map[string]string{}, false)
+
+		Expect(explanations).To(HaveKey("template-1"))
+		Expect(explanations["template-1"].Included).To(BeTrue())
+		Expect(explanations["template-1"].Rule).To(BeEmpty())
+		Expect(explanations["template-2"].Included).To(BeTrue())
+		Expect(explanations["template-9"].Included).To(BeFalse())
+		Expect(explanations["template-9"].Rule).To(Equal(models.UnavailableDisabled))
+	})
+
+	It("should explain offers outside their trigger window", func() {
+		explanations := explain(time.Unix(1486677000, 0), map[string]string{}, false)
+		Expect(explanations["template-1"].Rule).To(Equal(models.UnavailableNotStarted))
+
+		explanations = explain(time.Unix(1486679050, 0), map[string]string{}, false)
+		Expect(explanations["template-1"].Rule).To(Equal(models.UnavailableExpired))
+		Expect(explanations["template-2"].Included).To(BeTrue())
+	})
+
+	It("should explain offers in period cooldown", func() {
+		_, _, _, err := models.ClaimOffer(nil, db, defaultGameID, "eb7e8d2a-2739-4da3-aa31-7970b63bdad7", playerID, "", uuid.NewV4().String(), currentTime.Unix(), currentTime, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		explanations := explain(currentTime, map[string]string{}, false)
+
+		Expect(explanations["template-1"].Included).To(BeFalse())
+		Expect(explanations["template-1"].Rule).To(Equal(models.UnavailablePeriod))
+		Expect(explanations["template-1"].Until).To(Equal(currentTime.Unix() + 1))
+	})
+
+	It("should explain the filter key that did not match", func() {
+		insertFilteredOffer(`{"level": {"eq": "5"}}`)
+
+		explanations := explain(currentTime, map[string]string{"level": "4"}, false)
+		Expect(explanations["filtered-offer"].Rule).To(Equal(models.UnavailableFilter))
+		Expect(explanations["filtered-offer"].FilterKey).To(Equal("level"))
+
+		explanations = explain(currentTime, map[string]string{"level": "5"}, false)
+		Expect(explanations["filtered-offer"].Included).To(BeTrue())
+	})
+
+	It("should explain range filters of inefficient queries", func() {
+		insertFilteredOffer(`{"level": {"geq": 1.0, "lt": 3.0}}`)
+
+		explanations := explain(currentTime, map[string]string{"level": "3"}, true)
+		Expect(explanations["filtered-offer"].Rule).To(Equal(models.UnavailableFilter))
+		Expect(explanations["filtered-offer"].FilterKey).To(Equal("level"))
+
+		explanations = explain(currentTime, map[string]string{"level": "2"}, true)
+		Expect(explanations["filtered-offer"].Included).To(BeTrue())
+	})
+})
@@ -10,10 +10,11 @@ package models
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"time"
 
 	"github.com/pmylund/go-cache"
+	edat "github.com/topfreegames/extensions/dat"
+	"github.com/topfreegames/offers/errors"
 	"gopkg.in/mgutz/dat.v2/dat"
 	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
 )
@@ -33,21 +34,26 @@ type OfferInstance struct {
 
 //OfferInstanceOffer is a join of OfferInstance with offer
 type OfferInstanceOffer struct {
-	ID       string   `db:"id" json:"id" valid:"uuidv4,required"`
-	GameID   string   `db:"game_id" json:"gameId" valid:"matches(^[^-][a-zA-Z0-9-_]*$),stringlength(1|255),required"`
-	OfferID  string   `db:"offer_id" json:"offerId" valid:"uuidv4,required"`
-	Contents dat.JSON `db:"contents" json:"contents" valid:"RequiredJSONObject"`
-	Enabled  bool     `db:"enabled" json:"enabled"`
+	ID        string   `db:"id" json:"id" valid:"uuidv4,required"`
+	GameID    string   `db:"game_id" json:"gameId" valid:"matches(^[^-][a-zA-Z0-9-_]*$),stringlength(1|255),required"`
+	OfferID   string   `db:"offer_id" json:"offerId" valid:"uuidv4,required"`
+	Contents  dat.JSON `db:"contents" json:"contents" valid:"RequiredJSONObject"`
+	Enabled   bool     `db:"enabled" json:"enabled"`
+	Placement string   `db:"placement" json:"placement"`
 }
 
 //OfferToReturn has the fields for the returned offer
 type OfferToReturn struct {
-	ID        string   `db:"id" json:"id"`
-	ProductID string   `db:"product_id" json:"productId,omitempty"`
-	Cost      dat.JSON `db:"cost" json:"cost,omitempty" valid:"JSONObject"`
-	Contents  dat.JSON `db:"contents" json:"contents"`
-	Metadata  dat.JSON `db:"metadata" json:"metadata"`
-	ExpireAt  int64    `db:"expire_at" json:"expireAt"`
+	ID             string   `db:"id" json:"id"`
+	ProductID      string   `db:"product_id" json:"productId,omitempty"`
+	Cost           dat.JSON `db:"cost" json:"cost,omitempty" valid:"JSONObject"`
+	Contents       dat.JSON `db:"contents" json:"contents"`
+	Metadata       dat.JSON `db:"metadata" json:"metadata"`
+	ExpireAt       int64    `db:"expire_at" json:"expireAt"`
+	RemainingStock *int64   `db:"remaining_stock" json:"remainingStock,omitempty"`
+	Token          string   `db:"-" json:"token,omitempty"`
+	OfferID        string   `db:"-" json:"-"`
+	HasDraft       bool     `db:"-" json:"hasDraft,omitempty"`
 }
 
 //FrequencyOrPeriod is the struct for basic Frequency and Period types
@@ -68,8 +74,14 @@ func getClaimedOfferNextAt(
 	if err != nil {
 		return 0, err
 	}
+	return claimedOfferNextAt(offer, claimCounter, t), nil
+}
+
+//claimedOfferNextAt returns when the offer can be claimed again after a claim at time t,
+//or 0 if it can't be claimed again
+func claimedOfferNextAt(offer *Offer, claimCounter int, t time.Time) int64 {
 	if !offer.Enabled {
-		return 0, nil
+		return 0
 	}
 
 	var p FrequencyOrPeriod
@@ -78,11 +90,11 @@ func getClaimedOfferNextAt(
 	json.Unmarshal(offer.Frequency, &f)
 
 	if p.Max != 0 && claimCounter >= p.Max {
-		return 0, nil
+		return 0
 	}
 
 	if p.Every == "" && f.Every == "" {
-		return t.Unix(), nil
+		return t.Unix()
 	}
 
 	var duration time.Duration
@@ -98,7 +110,7 @@ func getClaimedOfferNextAt(
 			nextAt = t.Add(duration).Unix()
 		}
 	}
-	return nextAt, nil
+	return nextAt
 }
 
 //ClaimOffer claims the offer
@@ -109,176 +121,248 @@ func ClaimOffer(
 	timestamp int64,
 	t time.Time,
 	mr *MixedMetricsReporter,
+) (dat.JSON, bool, int64, error) {
+	return ClaimOfferWithReceipt(ctx, db, gameID, offerInstanceID, playerID, productID, transactionID, timestamp, nil, t, mr)
+}
+
+//ClaimOfferWithReceipt claims the offer and stores the verification of its receipt with the claim, if there is one.
+//The verified product must be the product of the offer version, and a verified transaction claims a single offer of the game.
+func ClaimOfferWithReceipt(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, offerInstanceID, playerID, productID, transactionID string,
+	timestamp int64,
+	receipt *ReceiptVerification,
+	t time.Time,
+	mr *MixedMetricsReporter,
 ) (dat.JSON, bool, int64, error) {
 	// If an offer instance id is sent
 	var offerInstance *OfferVersion
 	var previousOfferPlayer bool
-	var err error
 	var nextAt int64
 
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, false, 0, err
+	}
+	defer tx.AutoRollback()
+
 	if offerInstanceID != "" {
-		offerInstance, err = getOfferVersionByID(ctx, db, gameID, offerInstanceID, mr)
+		offerInstance, err = getOfferVersionByID(ctx, tx, gameID, offerInstanceID, mr)
 		if err != nil {
 			return nil, false, 0, err
 		}
 	} else {
-		offerInstance, err = getLastOfferInstanceByPlayerIDAndProductID(ctx, db, gameID, playerID, productID, timestamp, mr)
+		offerInstance, err = getLastOfferInstanceByPlayerIDAndProductID(ctx, tx, gameID, playerID, productID, timestamp, mr)
 		if err != nil {
 			return nil, false, 0, err
 		}
 	}
-	offerPlayer, err := GetOfferPlayer(ctx, db, gameID, playerID, offerInstance.OfferID, mr)
+	if receipt != nil {
+		if receipt.ProductID != offerInstance.ProductID {
+			return nil, false, 0, errors.NewReceiptRejectedError(transactionID, "the transaction is for another product")
+		}
+		registered, err := registerReceiptTransaction(ctx, tx, gameID, playerID, offerInstance.OfferID, transactionID, t, mr)
+		if err != nil {
+			return nil, false, 0, err
+		}
+		if !registered {
+			return nil, false, 0, errors.NewReceiptRejectedError(transactionID, "the transaction already claimed another offer")
+		}
+	}
+	testPlayer, err := IsTestPlayer(ctx, tx, gameID, playerID, mr)
+	if err != nil {
+		return nil, false, 0, err
+	}
+	offerPlayer, err := GetOfferPlayer(ctx, tx, gameID, playerID, offerInstance.OfferID, mr)
 	if err == nil {
 		previousOfferPlayer = true
 	} else if !IsNoRowsInResultSetError(err) {
 		return nil, false, 0, err
 	} else {
 		offerPlayer = &OfferPlayer{
-			GameID:       gameID,
-			PlayerID:     playerID,
-			OfferID:      offerInstance.OfferID,
-			Transactions: dat.JSON([]byte(`[]`)),
-			Impressions:  dat.JSON([]byte(`[]`)),
+			GameID:   gameID,
+			PlayerID: playerID,
+			OfferID:  offerInstance.OfferID,
 		}
 	}
+	offerPlayer.TestPlayer = offerPlayer.TestPlayer || testPlayer
 
-	isReplay := false
-	var transactions []string
-	err = offerPlayer.Transactions.Unmarshal(&transactions)
-
+	registered, err := registerDedupeKey(ctx, tx, gameID, playerID, offerInstance.OfferID, DedupeKindTransaction, transactionID, t, mr)
 	if err != nil {
 		return nil, false, 0, err
 	}
-	for _, tr := range transactions {
-		if transactionID == tr {
-			isReplay = true
-			break
+	isReplay := !registered
+	if !isReplay {
+		inserted, err := insertClaim(ctx, tx, &Claim{
+			GameID:          gameID,
+			PlayerID:        playerID,
+			OfferID:         offerInstance.OfferID,
+			OfferVersionID:  offerInstance.ID,
+			OfferVersion:    offerInstance.OfferVersion,
+			ProductID:       offerInstance.ProductID,
+			Cost:            offerInstance.Cost,
+			Contents:        offerInstance.Contents,
+			TransactionID:   transactionID,
+			ClientTimestamp: time.Unix(timestamp, 0),
+			ClaimedAt:       t,
+			TestPlayer:      testPlayer,
+		}, mr)
+		if err != nil {
+			return nil, false, 0, err
+		}
+		isReplay = !inserted
+	}
+	// Test players don't take the stock of real players
+	if !isReplay && !testPlayer {
+		err = claimStock(ctx, tx, gameID, offerInstance.OfferID, mr)
+		if err != nil {
+			return nil, false, 0, err
 		}
 	}
 	if isReplay {
 		nextAt, err = getClaimedOfferNextAt(
-			ctx, db, gameID, offerInstance.OfferID,
+			ctx, tx, gameID, offerInstance.OfferID,
 			offerPlayer.ClaimCounter, offerPlayer.ClaimTimestamp.Time, mr)
 		if err != nil {
 			return nil, false, 0, err
 		}
-		return offerInstance.Contents, true, nextAt, nil
+		return offerInstance.Contents, true, nextAt, tx.Commit()
 	}
 
-	if previousOfferPlayer {
-		jsonTr, err := dat.NewJSON(append(transactions, transactionID))
+	if receipt != nil {
+		receipts := map[string]*ReceiptVerification{}
+		if offerPlayer.Receipts != nil {
+			err = offerPlayer.Receipts.Unmarshal(&receipts)
+			if err != nil {
+				return nil, false, 0, err
+			}
+		}
+		receipts[transactionID] = receipt
+		jsonReceipts, err := dat.NewJSON(receipts)
 		if err != nil {
 			return nil, false, 0, err
 		}
-		offerPlayer.Transactions = *jsonTr
-		err = ClaimOfferPlayer(ctx, db, offerPlayer, time.Unix(timestamp, 0), mr)
+		offerPlayer.Receipts = *jsonReceipts
+	}
+
+	if previousOfferPlayer {
+		err = ClaimOfferPlayer(ctx, tx, offerPlayer, time.Unix(timestamp, 0), mr)
 		if err != nil {
 			return nil, false, 0, err
 		}
 	} else {
 		offerPlayer.ClaimCounter = 1
 		offerPlayer.ClaimTimestamp = dat.NullTimeFrom(time.Unix(timestamp, 0))
-		offerPlayer.Transactions = dat.JSON([]byte(fmt.Sprintf(`["%s"]`, transactionID)))
-		err = CreateOfferPlayer(ctx, db, offerPlayer, mr)
+		err = CreateOfferPlayer(ctx, tx, offerPlayer, mr)
 		if err != nil {
 			return nil, false, 0, err
 		}
 	}
 
 	nextAt, err = getClaimedOfferNextAt(
-		ctx, db, gameID, offerInstance.OfferID,
+		ctx, tx, gameID, offerInstance.OfferID,
 		offerPlayer.ClaimCounter, time.Unix(timestamp, 0), mr)
 	if err != nil {
 		return nil, false, 0, err
 	}
-	return offerInstance.Contents, false, nextAt, nil
+	return offerInstance.Contents, false, nextAt, tx.Commit()
 }
 
 //ViewOffer views the offer
 func ViewOffer(
 	ctx context.Context,
 	db runner.Connection,
+	offersCache *cache.Cache,
 	gameID, offerInstanceID, playerID, impressionID string,
 	t time.Time,
+	expireDuration time.Duration,
 	mr *MixedMetricsReporter,
 ) (bool, int64, error) {
 	var nextAt int64
 	var previousOfferPlayer bool
 
-	offerInstance, err := getOfferVersionAndOfferEnabled(ctx, db, gameID, offerInstanceID, mr)
+	tx, err := db.Begin()
 	if err != nil {
 		return false, 0, err
 	}
+	defer tx.AutoRollback()
 
-	// Offer is disabled
-	if !offerInstance.Enabled {
-		return false, 0, nil
+	offerInstance, err := getOfferVersionAndOfferEnabled(ctx, tx, gameID, offerInstanceID, mr)
+	if err != nil {
+		return false, 0, err
 	}
 
-	offerPlayer, err := GetOfferPlayer(ctx, db, gameID, playerID, offerInstance.OfferID, mr)
+	testPlayer, err := IsTestPlayer(ctx, tx, gameID, playerID, mr)
+	if err != nil {
+		return false, 0, err
+	}
+
+	// Offer is disabled, only test players can see it
+	if !offerInstance.Enabled && !testPlayer {
+		return false, 0, tx.Commit()
+	}
+
+	offerPlayer, err := GetOfferPlayer(ctx, tx, gameID, playerID, offerInstance.OfferID, mr)
 	if err == nil {
 		previousOfferPlayer = true
 	} else if !IsNoRowsInResultSetError(err) {
 		return false, 0, err
 	} else {
 		offerPlayer = &OfferPlayer{
-			GameID:       gameID,
-			PlayerID:     playerID,
-			OfferID:      offerInstance.OfferID,
-			Transactions: dat.JSON([]byte(`[]`)),
-			Impressions:  dat.JSON([]byte(`[]`)),
+			GameID:   gameID,
+			PlayerID: playerID,
+			OfferID:  offerInstance.OfferID,
 		}
 	}
+	offerPlayer.TestPlayer = offerPlayer.TestPlayer || testPlayer
 
-	isReplay := false
-	var impressions []string
-	err = offerPlayer.Impressions.Unmarshal(&impressions)
+	registered, err := registerDedupeKey(ctx, tx, gameID, playerID, offerInstance.OfferID, DedupeKindImpression, impressionID, t, mr)
 	if err != nil {
 		return false, 0, err
 	}
-	for _, imp := range impressions {
-		if impressionID == imp {
-			isReplay = true
-			break
-		}
-	}
-
-	if isReplay {
-		nextAt, err = getViewedOfferNextAt(ctx, db, gameID, offerInstance.OfferID, offerPlayer.ViewCounter, t, mr)
+	if !registered {
+		nextAt, err = getViewedOfferNextAt(ctx, tx, gameID, offerInstance.OfferID, offerPlayer.ViewCounter, t, mr)
 		if err != nil {
 			return false, 0, err
 		}
-		return true, nextAt, nil
+		return true, nextAt, tx.Commit()
 	}
 
 	if previousOfferPlayer {
-		jsonTr, err := dat.NewJSON(append(impressions, impressionID))
-		if err != nil {
-			return false, 0, err
-		}
-		offerPlayer.Impressions = *jsonTr
-		err = ViewOfferPlayer(ctx, db, offerPlayer, t, mr)
+		err = ViewOfferPlayer(ctx, tx, offerPlayer, t, mr)
 		if err != nil {
 			return false, 0, err
 		}
 	} else {
 		offerPlayer.ViewCounter = 1
 		offerPlayer.ViewTimestamp = dat.NullTimeFrom(t)
-		offerPlayer.Impressions = dat.JSON([]byte(fmt.Sprintf(`["%s"]`, impressionID)))
-		err = CreateOfferPlayer(ctx, db, offerPlayer, mr)
+		offerPlayer.FirstViewTimestamp = dat.NullTimeFrom(t)
+		err = CreateOfferPlayer(ctx, tx, offerPlayer, mr)
+		if err != nil {
+			return false, 0, err
+		}
+	}
+
+	if !testPlayer {
+		err = countImpression(ctx, tx, offersCache, gameID, playerID, offerInstance.Placement, t, expireDuration, mr)
 		if err != nil {
 			return false, 0, err
 		}
 	}
 
-	nextAt, err = getViewedOfferNextAt(ctx, db, gameID, offerInstance.OfferID, offerPlayer.ViewCounter, t, mr)
+	nextAt, err = getViewedOfferNextAt(ctx, tx, gameID, offerInstance.OfferID, offerPlayer.ViewCounter, t, mr)
 	if err != nil {
 		return false, 0, err
 	}
-	return false, nextAt, nil
+	return false, nextAt, tx.Commit()
 }
 
-//GetAvailableOffers returns the offers that match the criteria of enabled offer templates
+//GetAvailableOffers returns the offers that match the criteria of enabled offer templates.
+//Test players get every offer of the game that matches, with its draft if there is one,
+//regardless of frequency, period and prerequisites.
+//The rotation, ordering, maximum and fallback offer of the placements of the game are then applied. Placements whose
+//impression cap the player reached are removed, or every placement if the player reached the cap of the game.
 func GetAvailableOffers(
 	ctx context.Context,
 	db runner.Connection,
@@ -290,8 +374,66 @@ func GetAvailableOffers(
 	allowInefficientQueries bool,
 	mr *MixedMetricsReporter,
 ) (map[string][]*OfferToReturn, error) {
+	offersByPlacement, playerOffersByOfferID, err := getAvailableOffers(
+		ctx, db, offersCache, gameID, playerID, t, expireDuration, filterAttrs, allowInefficientQueries, mr,
+	)
+	if err != nil {
+		return nil, err
+	}
+	placements, err := getPlacements(ctx, db, gameID, offersCache, expireDuration, mr)
+	if err != nil {
+		return nil, err
+	}
+	err = applyPlacements(
+		ctx, db, gameID, playerID, offersByPlacement, playerOffersByOfferID, placements, t, filterAttrs, allowInefficientQueries, mr,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	impressionCaps, err := getImpressionCaps(ctx, db, gameID, placements, offersCache, expireDuration, mr)
+	if err != nil {
+		return nil, err
+	}
+	if len(impressionCaps) == 0 {
+		return offersByPlacement, nil
+	}
+	playerImpressions, err := getPlayerImpressions(ctx, db, gameID, playerID, mr)
+	if err != nil {
+		return nil, err
+	}
+	applyImpressionCaps(offersByPlacement, impressionCapStates(impressionCaps, playerImpressions, t))
+	return offersByPlacement, nil
+}
+
+//getAvailableOffers returns the available offers of a player by placement and the offers seen or
+//claimed by the player by offer id, which is nil for test players since their rules are not applied
+func getAvailableOffers(
+	ctx context.Context,
+	db runner.Connection,
+	offersCache *cache.Cache,
+	gameID, playerID string,
+	t time.Time,
+	expireDuration time.Duration,
+	filterAttrs map[string]string,
+	allowInefficientQueries bool,
+	mr *MixedMetricsReporter,
+) (map[string][]*OfferToReturn, map[string]*OfferPlayer, error) {
 	offersByPlacement := make(map[string][]*OfferToReturn)
 
+	testPlayer, err := isCachedTestPlayer(ctx, db, gameID, playerID, offersCache, expireDuration, mr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if testPlayer {
+		testOffers, err := getTestPlayerOffers(ctx, db, gameID, t, filterAttrs, allowInefficientQueries, mr)
+		if err != nil {
+			return nil, nil, err
+		}
+		offersByPlacement, err = getOffersByPlacement(ctx, db, playerID, testOffers, nil, t, mr)
+		return offersByPlacement, nil, err
+	}
+
 	enabledOffers, err := GetEnabledOffers(
 		ctx,
 		db,
@@ -304,16 +446,50 @@ func GetAvailableOffers(
 		mr,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	enabledOffers, err = filterSoldOutOffers(ctx, db, gameID, enabledOffers, mr)
+	if err != nil {
+		return nil, nil, err
+	}
+	offersByPlayer, err := GetOffersByPlayer(ctx, db, gameID, playerID, mr)
+	if err != nil {
+		return nil, nil, err
+	}
+	playerOffersByOfferID := indexOfferPlayers(offersByPlayer)
 	if len(enabledOffers) == 0 {
-		return offersByPlacement, nil
+		return offersByPlacement, playerOffersByOfferID, nil
 	}
 
-	offersByPlayer, err := GetOffersByPlayer(ctx, db, gameID, playerID, mr)
+	enabledOffers, err = filterOffersByPrerequisites(enabledOffers, offersByPlayer)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	offersByPlacement, err = getOffersByPlacement(ctx, db, playerID, enabledOffers, offersByPlayer, t, mr)
+	return offersByPlacement, playerOffersByOfferID, err
+}
+
+//indexOfferPlayers returns the offers seen or claimed by a player by offer id
+func indexOfferPlayers(offersByPlayer []*OfferPlayer) map[string]*OfferPlayer {
+	playerOffersByOfferID := map[string]*OfferPlayer{}
+	for _, playerOffer := range offersByPlayer {
+		playerOffersByOfferID[playerOffer.OfferID] = playerOffer
+	}
+	return playerOffersByOfferID
+}
+
+//getOffersByPlacement returns the enabled offers that the player can see, given the offers seen or claimed by them.
+//The offers of each placement are sorted by priority and only the first offer of each exclusion group is returned.
+func getOffersByPlacement(
+	ctx context.Context,
+	db runner.Connection,
+	playerID string,
+	enabledOffers []*Offer,
+	offersByPlayer []*OfferPlayer,
+	t time.Time,
+	mr *MixedMetricsReporter,
+) (map[string][]*OfferToReturn, error) {
+	offersByPlacement := make(map[string][]*OfferToReturn)
 
 	filteredOffers, err := filterOffersByFrequencyAndPeriod(playerID, enabledOffers, offersByPlayer, t, mr)
 	if err != nil {
@@ -322,12 +498,10 @@ func GetAvailableOffers(
 	if len(filteredOffers) == 0 {
 		return offersByPlacement, nil
 	}
+	filteredOffers = filterOffersByExclusionGroup(filteredOffers)
 
 	var offerVersions []*OfferVersion
-	offers := make(map[string]*Offer)
-
 	for _, offer := range filteredOffers {
-		offers[offer.ID] = offer
 		offerVersions = append(offerVersions, &OfferVersion{
 			GameID:       offer.GameID,
 			OfferID:      offer.ID,
@@ -340,18 +514,42 @@ func GetAvailableOffers(
 		return nil, err
 	}
 
+	playerOffersByOfferID := map[string]*OfferPlayer{}
+	for _, playerOffer := range offersByPlayer {
+		playerOffersByOfferID[playerOffer.OfferID] = playerOffer
+	}
+
+	offerInstancesByOfferID := map[string]*OfferVersion{}
 	for _, offerInstance := range offerVersions {
-		offer := offers[offerInstance.OfferID]
+		offerInstancesByOfferID[offerInstance.OfferID] = offerInstance
+	}
+
+	for _, offer := range filteredOffers {
+		offerInstance, ok := offerInstancesByOfferID[offer.ID]
+		if !ok {
+			continue
+		}
 
 		var trigger Times
 		json.Unmarshal(offer.Trigger, &trigger)
+		var firstView dat.NullTime
+		if offerPlayer, ok := playerOffersByOfferID[offer.ID]; ok {
+			firstView = offerPlayer.FirstViewTimestamp
+		}
+		expireAt, err := trigger.PlayerExpireAt(firstView)
+		if err != nil {
+			return nil, err
+		}
 		offerToReturn := &OfferToReturn{
-			ID:        offerInstance.ID,
-			ProductID: offer.ProductID,
-			Contents:  offer.Contents,
-			Cost:      offer.Cost,
-			Metadata:  offer.Metadata,
-			ExpireAt:  trigger.To,
+			ID:             offerInstance.ID,
+			OfferID:        offer.ID,
+			ProductID:      offer.ProductID,
+			Contents:       offer.Contents,
+			Cost:           offer.Cost,
+			Metadata:       offer.Metadata,
+			ExpireAt:       expireAt,
+			RemainingStock: offer.remainingStock(),
+			HasDraft:       offer.HasDraft,
 		}
 
 		if _, offerInMap := offersByPlacement[offer.Placement]; !offerInMap {
@@ -364,6 +562,93 @@ func GetAvailableOffers(
 	return offersByPlacement, nil
 }
 
+//filterSoldOutOffers removes the offers whose stock is sold out. The enabled offers may come from
+//the cache, so the stock of the offers that have one is read again and the offers are copied.
+func filterSoldOutOffers(
+	ctx context.Context,
+	db runner.Connection,
+	gameID string,
+	offers []*Offer,
+	mr *MixedMetricsReporter,
+) ([]*Offer, error) {
+	limited := false
+	for _, offer := range offers {
+		limited = limited || offer.Stock.Valid
+	}
+	if !limited {
+		return offers, nil
+	}
+
+	stocks := []*Offer{}
+	err := mr.WithDatastoreSegment("offers", SegmentSelect, func() error {
+		builder := db.Select("id, stock, stock_sold")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("offers").
+			Where("game_id = $1 AND stock IS NOT NULL", gameID).
+			QueryStructs(&stocks)
+	})
+	if err != nil {
+		return nil, err
+	}
+	stocksByOfferID := map[string]*Offer{}
+	for _, stock := range stocks {
+		stocksByOfferID[stock.ID] = stock
+	}
+
+	availableOffers := make([]*Offer, 0, len(offers))
+	for _, offer := range offers {
+		if offer.Stock.Valid {
+			stockedOffer := *offer
+			stockedOffer.Stock, stockedOffer.StockSold = dat.NullInt64{}, 0
+			if stock, ok := stocksByOfferID[offer.ID]; ok {
+				stockedOffer.Stock, stockedOffer.StockSold = stock.Stock, stock.StockSold
+			}
+			if remaining := stockedOffer.remainingStock(); remaining != nil && *remaining == 0 {
+				continue
+			}
+			offer = &stockedOffer
+		}
+		availableOffers = append(availableOffers, offer)
+	}
+	return availableOffers, nil
+}
+
+//claimStock takes one unit of the stock of the offer, if it has one. The row of the offer stays
+//locked until the transaction ends, so concurrent claims never sell more than the stock.
+//It returns an OfferSoldOutError if there is no stock left.
+func claimStock(ctx context.Context, db runner.Connection, gameID, offerID string, mr *MixedMetricsReporter) error {
+	offer, err := GetOfferByID(ctx, db, gameID, offerID, mr)
+	if err != nil {
+		return err
+	}
+	if !offer.Stock.Valid {
+		return nil
+	}
+
+	var claimed int64
+	err = mr.WithDatastoreSegment("offers", SegmentUpdate, func() error {
+		builder := db.SQL(`
+			UPDATE offers SET stock_sold = stock_sold + 1
+			WHERE id = $1 AND game_id = $2 AND stock IS NOT NULL AND stock_sold < stock`,
+			offerID, gameID,
+		)
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		res, err := builder.Exec()
+		if err != nil {
+			return err
+		}
+		claimed = res.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if claimed == 0 {
+		return errors.NewOfferSoldOutError(offerID)
+	}
+	return nil
+}
+
 func filterOffersByFrequencyAndPeriod(
 	playerID string,
 	offers []*Offer,
@@ -375,51 +660,75 @@ func filterOffersByFrequencyAndPeriod(
 	for _, playerOffer := range playerOffers {
 		playerOffersByOfferID[playerOffer.OfferID] = playerOffer
 	}
-	var err error
 	var filteredOffers []*Offer
 	for _, offer := range offers {
-		var (
-			f FrequencyOrPeriod
-			p FrequencyOrPeriod
-		)
-		if err = json.Unmarshal(offer.Frequency, &f); err != nil {
-			return nil, err
-		}
-		if err = json.Unmarshal(offer.Period, &p); err != nil {
-			return nil, err
-		}
-
 		offerPlayer := &OfferPlayer{}
 		if val, ok := playerOffersByOfferID[offer.ID]; ok {
 			offerPlayer = val
 		}
 
-		if f.Max != 0 && offerPlayer.ViewCounter >= f.Max {
-			continue
-		}
-		if f.Every != "" {
-			duration, err := time.ParseDuration(f.Every)
-			if err != nil {
-				return nil, err
-			}
-			if offerPlayer.ViewTimestamp.Time.Add(duration).After(t) {
-				continue
-			}
+		reason, _, err := frequencyAndPeriodReason(offer, offerPlayer, t)
+		if err != nil {
+			return nil, err
 		}
-		if p.Max != 0 && offerPlayer.ClaimCounter >= p.Max {
+		if reason != "" {
 			continue
 		}
-		if p.Every != "" {
-			duration, err := time.ParseDuration(p.Every)
-			if err != nil {
-				return nil, err
-			}
-			if offerPlayer.ClaimTimestamp.Time.Add(duration).After(t) {
-				continue
-			}
-		}
 		filteredOffers = append(filteredOffers, offer)
 	}
 
 	return filteredOffers, nil
 }
+
+//frequencyAndPeriodReason returns why the frequency or period of the offer, or the duration of
+//its trigger since the player first saw it, don't allow the player to see it at time t, or an
+//empty string if they do. If the player has to wait it also returns until when.
+func frequencyAndPeriodReason(offer *Offer, offerPlayer *OfferPlayer, t time.Time) (string, time.Time, error) {
+	var (
+		f       FrequencyOrPeriod
+		p       FrequencyOrPeriod
+		trigger Times
+	)
+	if err := json.Unmarshal(offer.Trigger, &trigger); err != nil {
+		return "", time.Time{}, err
+	}
+	expireAt, err := trigger.PlayerExpireAt(offerPlayer.FirstViewTimestamp)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if t.Unix() > expireAt {
+		return UnavailablePlayerExpired, time.Time{}, nil
+	}
+	if err := json.Unmarshal(offer.Frequency, &f); err != nil {
+		return "", time.Time{}, err
+	}
+	if err := json.Unmarshal(offer.Period, &p); err != nil {
+		return "", time.Time{}, err
+	}
+
+	if f.Max != 0 && offerPlayer.ViewCounter >= f.Max {
+		return UnavailableMaxViews, time.Time{}, nil
+	}
+	if f.Every != "" {
+		duration, err := time.ParseDuration(f.Every)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		if until := offerPlayer.ViewTimestamp.Time.Add(duration); until.After(t) {
+			return UnavailableFrequency, until, nil
+		}
+	}
+	if p.Max != 0 && offerPlayer.ClaimCounter >= p.Max {
+		return UnavailableMaxClaims, time.Time{}, nil
+	}
+	if p.Every != "" {
+		duration, err := time.ParseDuration(p.Every)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		if until := offerPlayer.ClaimTimestamp.Time.Add(duration); until.After(t) {
+			return UnavailablePeriod, until, nil
+		}
+	}
+	return "", time.Time{}, nil
+}
@@ -8,12 +8,14 @@
 package models_test
 
 import (
+	"fmt"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/satori/go.uuid"
 	edat "github.com/topfreegames/extensions/dat"
+	"github.com/topfreegames/offers/errors"
 	"github.com/topfreegames/offers/models"
 	. "github.com/topfreegames/offers/testing"
 	"gopkg.in/mgutz/dat.v2/dat"
@@ -337,7 +339,7 @@ var _ = Describe("Offer Instance Model", func() {
 			currentTime := time.Now()
 
 			//When
-			isReplay, nextAt, err := models.ViewOffer(nil, db, gameID, offerInstanceID, playerID, impressionID, currentTime, nil)
+			isReplay, nextAt, err := models.ViewOffer(nil, db, offersCache, gameID, offerInstanceID, playerID, impressionID, currentTime, cacheExpireDuration, nil)
 			Expect(isReplay).To(BeFalse())
 			Expect(err).NotTo(HaveOccurred())
 
@@ -358,7 +360,7 @@ var _ = Describe("Offer Instance Model", func() {
 			impressionID := uuid.NewV4().String()
 
 			//When
-			isReplay, nextAt, err := models.ViewOffer(nil, db, gameID, offerInstanceID, playerID, impressionID, currentTime, nil)
+			isReplay, nextAt, err := models.ViewOffer(nil, db, offersCache, gameID, offerInstanceID, playerID, impressionID, currentTime, cacheExpireDuration, nil)
 			Expect(isReplay).To(BeFalse())
 
 			//Then
@@ -375,7 +377,7 @@ var _ = Describe("Offer Instance Model", func() {
 			impressionID := uuid.NewV4().String()
 
 			//When
-			isReplay, nextAt, err := models.ViewOffer(nil, db, gameID, offerInstanceID, playerID, impressionID, currentTime, nil)
+			isReplay, nextAt, err := models.ViewOffer(nil, db, offersCache, gameID, offerInstanceID, playerID, impressionID, currentTime, cacheExpireDuration, nil)
 			Expect(isReplay).To(BeFalse())
 
 			//Then
@@ -393,7 +395,7 @@ var _ = Describe("Offer Instance Model", func() {
 			impressionID := uuid.NewV4().String()
 
 			//When
-			isReplay, nextAt, err := models.ViewOffer(nil, db, gameID, offerInstanceID, playerID, impressionID, currentTime, nil)
+			isReplay, nextAt, err := models.ViewOffer(nil, db, offersCache, gameID, offerInstanceID, playerID, impressionID, currentTime, cacheExpireDuration, nil)
 			Expect(isReplay).To(BeFalse())
 			Expect(err).NotTo(HaveOccurred())
 
@@ -414,7 +416,7 @@ var _ = Describe("Offer Instance Model", func() {
 			currentTime := time.Now()
 
 			//When
-			isReplay, nextAt, err := models.ViewOffer(nil, db, gameID, offerInstanceID, playerID, impressionID, currentTime, nil)
+			isReplay, nextAt, err := models.ViewOffer(nil, db, offersCache, gameID, offerInstanceID, playerID, impressionID, currentTime, cacheExpireDuration, nil)
 			Expect(isReplay).To(BeFalse())
 
 			//Then
@@ -432,7 +434,7 @@ var _ = Describe("Offer Instance Model", func() {
 			currentTime := time.Now()
 
 			//When
-			isReplay, nextAt, err := models.ViewOffer(nil, db, gameID, offerInstanceID, playerID, impressionID, currentTime, nil)
+			isReplay, nextAt, err := models.ViewOffer(nil, db, offersCache, gameID, offerInstanceID, playerID, impressionID, currentTime, cacheExpireDuration, nil)
 			Expect(isReplay).To(BeFalse())
 
 			//Then
@@ -450,7 +452,7 @@ var _ = Describe("Offer Instance Model", func() {
 			currentTime := time.Now()
 
 			//When
-			isReplay, nextAt, err := models.ViewOffer(nil, db, gameID, offerInstanceID, playerID, impressionID, currentTime, nil)
+			isReplay, nextAt, err := models.ViewOffer(nil, db, offersCache, gameID, offerInstanceID, playerID, impressionID, currentTime, cacheExpireDuration, nil)
 			Expect(isReplay).To(BeFalse())
 
 			//Then
@@ -469,10 +471,10 @@ var _ = Describe("Offer Instance Model", func() {
 			currentTime := time.Now()
 
 			//When
-			isReplay, nextAt, err := models.ViewOffer(nil, db, gameID, offerInstanceID, playerID, impressionID, currentTime, nil)
+			isReplay, nextAt, err := models.ViewOffer(nil, db, offersCache, gameID, offerInstanceID, playerID, impressionID, currentTime, cacheExpireDuration, nil)
 			Expect(isReplay).To(BeFalse())
 			Expect(err).NotTo(HaveOccurred())
-			isReplay, nextAt, err = models.ViewOffer(nil, db, gameID, offerInstanceID, playerID, impressionID, currentTime, nil)
+			isReplay, nextAt, err = models.ViewOffer(nil, db, offersCache, gameID, offerInstanceID, playerID, impressionID, currentTime, cacheExpireDuration, nil)
 			Expect(isReplay).To(BeTrue())
 			Expect(err).NotTo(HaveOccurred())
 
@@ -494,11 +496,11 @@ var _ = Describe("Offer Instance Model", func() {
 			impressionID := uuid.NewV4().String()
 
 			//When
-			isReplay, nextAt, err := models.ViewOffer(nil, db, gameID, offerInstanceID, playerID, impressionID, currentTime, nil)
+			isReplay, nextAt, err := models.ViewOffer(nil, db, offersCache, gameID, offerInstanceID, playerID, impressionID, currentTime, cacheExpireDuration, nil)
 			Expect(isReplay).To(BeFalse())
 			Expect(err).NotTo(HaveOccurred())
 			impressionID = uuid.NewV4().String()
-			isReplay, nextAt, err = models.ViewOffer(nil, db, gameID, offerInstanceID, playerID, impressionID, currentTime, nil)
+			isReplay, nextAt, err = models.ViewOffer(nil, db, offersCache, gameID, offerInstanceID, playerID, impressionID, currentTime, cacheExpireDuration, nil)
 			Expect(isReplay).To(BeFalse())
 			Expect(err).NotTo(HaveOccurred())
 
@@ -714,7 +716,7 @@ var _ = Describe("Offer Instance Model", func() {
 			impressionID := uuid.NewV4().String()
 
 			//When
-			_, _, err := models.ViewOffer(nil, db, gameID, defaultOfferInstanceID, playerID, impressionID, currentTime, nil)
+			_, _, err := models.ViewOffer(nil, db, offersCache, gameID, defaultOfferInstanceID, playerID, impressionID, currentTime, cacheExpireDuration, nil)
 			Expect(err).NotTo(HaveOccurred())
 			offerInstances, err := models.GetAvailableOffers(nil, db, offersCache, gameID, playerID, nextTime, expireDuration, filterAttrs, false, nil)
 			Expect(err).NotTo(HaveOccurred())
@@ -866,7 +868,7 @@ var _ = Describe("Offer Instance Model", func() {
 			offerInstanceID := "eb7e8d2a-2739-4da3-aa31-7970b63bdad7"
 
 			//When
-			offerInstance, err := models.GetOfferInfo(nil, db, gameID, offerInstanceID, expireDuration, nil)
+			offerInstance, err := models.GetOfferInfo(nil, db, gameID, defaultPlayerID, offerInstanceID, expireDuration, nil)
 
 			//Then
 			Expect(err).NotTo(HaveOccurred())
@@ -884,7 +886,7 @@ var _ = Describe("Offer Instance Model", func() {
 			offerInstanceID := "abcd8d2a-2739-4da3-aa31-8970b63bdad7"
 
 			//When
-			offerInstance, err := models.GetOfferInfo(nil, db, gameID, offerInstanceID, expireDuration, nil)
+			offerInstance, err := models.GetOfferInfo(nil, db, gameID, defaultPlayerID, offerInstanceID, expireDuration, nil)
 
 			//Then
 			Expect(err).NotTo(HaveOccurred())
@@ -902,7 +904,7 @@ var _ = Describe("Offer Instance Model", func() {
 			offerInstanceID := "eb7e8d2a-2739-4da3-aa31-7970b63bdad7"
 
 			//When
-			_, err := models.GetOfferInfo(nil, db, gameID, offerInstanceID, expireDuration, nil)
+			_, err := models.GetOfferInfo(nil, db, gameID, defaultPlayerID, offerInstanceID, expireDuration, nil)
 
 			//Then
 			Expect(err).To(HaveOccurred())
@@ -923,7 +925,7 @@ var _ = Describe("Offer Instance Model", func() {
 			db.(*runner.DB).DB.Close() // make DB connection unavailable
 
 			//When
-			_, err = models.GetOfferInfo(nil, db, gameID, offerInstanceID, expireDuration, nil)
+			_, err = models.GetOfferInfo(nil, db, gameID, defaultPlayerID, offerInstanceID, expireDuration, nil)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(Equal("sql: database is closed"))
 		})
@@ -987,7 +989,7 @@ var _ = Describe("Offer Instance Model", func() {
 			offerInstanceID := offerInstances[place][0].ID
 
 			// View once
-			_, _, err = models.ViewOffer(nil, db, gameID, offerInstanceID, playerID, uuid.NewV4().String(), currentTime, nil)
+			_, _, err = models.ViewOffer(nil, db, offersCache, gameID, offerInstanceID, playerID, uuid.NewV4().String(), currentTime, cacheExpireDuration, nil)
 			Expect(err).NotTo(HaveOccurred())
 
 			// Update Offer
@@ -1009,7 +1011,7 @@ var _ = Describe("Offer Instance Model", func() {
 			offerInstanceID = offerInstances[place][0].ID
 
 			// Sees twice
-			_, _, err = models.ViewOffer(nil, db, gameID, offerInstanceID, playerID, uuid.NewV4().String(), currentTime, nil)
+			_, _, err = models.ViewOffer(nil, db, offersCache, gameID, offerInstanceID, playerID, uuid.NewV4().String(), currentTime, cacheExpireDuration, nil)
 			Expect(err).NotTo(HaveOccurred())
 
 			// Get offer, expect unique-place to not be returned
@@ -1019,4 +1021,286 @@ var _ = Describe("Offer Instance Model", func() {
 			Expect(offerInstances).NotTo(HaveKey(place))
 		})
 	})
+
+	Describe("Stock", func() {
+		currentTime := time.Unix(1486678000, 0)
+
+		setStock := func(stock int64) {
+			offer := new(models.Offer)
+			builder := db.SQL("SELECT * FROM offers WHERE id = $1 AND game_id = $2", defaultOfferID, defaultGameID)
+			builder.Execer = edat.NewExecer(builder.Execer)
+			err := builder.QueryStruct(offer)
+			Expect(err).NotTo(HaveOccurred())
+			offer.Stock = dat.NullInt64From(stock)
+			_, err = models.UpdateOffer(nil, db, offer, offersCache, nil)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		findStockedOffer := func(playerID string) *models.OfferToReturn {
+			offerInstances, err := models.GetAvailableOffers(nil, db, offersCache, defaultGameID, playerID, currentTime, expireDuration, map[string]string{}, false, nil)
+			Expect(err).NotTo(HaveOccurred())
+			for _, offer := range offerInstances["popup"] {
+				if offer.RemainingStock != nil {
+					return offer
+				}
+			}
+			return nil
+		}
+
+		It("should hide the offer and reject claims after its stock is sold out", func() {
+			setStock(1)
+			offer := findStockedOffer("stock-player-1")
+			Expect(offer).NotTo(BeNil())
+			Expect(*offer.RemainingStock).To(BeEquivalentTo(1))
+
+			_, _, _, err := models.ClaimOffer(nil, db, defaultGameID, offer.ID, "stock-player-1", defaultProductID, uuid.NewV4().String(), currentTime.Unix(), currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(findStockedOffer("stock-player-2")).To(BeNil())
+
+			offerInfo, err := models.GetOfferInfo(nil, db, defaultGameID, "stock-player-1", offer.ID, expireDuration, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*offerInfo.RemainingStock).To(BeEquivalentTo(0))
+
+			_, _, _, err = models.ClaimOffer(nil, db, defaultGameID, offer.ID, "stock-player-2", defaultProductID, uuid.NewV4().String(), currentTime.Unix(), currentTime, nil)
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.OfferSoldOutError)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should not take stock on replayed claims or claims of test players", func() {
+			setStock(1)
+			offer := findStockedOffer("stock-player-1")
+			Expect(offer).NotTo(BeNil())
+			err := models.AddTestPlayer(nil, db, &models.TestPlayer{GameID: defaultGameID, PlayerID: "stock-qa"}, offersCache, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, _, err = models.ClaimOffer(nil, db, defaultGameID, offer.ID, "stock-qa", defaultProductID, uuid.NewV4().String(), currentTime.Unix(), currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+			transactionID := uuid.NewV4().String()
+			for i := 0; i < 2; i++ {
+				_, _, _, err = models.ClaimOffer(nil, db, defaultGameID, offer.ID, "stock-player-1", defaultProductID, transactionID, currentTime.Unix(), currentTime, nil)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			offers, _, err := models.ListOffers(nil, db, defaultGameID, 100, 0, nil)
+			Expect(err).NotTo(HaveOccurred())
+			for _, o := range offers {
+				if o.ID == defaultOfferID {
+					Expect(o.StockSold).To(BeEquivalentTo(1))
+					Expect(*o.RemainingStock).To(BeEquivalentTo(0))
+				}
+			}
+		})
+	})
+
+	Describe("Player expiration", func() {
+		It("should expire the offer for each player after the duration of its trigger", func() {
+			currentTime := time.Unix(1486678000, 0)
+			playerID := "flash-player"
+			offer := new(models.Offer)
+			builder := db.SQL("SELECT * FROM offers WHERE id = $1 AND game_id = $2", defaultOfferID, defaultGameID)
+			builder.Execer = edat.NewExecer(builder.Execer)
+			err := builder.QueryStruct(offer)
+			Expect(err).NotTo(HaveOccurred())
+			offer.Trigger = dat.JSON([]byte(`{"from": 1486678000, "to": 1486679000, "duration": "100s"}`))
+			_, err = models.UpdateOffer(nil, db, offer, offersCache, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			findOffer := func(t time.Time) *models.OfferToReturn {
+				offerInstances, err := models.GetAvailableOffers(nil, db, offersCache, defaultGameID, playerID, t, expireDuration, map[string]string{}, false, nil)
+				Expect(err).NotTo(HaveOccurred())
+				for _, offerInstance := range offerInstances["popup"] {
+					if offerInstance.ProductID == defaultProductID {
+						return offerInstance
+					}
+				}
+				return nil
+			}
+
+			offerInstance := findOffer(currentTime)
+			Expect(offerInstance).NotTo(BeNil())
+			Expect(offerInstance.ExpireAt).To(Equal(int64(1486679000)))
+
+			_, _, err = models.ViewOffer(nil, db, offersCache, defaultGameID, offerInstance.ID, playerID, uuid.NewV4().String(), currentTime, cacheExpireDuration, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			offerInstance = findOffer(time.Unix(1486678050, 0))
+			Expect(offerInstance).NotTo(BeNil())
+			Expect(offerInstance.ExpireAt).To(Equal(int64(1486678100)))
+			offerInfo, err := models.GetOfferInfo(nil, db, defaultGameID, playerID, offerInstance.ID, expireDuration, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offerInfo.ExpireAt).To(Equal(int64(1486678100)))
+
+			Expect(findOffer(time.Unix(1486678101, 0))).To(BeNil())
+			offerInstances, err := models.GetAvailableOffers(nil, db, offersCache, defaultGameID, "another-flash-player", time.Unix(1486678101, 0), expireDuration, map[string]string{}, false, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offerInstances["popup"]).NotTo(BeEmpty())
+		})
+	})
+
+	Describe("Prerequisites", func() {
+		currentTime := time.Unix(1486678000, 0)
+		requiredOfferID := "5fed76ab-1fd7-4a91-972d-bca228ce80c4"
+
+		setPrerequisites := func(offerID, prerequisites string) error {
+			offer := new(models.Offer)
+			builder := db.SQL("SELECT * FROM offers WHERE id = $1 AND game_id = $2", offerID, defaultGameID)
+			builder.Execer = edat.NewExecer(builder.Execer)
+			err := builder.QueryStruct(offer)
+			Expect(err).NotTo(HaveOccurred())
+			offer.Prerequisites = dat.JSON([]byte(prerequisites))
+			_, err = models.UpdateOffer(nil, db, offer, offersCache, nil)
+			return err
+		}
+
+		getOffers := func(playerID string) map[string][]*models.OfferToReturn {
+			offerInstances, err := models.GetAvailableOffers(nil, db, offersCache, defaultGameID, playerID, currentTime, expireDuration, map[string]string{}, false, nil)
+			Expect(err).NotTo(HaveOccurred())
+			return offerInstances
+		}
+
+		claimRequiredOffer := func(playerID string, times int) {
+			offerInstances := getOffers(playerID)
+			Expect(offerInstances["unique-place"]).To(HaveLen(1))
+			for i := 0; i < times; i++ {
+				_, _, _, err := models.ClaimOffer(nil, db, defaultGameID, offerInstances["unique-place"][0].ID, playerID, defaultProductID, uuid.NewV4().String(), currentTime.Unix(), currentTime, nil)
+				Expect(err).NotTo(HaveOccurred())
+			}
+		}
+
+		It("should only return the offer after the required offer is claimed", func() {
+			err := setPrerequisites(defaultOfferID, fmt.Sprintf(`{"claimed": ["%s"]}`, requiredOfferID))
+			Expect(err).NotTo(HaveOccurred())
+			playerID := "tier-player"
+
+			Expect(getOffers(playerID)["popup"]).To(BeEmpty())
+			explanations, err := models.ExplainAvailableOffers(nil, db, defaultGameID, playerID, currentTime, map[string]string{}, false, nil)
+			Expect(err).NotTo(HaveOccurred())
+			for _, explanation := range explanations {
+				if explanation.OfferID == defaultOfferID {
+					Expect(explanation.Rule).To(Equal(models.UnavailablePrerequisites))
+				}
+			}
+
+			claimRequiredOffer(playerID, 1)
+
+			Expect(getOffers(playerID)["popup"]).NotTo(BeEmpty())
+		})
+
+		It("should not return the offer after an excluded offer is claimed", func() {
+			err := setPrerequisites(defaultOfferID, fmt.Sprintf(`{"notClaimed": ["%s"]}`, requiredOfferID))
+			Expect(err).NotTo(HaveOccurred())
+			playerID := "excluded-player"
+
+			Expect(getOffers(playerID)["popup"]).NotTo(BeEmpty())
+
+			claimRequiredOffer(playerID, 1)
+
+			Expect(getOffers(playerID)["popup"]).To(BeEmpty())
+		})
+
+		It("should not return the offer before the minimum claims of the required offer", func() {
+			err := setPrerequisites(defaultOfferID, fmt.Sprintf(`{"minClaims": {"%s": 2}}`, requiredOfferID))
+			Expect(err).NotTo(HaveOccurred())
+			playerID := "min-claims-player"
+
+			claimRequiredOffer(playerID, 1)
+			Expect(getOffers(playerID)["popup"]).To(BeEmpty())
+		})
+
+		It("should return the offer after the minimum claims of the required offer", func() {
+			err := setPrerequisites(defaultOfferID, fmt.Sprintf(`{"minClaims": {"%s": 2}}`, requiredOfferID))
+			Expect(err).NotTo(HaveOccurred())
+			playerID := "min-claims-player"
+
+			claimRequiredOffer(playerID, 2)
+			Expect(getOffers(playerID)["popup"]).NotTo(BeEmpty())
+		})
+
+		It("should return error if the prerequisites create a cycle", func() {
+			err := setPrerequisites(requiredOfferID, fmt.Sprintf(`{"claimed": ["%s"]}`, defaultOfferID))
+			Expect(err).NotTo(HaveOccurred())
+
+			err = setPrerequisites(defaultOfferID, fmt.Sprintf(`{"minClaims": {"%s": 1}}`, requiredOfferID))
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.InvalidModelError)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should allow offers to exclude each other", func() {
+			err := setPrerequisites(requiredOfferID, fmt.Sprintf(`{"notClaimed": ["%s"]}`, defaultOfferID))
+			Expect(err).NotTo(HaveOccurred())
+
+			err = setPrerequisites(defaultOfferID, fmt.Sprintf(`{"notClaimed": ["%s"]}`, requiredOfferID))
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should return error if a prerequisite offer does not exist", func() {
+			err := setPrerequisites(defaultOfferID, `{"claimed": ["a6ab2d3b-c1b9-4b5a-bf93-27a16b4c6f5e"]}`)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.InvalidModelError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Describe("Priority and exclusion groups", func() {
+		currentTime := time.Unix(1486678000, 0)
+		playerID := "priority-player"
+
+		updateOffer := func(offerID string, update func(*models.Offer)) {
+			offer := new(models.Offer)
+			builder := db.SQL("SELECT * FROM offers WHERE id = $1 AND game_id = $2", offerID, defaultGameID)
+			builder.Execer = edat.NewExecer(builder.Execer)
+			err := builder.QueryStruct(offer)
+			Expect(err).NotTo(HaveOccurred())
+			update(offer)
+			_, err = models.UpdateOffer(nil, db, offer, offersCache, nil)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		getStoreProductIDs := func() []string {
+			offerInstances, err := models.GetAvailableOffers(nil, db, offersCache, defaultGameID, playerID, currentTime, expireDuration, map[string]string{}, false, nil)
+			Expect(err).NotTo(HaveOccurred())
+			productIDs := []string{}
+			for _, offerInstance := range offerInstances["store"] {
+				productIDs = append(productIDs, offerInstance.ProductID)
+			}
+			return productIDs
+		}
+
+		It("should sort the offers of a placement by priority and then by id", func() {
+			Expect(getStoreProductIDs()).To(Equal([]string{"com.tfg.sample.3", "com.tfg.sample.2"}))
+
+			updateOffer("d5114990-77d7-45c4-ba5f-462fc86b213f", func(offer *models.Offer) {
+				offer.Priority = 10
+			})
+
+			Expect(getStoreProductIDs()).To(Equal([]string{"com.tfg.sample.2", "com.tfg.sample.3"}))
+		})
+
+		It("should return only the offer with the highest priority of an exclusion group", func() {
+			updateOffer("d5114990-77d7-45c4-ba5f-462fc86b213f", func(offer *models.Offer) {
+				offer.Priority = 10
+				offer.ExclusionGroup = "starter-pack"
+			})
+			updateOffer("a411fbcf-dddc-4153-b42b-3f9b2684c965", func(offer *models.Offer) {
+				offer.ExclusionGroup = "starter-pack"
+			})
+
+			Expect(getStoreProductIDs()).To(Equal([]string{"com.tfg.sample.2"}))
+
+			explanations, err := models.ExplainAvailableOffers(nil, db, defaultGameID, playerID, currentTime, map[string]string{}, false, nil)
+			Expect(err).NotTo(HaveOccurred())
+			for _, explanation := range explanations {
+				if explanation.OfferID == "a411fbcf-dddc-4153-b42b-3f9b2684c965" {
+					Expect(explanation.Included).To(BeFalse())
+					Expect(explanation.Rule).To(Equal(models.UnavailableExclusionGroup))
+				}
+			}
+		})
+	})
 })
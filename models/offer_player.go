@@ -19,16 +19,18 @@ import (
 
 //OfferPlayer represents an offer seen by a player
 type OfferPlayer struct {
-	ID             string       `db:"id" json:"id" valid:"uuidv4,required"`
-	GameID         string       `db:"game_id" json:"gameId" valid:"matches(^[^-][a-zA-Z0-9-_]*$),stringlength(1|255),required"`
-	PlayerID       string       `db:"player_id" json:"playerId" valid:"ascii,stringlength(1|1000),required"`
-	OfferID        string       `db:"offer_id" json:"offerId" valid:"uuidv4,required"`
-	ClaimCounter   int          `db:"claim_counter" json:"claimCounter" valid:"int"`
-	ClaimTimestamp dat.NullTime `db:"claim_timestamp" json:"claimTimestamp" valid:""`
-	ViewCounter    int          `db:"view_counter" json:"viewCounter" valid:"int"`
-	ViewTimestamp  dat.NullTime `db:"view_timestamp" json:"viewTimestamp" valid:""`
-	Transactions   dat.JSON     `db:"transactions" json:"transactions" valid:""`
-	Impressions    dat.JSON     `db:"impressions" json:"impressions" valid:""`
+	ID                 string       `db:"id" json:"id" valid:"uuidv4,required"`
+	GameID             string       `db:"game_id" json:"gameId" valid:"matches(^[^-][a-zA-Z0-9-_]*$),stringlength(1|255),required"`
+	PlayerID           string       `db:"player_id" json:"playerId" valid:"ascii,stringlength(1|1000),required"`
+	OfferID            string       `db:"offer_id" json:"offerId" valid:"uuidv4,required"`
+	ClaimCounter       int          `db:"claim_counter" json:"claimCounter" valid:"int"`
+	ClaimTimestamp     dat.NullTime `db:"claim_timestamp" json:"claimTimestamp" valid:""`
+	ViewCounter        int          `db:"view_counter" json:"viewCounter" valid:"int"`
+	ViewTimestamp      dat.NullTime `db:"view_timestamp" json:"viewTimestamp" valid:""`
+	FirstViewTimestamp dat.NullTime `db:"first_view_timestamp" json:"firstViewTimestamp" valid:""`
+	Receipts           dat.JSON     `db:"receipts" json:"receipts" valid:""`
+	Revocations        dat.JSON     `db:"revocations" json:"revocations" valid:""`
+	TestPlayer         bool         `db:"test_player" json:"testPlayer" valid:""`
 }
 
 //GetOfferPlayer returns an offer player
@@ -61,17 +63,17 @@ func GetOffersByPlayer(ctx context.Context, db runner.Connection, gameID, player
 
 //CreateOfferPlayer creates an offer player
 func CreateOfferPlayer(ctx context.Context, db runner.Connection, offerPlayer *OfferPlayer, mr *MixedMetricsReporter) error {
-	if offerPlayer.Transactions == nil {
-		offerPlayer.Transactions = dat.JSON([]byte(`[]`))
+	if offerPlayer.Receipts == nil {
+		offerPlayer.Receipts = dat.JSON([]byte(`{}`))
 	}
-	if offerPlayer.Impressions == nil {
-		offerPlayer.Impressions = dat.JSON([]byte(`[]`))
+	if offerPlayer.Revocations == nil {
+		offerPlayer.Revocations = dat.JSON([]byte(`{}`))
 	}
 	return mr.WithDatastoreSegment("offer_players", SegmentInsert, func() error {
 		builder := db.InsertInto("offer_players")
 		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
 		return builder.
-			Columns("game_id", "player_id", "offer_id", "claim_counter", "claim_timestamp", "view_counter", "view_timestamp", "transactions", "impressions").
+			Columns("game_id", "player_id", "offer_id", "claim_counter", "claim_timestamp", "view_counter", "view_timestamp", "first_view_timestamp", "receipts", "revocations", "test_player").
 			Record(offerPlayer).
 			Returning("*").
 			QueryStruct(offerPlayer)
@@ -84,26 +86,37 @@ func ClaimOfferPlayer(ctx context.Context, db runner.Connection, offerPlayer *Of
 		const incrCounter = dat.UnsafeString("claim_counter + 1")
 		builder := db.Update("offer_players")
 		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
-		return builder.Set("claim_counter", incrCounter).
-			Set("claim_timestamp", t).
-			Set("transactions", offerPlayer.Transactions).
-			Where("game_id = $1 AND player_id = $2 AND offer_id = $3", offerPlayer.GameID, offerPlayer.PlayerID, offerPlayer.OfferID).
-			Returning("claim_counter, claim_timestamp, transactions").
+		builder.Set("claim_counter", incrCounter).
+			Set("claim_timestamp", t)
+		if offerPlayer.Receipts != nil {
+			builder.Set("receipts", offerPlayer.Receipts)
+		}
+		if offerPlayer.TestPlayer {
+			builder.Set("test_player", true)
+		}
+		return builder.Where("game_id = $1 AND player_id = $2 AND offer_id = $3", offerPlayer.GameID, offerPlayer.PlayerID, offerPlayer.OfferID).
+			Returning("claim_counter, claim_timestamp, receipts").
 			QueryStruct(offerPlayer)
 	})
 }
 
-//ViewOfferPlayer increments the view counter and updates the timestamp
+//ViewOfferPlayer increments the view counter and updates the timestamp.
+//The first view is recorded if the player had only claimed the offer so far.
 func ViewOfferPlayer(ctx context.Context, db runner.Connection, offerPlayer *OfferPlayer, t time.Time, mr *MixedMetricsReporter) error {
 	return mr.WithDatastoreSegment("offer_players", SegmentUpdate, func() error {
 		const incrCounter = dat.UnsafeString("view_counter + 1")
 		builder := db.Update("offer_players")
 		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
-		return builder.Set("view_counter", incrCounter).
-			Set("view_timestamp", t).
-			Set("impressions", offerPlayer.Impressions).
-			Where("game_id = $1 AND player_id = $2 AND offer_id = $3", offerPlayer.GameID, offerPlayer.PlayerID, offerPlayer.OfferID).
-			Returning("view_counter, view_timestamp, impressions").
+		builder.Set("view_counter", incrCounter).
+			Set("view_timestamp", t)
+		if !offerPlayer.FirstViewTimestamp.Valid {
+			builder.Set("first_view_timestamp", t)
+		}
+		if offerPlayer.TestPlayer {
+			builder.Set("test_player", true)
+		}
+		return builder.Where("game_id = $1 AND player_id = $2 AND offer_id = $3", offerPlayer.GameID, offerPlayer.PlayerID, offerPlayer.OfferID).
+			Returning("view_counter, view_timestamp, first_view_timestamp").
 			QueryStruct(offerPlayer)
 	})
 }
@@ -120,6 +133,12 @@ func getViewedOfferNextAt(
 	if err != nil {
 		return 0, err
 	}
+	return viewedOfferNextAt(offer, viewCounter, t)
+}
+
+//viewedOfferNextAt returns when the offer can be seen again after an impression at time t,
+//or 0 if it can't be seen again
+func viewedOfferNextAt(offer *Offer, viewCounter int, t time.Time) (int64, error) {
 	var f FrequencyOrPeriod
 
 	json.Unmarshal(offer.Frequency, &f)
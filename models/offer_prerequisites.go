@@ -0,0 +1,190 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	edat "github.com/topfreegames/extensions/dat"
+	"github.com/topfreegames/offers/errors"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//Prerequisites are the rules on the offers claimed by a player that an offer requires to be
+//available to them: offers that must have been claimed, offers that must not have been claimed
+//and offers that must have been claimed a minimum number of times
+type Prerequisites struct {
+	Claimed    []string       `json:"claimed,omitempty"`
+	NotClaimed []string       `json:"notClaimed,omitempty"`
+	MinClaims  map[string]int `json:"minClaims,omitempty"`
+}
+
+func getPrerequisites(offer *Offer) (*Prerequisites, error) {
+	prerequisites := &Prerequisites{}
+	if len(offer.Prerequisites) == 0 {
+		return prerequisites, nil
+	}
+	err := json.Unmarshal(offer.Prerequisites, prerequisites)
+	return prerequisites, err
+}
+
+//requiredOfferIDs returns the ids of the offers that must be claimed, sorted
+func (p *Prerequisites) requiredOfferIDs() []string {
+	ids := append([]string{}, p.Claimed...)
+	for id := range p.MinClaims {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+//remap returns a copy of the prerequisites with the offer ids found in offerIDs replaced by the ids they map to
+func (p *Prerequisites) remap(offerIDs map[string]string) *Prerequisites {
+	remapID := func(offerID string) string {
+		if id, ok := offerIDs[offerID]; ok {
+			return id
+		}
+		return offerID
+	}
+	remapped := &Prerequisites{}
+	for _, offerID := range p.Claimed {
+		remapped.Claimed = append(remapped.Claimed, remapID(offerID))
+	}
+	for _, offerID := range p.NotClaimed {
+		remapped.NotClaimed = append(remapped.NotClaimed, remapID(offerID))
+	}
+	if p.MinClaims != nil {
+		remapped.MinClaims = map[string]int{}
+		for offerID, min := range p.MinClaims {
+			remapped.MinClaims[remapID(offerID)] = min
+		}
+	}
+	return remapped
+}
+
+//isMetBy returns true if the player, with the given offer players by offer id, meets the prerequisites
+func (p *Prerequisites) isMetBy(offerPlayersByOfferID map[string]*OfferPlayer) bool {
+	claims := func(offerID string) int {
+		if offerPlayer, ok := offerPlayersByOfferID[offerID]; ok {
+			return offerPlayer.ClaimCounter
+		}
+		return 0
+	}
+	for _, offerID := range p.Claimed {
+		if claims(offerID) == 0 {
+			return false
+		}
+	}
+	for _, offerID := range p.NotClaimed {
+		if claims(offerID) > 0 {
+			return false
+		}
+	}
+	for offerID, min := range p.MinClaims {
+		if claims(offerID) < min {
+			return false
+		}
+	}
+	return true
+}
+
+//prerequisitesReason returns UnavailablePrerequisites if the player, with the given offer players
+//by offer id, does not meet the prerequisites of the offer, or an empty string if they do
+func prerequisitesReason(offer *Offer, offerPlayersByOfferID map[string]*OfferPlayer) (string, error) {
+	prerequisites, err := getPrerequisites(offer)
+	if err != nil {
+		return "", err
+	}
+	if !prerequisites.isMetBy(offerPlayersByOfferID) {
+		return UnavailablePrerequisites, nil
+	}
+	return "", nil
+}
+
+func filterOffersByPrerequisites(offers []*Offer, playerOffers []*OfferPlayer) ([]*Offer, error) {
+	playerOffersByOfferID := map[string]*OfferPlayer{}
+	for _, playerOffer := range playerOffers {
+		playerOffersByOfferID[playerOffer.OfferID] = playerOffer
+	}
+	filteredOffers := make([]*Offer, 0, len(offers))
+	for _, offer := range offers {
+		reason, err := prerequisitesReason(offer, playerOffersByOfferID)
+		if err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			filteredOffers = append(filteredOffers, offer)
+		}
+	}
+	return filteredOffers, nil
+}
+
+//validatePrerequisites returns an InvalidModelError if the prerequisites of the offer are malformed,
+//refer to offers that are not of its game or make it require claiming itself, directly or through
+//the offers it requires. Offers that must not be claimed can refer to each other.
+func validatePrerequisites(ctx context.Context, db runner.Connection, offer *Offer, mr *MixedMetricsReporter) error {
+	prerequisites, err := getPrerequisites(offer)
+	if err != nil {
+		return errors.NewInvalidModelError("Offer", fmt.Sprintf("invalid prerequisites: %s", err.Error()))
+	}
+	for offerID, min := range prerequisites.MinClaims {
+		if min < 1 {
+			return errors.NewInvalidModelError("Offer", fmt.Sprintf("the minimum claims of offer %s must be positive", offerID))
+		}
+	}
+	referencedOfferIDs := append(prerequisites.requiredOfferIDs(), prerequisites.NotClaimed...)
+	if len(referencedOfferIDs) == 0 {
+		return nil
+	}
+
+	offers := []*Offer{}
+	err = mr.WithDatastoreSegment("offers", SegmentSelect, func() error {
+		builder := db.Select("id, prerequisites")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("offers").
+			Where("game_id = $1", offer.GameID).
+			QueryStructs(&offers)
+	})
+	if err != nil {
+		return err
+	}
+
+	requiredOfferIDsByOfferID := map[string][]string{}
+	for _, o := range offers {
+		p, err := getPrerequisites(o)
+		if err != nil {
+			return err
+		}
+		requiredOfferIDsByOfferID[o.ID] = p.requiredOfferIDs()
+	}
+	for _, offerID := range referencedOfferIDs {
+		if _, ok := requiredOfferIDsByOfferID[offerID]; !ok {
+			return errors.NewInvalidModelError("Offer", fmt.Sprintf("the prerequisite offer %s does not exist", offerID))
+		}
+	}
+	requiredOfferIDsByOfferID[offer.ID] = prerequisites.requiredOfferIDs()
+
+	visited := map[string]bool{}
+	pending := append([]string{}, requiredOfferIDsByOfferID[offer.ID]...)
+	for len(pending) > 0 {
+		offerID := pending[0]
+		pending = pending[1:]
+		if offerID == offer.ID {
+			return errors.NewInvalidModelError("Offer", "the prerequisites of the offer require claiming the offer itself")
+		}
+		if visited[offerID] {
+			continue
+		}
+		visited[offerID] = true
+		pending = append(pending, requiredOfferIDsByOfferID[offerID]...)
+	}
+	return nil
+}
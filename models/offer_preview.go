@@ -0,0 +1,113 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/pmylund/go-cache"
+	"gopkg.in/mgutz/dat.v2/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//PreviewAvailableOffers returns the available offers, by placement, of a hypothetical player that has
+//the given offer players at the time of clock. The history of real players is not read.
+//The enabled offers cache is not used, since it holds the offers of the current time.
+//Rotated placements pick the offers of the player with the given id, which may be empty.
+func PreviewAvailableOffers(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, playerID string,
+	clock Clock,
+	filterAttrs map[string]string,
+	allowInefficientQueries bool,
+	offerPlayers []*SimulatedOfferPlayer,
+	mr *MixedMetricsReporter,
+) (map[string][]*OfferToReturn, error) {
+	t := clock.GetTime()
+	offersByPlayer := simulatedOffersByPlayer(gameID, offerPlayers)
+	offersByPlacement, err := previewOffersByPlacement(ctx, db, gameID, t, filterAttrs, allowInefficientQueries, offersByPlayer, mr)
+	if err != nil {
+		return nil, err
+	}
+	placements, err := ListPlacements(ctx, db, gameID, mr)
+	if err != nil {
+		return nil, err
+	}
+	err = applyPlacements(
+		ctx, db, gameID, playerID, offersByPlacement, indexOfferPlayers(offersByPlayer), placements, t, filterAttrs, allowInefficientQueries, mr,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return offersByPlacement, nil
+}
+
+func previewOffersByPlacement(
+	ctx context.Context,
+	db runner.Connection,
+	gameID string,
+	t time.Time,
+	filterAttrs map[string]string,
+	allowInefficientQueries bool,
+	offersByPlayer []*OfferPlayer,
+	mr *MixedMetricsReporter,
+) (map[string][]*OfferToReturn, error) {
+	enabledOffers, err := GetEnabledOffers(
+		ctx,
+		db,
+		gameID,
+		cache.New(cache.NoExpiration, 0),
+		cache.NoExpiration,
+		t,
+		filterAttrs,
+		allowInefficientQueries,
+		mr,
+	)
+	if err != nil {
+		return nil, err
+	}
+	enabledOffers, err = filterSoldOutOffers(ctx, db, gameID, enabledOffers, mr)
+	if err != nil {
+		return nil, err
+	}
+	if len(enabledOffers) == 0 {
+		return map[string][]*OfferToReturn{}, nil
+	}
+
+	enabledOffers, err = filterOffersByPrerequisites(enabledOffers, offersByPlayer)
+	if err != nil {
+		return nil, err
+	}
+	return getOffersByPlacement(ctx, db, "", enabledOffers, offersByPlayer, t, mr)
+}
+
+//simulatedOffersByPlayer returns the offer players of a hypothetical player of a game
+func simulatedOffersByPlayer(gameID string, offerPlayers []*SimulatedOfferPlayer) []*OfferPlayer {
+	offersByPlayer := make([]*OfferPlayer, 0, len(offerPlayers))
+	for _, simulated := range offerPlayers {
+		offerPlayer := &OfferPlayer{
+			GameID:       gameID,
+			OfferID:      simulated.OfferID,
+			ViewCounter:  simulated.ViewCounter,
+			ClaimCounter: simulated.ClaimCounter,
+		}
+		if simulated.ViewTimestamp != 0 {
+			offerPlayer.ViewTimestamp = dat.NullTimeFrom(time.Unix(simulated.ViewTimestamp, 0))
+		}
+		if simulated.FirstViewTimestamp != 0 {
+			offerPlayer.FirstViewTimestamp = dat.NullTimeFrom(time.Unix(simulated.FirstViewTimestamp, 0))
+		}
+		if simulated.ClaimTimestamp != 0 {
+			offerPlayer.ClaimTimestamp = dat.NullTimeFrom(time.Unix(simulated.ClaimTimestamp, 0))
+		}
+		offersByPlayer = append(offersByPlayer, offerPlayer)
+	}
+	return offersByPlayer
+}
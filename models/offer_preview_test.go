@@ -0,0 +1,65 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/topfreegames/offers/models"
+)
+
+var _ = Describe("Offer Preview Model", func() {
+	clock := models.FixedClock{Time: time.Unix(1486678000, 0)}
+
+	It("should return the offers of a player without history", func() {
+		offers, err := models.PreviewAvailableOffers(nil, db, defaultGameID, "", clock, nil, false, nil, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offers).To(HaveKey("popup"))
+		Expect(offers["popup"]).To(HaveLen(1))
+		Expect(offers["store"]).To(HaveLen(2))
+		Expect(offers).To(HaveKey("unique-place"))
+	})
+
+	It("should return the offers at the time of the clock", func() {
+		future := models.FixedClock{Time: time.Unix(1486679150, 0)}
+
+		offers, err := models.PreviewAvailableOffers(nil, db, defaultGameID, "", future, nil, false, nil, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offers).NotTo(HaveKey("popup"))
+		Expect(offers["store"]).To(HaveLen(1))
+	})
+
+	It("should use the simulated offer players", func() {
+		offerPlayers := []*models.SimulatedOfferPlayer{
+			{
+				OfferID:        "dd21ec96-2890-4ba0-b8e2-40ea67196990",
+				ClaimCounter:   1,
+				ClaimTimestamp: clock.GetTime().Unix(),
+			},
+		}
+
+		offers, err := models.PreviewAvailableOffers(nil, db, defaultGameID, "", clock, nil, false, offerPlayers, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offers).NotTo(HaveKey("popup"))
+		Expect(offers["store"]).To(HaveLen(2))
+	})
+
+	It("should not use the enabled offers cache", func() {
+		future := models.FixedClock{Time: time.Unix(1486679150, 0)}
+		_, err := models.PreviewAvailableOffers(nil, db, defaultGameID, "", future, nil, false, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, found := offersCache.Get(models.GetEnabledOffersKey(defaultGameID))
+		Expect(found).To(BeFalse())
+	})
+})
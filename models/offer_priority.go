@@ -0,0 +1,41 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import "sort"
+
+//sortOffersByPriority sorts the offers by descending priority, breaking ties by id,
+//so every player gets the offers of a placement in the same order
+func sortOffersByPriority(offers []*Offer) {
+	sort.Slice(offers, func(i, j int) bool {
+		if offers[i].Priority != offers[j].Priority {
+			return offers[i].Priority > offers[j].Priority
+		}
+		return offers[i].ID < offers[j].ID
+	})
+}
+
+//filterOffersByExclusionGroup returns the offers sorted by priority, keeping only the first
+//offer of each exclusion group. Offers without an exclusion group are always kept.
+func filterOffersByExclusionGroup(offers []*Offer) []*Offer {
+	sortedOffers := append([]*Offer{}, offers...)
+	sortOffersByPriority(sortedOffers)
+
+	shownGroups := map[string]bool{}
+	filteredOffers := make([]*Offer, 0, len(sortedOffers))
+	for _, offer := range sortedOffers {
+		if offer.ExclusionGroup != "" {
+			if shownGroups[offer.ExclusionGroup] {
+				continue
+			}
+			shownGroups[offer.ExclusionGroup] = true
+		}
+		filteredOffers = append(filteredOffers, offer)
+	}
+	return filteredOffers
+}
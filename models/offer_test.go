@@ -14,6 +14,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/satori/go.uuid"
 	edat "github.com/topfreegames/extensions/dat"
+	"github.com/topfreegames/offers/errors"
 	"github.com/topfreegames/offers/models"
 	. "github.com/topfreegames/offers/testing"
 	oTesting "github.com/topfreegames/offers/testing"
@@ -187,6 +188,25 @@ var _ = Describe("Offer Models", func() {
 			Expect(err.Error()).To(Equal("sql: no rows in result set"))
 		})
 
+		It("should return error if the trigger duration is invalid", func() {
+			offer := &models.Offer{
+				Name:      "offer-1",
+				ProductID: "com.tfg.example",
+				GameID:    "game-id",
+				Contents:  dat.JSON([]byte(`{"gems": 5, "gold": 100}`)),
+				Period:    dat.JSON([]byte(`{"every": "10m"}`)),
+				Frequency: dat.JSON([]byte(`{"every": "24h"}`)),
+				Trigger:   dat.JSON([]byte(`{"from": 1487280506875, "duration": "24hours"}`)),
+				Placement: "popup",
+			}
+
+			_, err := models.InsertOffer(nil, db, offer, offersCache, nil)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.InvalidModelError)
+			Expect(ok).To(BeTrue())
+		})
+
 		It("should return error if inserting offer template with missing parameters", func() {
 			//Given
 			offer := &models.Offer{
@@ -711,6 +731,25 @@ var _ = Describe("Offer Models", func() {
 			Expect(dbOffer.Version).To(Equal(createdOffer.Version))
 		})
 
+		It("should return error if the trigger duration is invalid", func() {
+			offerUpdate := &models.Offer{
+				ID:        defaultOfferID,
+				GameID:    defaultGameID,
+				Name:      "offer-2",
+				ProductID: "com.tfg.example2",
+				Contents:  dat.JSON([]byte(`{"gems": 5}`)),
+				Period:    dat.JSON([]byte(`{"every": "1m"}`)),
+				Frequency: dat.JSON([]byte(`{"every": "2h"}`)),
+				Trigger:   dat.JSON([]byte(`{"from": 1111111111111, "duration": "-1h"}`)),
+				Placement: "popup",
+			}
+
+			_, err := models.UpdateOffer(nil, db, offerUpdate, offersCache, nil)
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.InvalidModelError)
+			Expect(ok).To(BeTrue())
+		})
+
 		It("should return error if offer with given id does not exist", func() {
 			id := uuid.NewV4().String()
 			offerUpdate := &models.Offer{
@@ -0,0 +1,61 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/topfreegames/offers/errors"
+)
+
+//offerTokenVersion prefixes offer tokens so their format can change later
+const offerTokenVersion = "v1"
+
+func offerTokenMAC(secret, gameID, playerID, offerInstanceID string, expiresAt int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%d", offerTokenVersion, gameID, playerID, offerInstanceID, expiresAt)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+//SignOfferToken returns a token proving the offer instance was made available to the player until expiresAt
+func SignOfferToken(secret, gameID, playerID, offerInstanceID string, expiresAt time.Time) string {
+	return fmt.Sprintf(
+		"%s.%d.%s",
+		offerTokenVersion, expiresAt.Unix(),
+		offerTokenMAC(secret, gameID, playerID, offerInstanceID, expiresAt.Unix()),
+	)
+}
+
+//VerifyOfferToken checks that token was signed for the player and offer instance and is not expired at time t
+func VerifyOfferToken(secret, token, gameID, playerID, offerInstanceID string, t time.Time) error {
+	if token == "" {
+		return errors.NewInvalidOfferTokenError(offerInstanceID, "missing offer token")
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != offerTokenVersion {
+		return errors.NewInvalidOfferTokenError(offerInstanceID, "malformed offer token")
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return errors.NewInvalidOfferTokenError(offerInstanceID, "malformed offer token")
+	}
+	expected := offerTokenMAC(secret, gameID, playerID, offerInstanceID, expiresAt)
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return errors.NewInvalidOfferTokenError(offerInstanceID, "offer token was not issued for this player and offer")
+	}
+	if t.Unix() > expiresAt {
+		return errors.NewInvalidOfferTokenError(offerInstanceID, "offer token is expired")
+	}
+	return nil
+}
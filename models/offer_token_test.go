@@ -0,0 +1,66 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+)
+
+var _ = Describe("Offer Token Model", func() {
+	currentTime := time.Unix(1486678000, 0)
+	offerInstanceID := "56fc0477-39f1-485c-898e-4909e9155eb1"
+	secret := "offer-token-secret"
+
+	It("should verify a token signed for the player and offer", func() {
+		token := models.SignOfferToken(secret, defaultGameID, "player-1", offerInstanceID, currentTime.Add(time.Minute))
+
+		err := models.VerifyOfferToken(secret, token, defaultGameID, "player-1", offerInstanceID, currentTime)
+
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject a token signed for another player", func() {
+		token := models.SignOfferToken(secret, defaultGameID, "player-1", offerInstanceID, currentTime.Add(time.Minute))
+
+		err := models.VerifyOfferToken(secret, token, defaultGameID, "player-2", offerInstanceID, currentTime)
+
+		Expect(err).To(HaveOccurred())
+		_, ok := err.(*errors.InvalidOfferTokenError)
+		Expect(ok).To(BeTrue())
+	})
+
+	It("should reject a token signed with another secret", func() {
+		token := models.SignOfferToken("another-secret", defaultGameID, "player-1", offerInstanceID, currentTime.Add(time.Minute))
+
+		err := models.VerifyOfferToken(secret, token, defaultGameID, "player-1", offerInstanceID, currentTime)
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject an expired token", func() {
+		token := models.SignOfferToken(secret, defaultGameID, "player-1", offerInstanceID, currentTime.Add(-time.Second))
+		expectedError := errors.NewInvalidOfferTokenError(offerInstanceID, "offer token is expired")
+
+		err := models.VerifyOfferToken(secret, token, defaultGameID, "player-1", offerInstanceID, currentTime)
+
+		Expect(err).To(MatchError(expectedError))
+	})
+
+	It("should reject missing and malformed tokens", func() {
+		err := models.VerifyOfferToken(secret, "", defaultGameID, "player-1", offerInstanceID, currentTime)
+		Expect(err).To(MatchError(errors.NewInvalidOfferTokenError(offerInstanceID, "missing offer token")))
+
+		err = models.VerifyOfferToken(secret, "v1.not-a-timestamp.abc", defaultGameID, "player-1", offerInstanceID, currentTime)
+		Expect(err).To(MatchError(errors.NewInvalidOfferTokenError(offerInstanceID, "malformed offer token")))
+	})
+})
@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -34,32 +35,40 @@ func offerVersionFromOffer(offer *Offer) *OfferVersion {
 	}
 }
 
+//offerInfo is an offer to return with what is needed to tell when it expires for a player
+type offerInfo struct {
+	OfferToReturn
+	Trigger            dat.JSON     `db:"trigger"`
+	FirstViewTimestamp dat.NullTime `db:"first_view_timestamp"`
+}
+
+const offerInfoQuery = `
+	SELECT oi.id, oi.product_id, oi.contents, oi.cost, o.metadata, o.trigger,
+		GREATEST(o.stock - o.stock_sold, 0) AS remaining_stock, op.first_view_timestamp
+	FROM %s oi JOIN offers o ON (oi.offer_id=o.id)
+		LEFT JOIN offer_players op ON (op.game_id=oi.game_id AND op.offer_id=o.id AND op.player_id=$3)
+	WHERE oi.id=$1 AND oi.game_id=$2`
+
 func getOfferToReturn(
 	ctx context.Context,
 	db runner.Connection,
-	gameID, offerID string,
+	gameID, playerID, offerID string,
 	mr *MixedMetricsReporter,
-) (*OfferToReturn, error) {
-	var offerVersion OfferToReturn
+) (*offerInfo, error) {
+	var offerVersion offerInfo
 
 	err := mr.WithDatastoreSegment("offer_versions", SegmentSelect, func() error {
-		builder := db.
-			Select("oi.id, oi.product_id, oi.contents, oi.cost, o.metadata, o.trigger#>>'{to}' AS expire_at")
+		builder := db.SQL(fmt.Sprintf(offerInfoQuery, "offer_versions"), offerID, gameID, playerID)
 		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
-		return builder.From("offer_versions oi JOIN offers o ON (oi.offer_id=o.id)").
-			Where("oi.id=$1 AND oi.game_id=$2", offerID, gameID).
-			QueryStruct(&offerVersion)
+		return builder.QueryStruct(&offerVersion)
 	})
 
 	// This part was left to be backwards compatible with previously existing offer instances
 	if err != nil && IsNoRowsInResultSetError(err) {
 		err = mr.WithDatastoreSegment("offer_instances", SegmentSelect, func() error {
-			builder := db.
-				Select("oi.id, oi.product_id, oi.contents, oi.cost, o.metadata, o.trigger#>>'{to}' AS expire_at")
+			builder := db.SQL(fmt.Sprintf(offerInfoQuery, "offer_instances"), offerID, gameID, playerID)
 			builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
-			return builder.From("offer_instances oi JOIN offers o ON (oi.offer_id=o.id)").
-				Where("oi.id=$1 AND oi.game_id=$2", offerID, gameID).
-				QueryStruct(&offerVersion)
+			return builder.QueryStruct(&offerVersion)
 		})
 	}
 	err = handleNotFoundError("OfferInstance", map[string]interface{}{
@@ -99,27 +108,37 @@ func findOfferVersions(
 	return resOfferInstances, err
 }
 
-//GetOfferInfo returns the offers that match the criteria of enabled offer templates
+//GetOfferInfo returns an offer instance as returned by GetAvailableOffers to the player.
+//If the trigger of the offer has a duration, it expires for the player that long after they first saw it.
 func GetOfferInfo(
 	ctx context.Context,
 	db runner.Connection,
-	gameID, offerInstanceID string,
+	gameID, playerID, offerInstanceID string,
 	expireDuration time.Duration,
 	mr *MixedMetricsReporter,
 ) (*OfferToReturn, error) {
-	offer, err := getOfferToReturn(ctx, db, gameID, offerInstanceID, mr)
+	offer, err := getOfferToReturn(ctx, db, gameID, playerID, offerInstanceID, mr)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return offer, nil
+	var trigger Times
+	if err := json.Unmarshal(offer.Trigger, &trigger); err != nil {
+		return nil, err
+	}
+	offer.ExpireAt, err = trigger.PlayerExpireAt(offer.FirstViewTimestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &offer.OfferToReturn, nil
 }
 
 func getOfferVersionAndOfferEnabled(ctx context.Context, db runner.Connection, gameID, id string, mr *MixedMetricsReporter) (*OfferInstanceOffer, error) {
 	var offerInstance OfferInstanceOffer
 	err := mr.WithDatastoreSegment("offer_versions", SegmentSelect, func() error {
-		builder := db.Select("oi.id, oi.offer_id, oi.contents, o.enabled")
+		builder := db.Select("oi.id, oi.offer_id, oi.contents, o.enabled, o.placement")
 		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
 		return builder.From("offer_versions oi JOIN offers o ON (oi.offer_id=o.id)").
 			Where("oi.id=$1 AND oi.game_id=$2", id, gameID).
@@ -137,7 +156,7 @@ func getOfferVersionAndOfferEnabled(ctx context.Context, db runner.Connection, g
 func getOfferVersionByID(ctx context.Context, db runner.Connection, gameID, id string, mr *MixedMetricsReporter) (*OfferVersion, error) {
 	var offerInstance OfferVersion
 	err := mr.WithDatastoreSegment("offer_versions", SegmentSelect, func() error {
-		builder := db.Select("id, offer_id, contents")
+		builder := db.Select("id, offer_id, offer_version, contents, product_id, cost")
 		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
 		return builder.From("offer_versions").
 			Where("id=$1 AND game_id=$2", id, gameID).
@@ -156,7 +175,7 @@ func getLastOfferInstanceByPlayerIDAndProductID(ctx context.Context, db runner.C
 	var offerInstance OfferVersion
 	err := mr.WithDatastoreSegment("offer_players", SegmentSelect, func() error {
 		builder := db.SQL(`
-		SELECT ov.id, ov.offer_id, ov.contents
+		SELECT ov.id, ov.offer_id, ov.offer_version, ov.contents, ov.product_id, ov.cost
 			FROM offer_players op JOIN offer_versions ov ON ov.offer_id=op.offer_id
 			WHERE op.game_id=$1 AND op.player_id=$2 AND op.view_timestamp < to_timestamp($4)
 				AND ov.game_id=$1 AND ov.product_id=$3
@@ -0,0 +1,500 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pmylund/go-cache"
+	edat "github.com/topfreegames/extensions/dat"
+	"github.com/topfreegames/offers/errors"
+	"gopkg.in/mgutz/dat.v2/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//Orderings of the offers of a placement
+const (
+	PlacementOrderingPriority   = "priority"
+	PlacementOrderingExpiration = "expiration"
+)
+
+//Placement is a place in the UI of a game where offers are shown. Once a game has placements,
+//the placement of its offers must be one of them.
+type Placement struct {
+	GameID          string         `db:"game_id" json:"gameId" valid:"optional"`
+	Name            string         `db:"name" json:"name" valid:"ascii,stringlength(1|255),required"`
+	Ordering        string         `db:"ordering" json:"ordering" valid:"matches(^(priority|expiration)$),optional"`
+	MaxOffers       dat.NullInt64  `db:"max_offers" json:"maxOffers" valid:"NonNegativeNullInt"`
+	FallbackOfferID dat.NullString `db:"fallback_offer_id" json:"fallbackOfferId" valid:"NullUUIDv4"`
+	ImpressionCap   dat.JSON       `db:"impression_cap" json:"impressionCap" valid:"JSONObject"`
+	Rotation        dat.JSON       `db:"rotation" json:"rotation" valid:"JSONObject"`
+	CreatedAt       dat.NullTime   `db:"created_at" json:"createdAt" valid:"optional"`
+	UpdatedAt       dat.NullTime   `db:"updated_at" json:"updatedAt" valid:"optional"`
+}
+
+//ListPlacements returns the placements of a game
+func ListPlacements(ctx context.Context, db runner.Connection, gameID string, mr *MixedMetricsReporter) ([]*Placement, error) {
+	placements := []*Placement{}
+	err := mr.WithDatastoreSegment("placements", SegmentSelect, func() error {
+		builder := db.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("placements").
+			Where("game_id = $1", gameID).
+			OrderBy("name").
+			QueryStructs(&placements)
+	})
+	return placements, err
+}
+
+//getImpressionCap returns the impression cap of the placement, or nil if it has none
+func (p *Placement) getImpressionCap() (*ImpressionCap, error) {
+	var impressionCap *ImpressionCap
+	if len(p.ImpressionCap) == 0 {
+		return nil, nil
+	}
+	if err := p.ImpressionCap.Unmarshal(&impressionCap); err != nil {
+		return nil, err
+	}
+	if impressionCap == nil || *impressionCap == (ImpressionCap{}) {
+		return nil, nil
+	}
+	return impressionCap, nil
+}
+
+//getRotation returns the rotation of the placement, or nil if it has none
+func (p *Placement) getRotation() (*Rotation, error) {
+	var rotation *Rotation
+	if len(p.Rotation) == 0 {
+		return nil, nil
+	}
+	if err := p.Rotation.Unmarshal(&rotation); err != nil {
+		return nil, err
+	}
+	if rotation == nil || *rotation == (Rotation{}) {
+		return nil, nil
+	}
+	return rotation, nil
+}
+
+//getPlacements returns the placements of a game from offersCache, if it is not nil,
+//or from the database
+func getPlacements(
+	ctx context.Context,
+	db runner.Connection,
+	gameID string,
+	offersCache *cache.Cache,
+	expireDuration time.Duration,
+	mr *MixedMetricsReporter,
+) ([]*Placement, error) {
+	if offersCache == nil {
+		return ListPlacements(ctx, db, gameID, mr)
+	}
+
+	placementsKey := GetPlacementsKey(gameID)
+	if placements, found := offersCache.Get(placementsKey); found {
+		return placements.([]*Placement), nil
+	}
+	placements, err := ListPlacements(ctx, db, gameID, mr)
+	if err != nil {
+		return nil, err
+	}
+	offersCache.Set(placementsKey, placements, expireDuration)
+	return placements, nil
+}
+
+//validate sets the defaults of the placement and returns an InvalidModelError if its impression cap
+//or rotation are malformed
+func (p *Placement) validate() error {
+	if p.Ordering == "" {
+		p.Ordering = PlacementOrderingPriority
+	}
+	if p.ImpressionCap == nil {
+		p.ImpressionCap = dat.JSON([]byte(`{}`))
+	}
+	impressionCap, err := p.getImpressionCap()
+	if err != nil {
+		return errors.NewInvalidModelError("Placement", err.Error())
+	}
+	if impressionCap != nil {
+		if err := impressionCap.validate(); err != nil {
+			return errors.NewInvalidModelError("Placement", fmt.Sprintf("invalid impression cap: %s", err.Error()))
+		}
+	}
+	if p.Rotation == nil {
+		p.Rotation = dat.JSON([]byte(`{}`))
+	}
+	rotation, err := p.getRotation()
+	if err != nil {
+		return errors.NewInvalidModelError("Placement", err.Error())
+	}
+	if rotation != nil {
+		if err := rotation.validate(); err != nil {
+			return errors.NewInvalidModelError("Placement", fmt.Sprintf("invalid rotation: %s", err.Error()))
+		}
+	}
+	return nil
+}
+
+//UpsertPlacement creates or replaces a placement of a game. The fallback offer, if any, must be an offer of the game,
+//the impression cap, if any, must have a positive every and a max of at least 1 and the rotation, if any,
+//must have a size of at least 1 and a reset offset shorter than a day.
+func UpsertPlacement(
+	ctx context.Context,
+	db runner.Connection,
+	placement *Placement,
+	offersCache *cache.Cache,
+	t time.Time,
+	mr *MixedMetricsReporter,
+) error {
+	err := placement.validate()
+	if err != nil {
+		return err
+	}
+	placement.UpdatedAt = dat.NullTimeFrom(t)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.AutoRollback()
+
+	var prevPlacement *Placement
+	prevPlacements := []*Placement{}
+	err = mr.WithDatastoreSegment("placements", SegmentSelect, func() error {
+		builder := tx.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("placements").
+			Where("game_id = $1 AND name = $2", placement.GameID, placement.Name).
+			QueryStructs(&prevPlacements)
+	})
+	if err != nil {
+		return err
+	}
+	if len(prevPlacements) > 0 {
+		prevPlacement = prevPlacements[0]
+	}
+
+	if placement.FallbackOfferID.Valid {
+		var count int
+		err = mr.WithDatastoreSegment("offers", SegmentSelect, func() error {
+			builder := tx.Select("COUNT(*)")
+			builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+			return builder.From("offers").
+				Where("game_id = $1 AND id = $2", placement.GameID, placement.FallbackOfferID.String).
+				QueryScalar(&count)
+		})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.NewInvalidModelError("Placement", fmt.Sprintf("the fallback offer %s does not exist", placement.FallbackOfferID.String))
+		}
+	}
+
+	err = mr.WithDatastoreSegment("placements", SegmentUpsert, func() error {
+		builder := tx.Upsert("placements")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.Columns("game_id", "name", "ordering", "max_offers", "fallback_offer_id", "impression_cap", "rotation", "updated_at").
+			Record(placement).
+			Where("game_id = $1 AND name = $2", placement.GameID, placement.Name).
+			Returning("created_at", "updated_at").
+			QueryStruct(placement)
+	})
+	err = handleForeignKeyViolationError("Placement", err)
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEvent(ctx, tx, placement.GameID, "", AuditActionUpsertPlacement, prevPlacement, placement, mr)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err == nil {
+		offersCache.Delete(GetPlacementsKey(placement.GameID))
+	}
+	return err
+}
+
+//DeletePlacement removes a placement of a game. It returns an InvalidModelError if an offer of the game uses it.
+func DeletePlacement(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, name string,
+	offersCache *cache.Cache,
+	mr *MixedMetricsReporter,
+) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.AutoRollback()
+
+	var count int
+	err = mr.WithDatastoreSegment("offers", SegmentSelect, func() error {
+		builder := tx.Select("COUNT(*)")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("offers").
+			Where("game_id = $1 AND placement = $2", gameID, name).
+			QueryScalar(&count)
+	})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.NewInvalidModelError("Placement", fmt.Sprintf("the placement %s is used by %d offers", name, count))
+	}
+
+	var placement Placement
+	err = mr.WithDatastoreSegment("placements", SegmentDelete, func() error {
+		builder := tx.DeleteFrom("placements")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.
+			Where("game_id = $1 AND name = $2", gameID, name).
+			Returning("*").
+			QueryStruct(&placement)
+	})
+	err = handleNotFoundError("Placement", map[string]interface{}{
+		"GameID": gameID,
+		"Name":   name,
+	}, err)
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEvent(ctx, tx, gameID, "", AuditActionDeletePlacement, &placement, nil, mr)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err == nil {
+		offersCache.Delete(GetPlacementsKey(gameID))
+	}
+	return err
+}
+
+//validateOfferPlacement returns an InvalidModelError if the game of the offer has placements
+//and the placement of the offer is not one of them
+func validateOfferPlacement(ctx context.Context, db runner.Connection, offer *Offer, mr *MixedMetricsReporter) error {
+	placements, err := ListPlacements(ctx, db, offer.GameID, mr)
+	if err != nil {
+		return err
+	}
+	if len(placements) == 0 {
+		return nil
+	}
+	for _, placement := range placements {
+		if placement.Name == offer.Placement {
+			return nil
+		}
+	}
+	return errors.NewInvalidModelError("Offer", fmt.Sprintf("the placement %s does not exist in the game", offer.Placement))
+}
+
+//applyPlacements keeps the offers of the rotated placements picked for the player, returns the fallback
+//offer of the placements that have no offers, sorts the offers of the placements ordered by expiration
+//and keeps at most the maximum number of offers of each placement. The fallback offers must match filterAttrs
+//and respect the rules of the player, given by the offers they saw or claimed, unless playerOffersByOfferID is nil.
+func applyPlacements(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, playerID string,
+	offersByPlacement map[string][]*OfferToReturn,
+	playerOffersByOfferID map[string]*OfferPlayer,
+	placements []*Placement,
+	t time.Time,
+	filterAttrs map[string]string,
+	allowInefficientQueries bool,
+	mr *MixedMetricsReporter,
+) error {
+	var pools map[string][]string
+	for _, placement := range placements {
+		rotation, err := placement.getRotation()
+		if err != nil {
+			return err
+		}
+		if rotation == nil || len(offersByPlacement[placement.Name]) == 0 {
+			continue
+		}
+		if pools == nil {
+			pools, err = getRotationPools(ctx, db, gameID, mr)
+			if err != nil {
+				return err
+			}
+		}
+		offersByPlacement[placement.Name] = rotation.rotateOffers(
+			gameID, playerID, placement.Name, pools[placement.Name], offersByPlacement[placement.Name], t,
+		)
+	}
+
+	needsFallback := false
+	for _, placement := range placements {
+		needsFallback = needsFallback || (placement.FallbackOfferID.Valid && len(offersByPlacement[placement.Name]) == 0)
+	}
+	fallbackOffers := map[string]*OfferToReturn{}
+	if needsFallback {
+		var err error
+		fallbackOffers, err = getFallbackOffers(ctx, db, gameID, playerOffersByOfferID, t, filterAttrs, allowInefficientQueries, mr)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, placement := range placements {
+		offers := offersByPlacement[placement.Name]
+		if len(offers) == 0 && placement.FallbackOfferID.Valid {
+			if fallbackOffer, ok := fallbackOffers[placement.FallbackOfferID.String]; ok {
+				offers = []*OfferToReturn{fallbackOffer}
+			}
+		}
+		if placement.Ordering == PlacementOrderingExpiration {
+			sortOffersByExpiration(offers)
+		}
+		if placement.MaxOffers.Valid && int64(len(offers)) > placement.MaxOffers.Int64 {
+			offers = offers[:placement.MaxOffers.Int64]
+		}
+
+		if len(offers) > 0 {
+			offersByPlacement[placement.Name] = offers
+		} else {
+			delete(offersByPlacement, placement.Name)
+		}
+	}
+	return nil
+}
+
+//sortOffersByExpiration sorts the offers by the time they expire, the ones that do not expire
+//last, keeping the priority order of the offers that expire at the same time
+func sortOffersByExpiration(offers []*OfferToReturn) {
+	sort.SliceStable(offers, func(i, j int) bool {
+		if offers[i].ExpireAt == 0 || offers[j].ExpireAt == 0 {
+			return offers[j].ExpireAt == 0 && offers[i].ExpireAt != 0
+		}
+		return offers[i].ExpireAt < offers[j].ExpireAt
+	})
+}
+
+//getFallbackOffers returns, by offer id, the fallback offers of the placements of a game that are
+//enabled, triggered at time t, not sold out and whose filters match filterAttrs. Unless playerOffersByOfferID is nil, as for test players,
+//they must also respect the frequency, period, duration and prerequisites of the offer for the player.
+func getFallbackOffers(
+	ctx context.Context,
+	db runner.Connection,
+	gameID string,
+	playerOffersByOfferID map[string]*OfferPlayer,
+	t time.Time,
+	filterAttrs map[string]string,
+	allowInefficientQueries bool,
+	mr *MixedMetricsReporter,
+) (map[string]*OfferToReturn, error) {
+	offers := []*Offer{}
+	err := mr.WithDatastoreSegment("offers", SegmentSelect, func() error {
+		builder := db.SQL(`
+			SELECT DISTINCT o.* FROM offers o
+			JOIN placements p ON p.game_id = o.game_id AND p.fallback_offer_id = o.id
+			WHERE o.game_id = $1 AND o.enabled`,
+			gameID,
+		)
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.QueryStructs(&offers)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	triggeredOffers := []*Offer{}
+	triggersByOfferID := map[string]Times{}
+	for _, offer := range offers {
+		var trigger Times
+		if err := json.Unmarshal(offer.Trigger, &trigger); err != nil {
+			return nil, err
+		}
+		if t.Unix() < trigger.From || t.Unix() > trigger.To {
+			continue
+		}
+		key, err := mismatchedFilterKey(offer, filterAttrs, allowInefficientQueries)
+		if err != nil {
+			return nil, err
+		}
+		if key != "" {
+			continue
+		}
+		if playerOffersByOfferID != nil {
+			reason, err := fallbackOfferReason(offer, playerOffersByOfferID, t)
+			if err != nil {
+				return nil, err
+			}
+			if reason != "" {
+				continue
+			}
+		}
+		triggersByOfferID[offer.ID] = trigger
+		triggeredOffers = append(triggeredOffers, offer)
+	}
+	triggeredOffers, err = filterSoldOutOffers(ctx, db, gameID, triggeredOffers, mr)
+	if err != nil {
+		return nil, err
+	}
+
+	fallbackOffers := map[string]*OfferToReturn{}
+	if len(triggeredOffers) == 0 {
+		return fallbackOffers, nil
+	}
+	offersByID := map[string]*Offer{}
+	offerVersions := make([]*OfferVersion, 0, len(triggeredOffers))
+	for _, offer := range triggeredOffers {
+		offersByID[offer.ID] = offer
+		offerVersions = append(offerVersions, &OfferVersion{
+			GameID:       offer.GameID,
+			OfferID:      offer.ID,
+			OfferVersion: offer.Version,
+		})
+	}
+	offerVersions, err = findOfferVersions(ctx, db, offerVersions, mr)
+	if err != nil {
+		return nil, err
+	}
+	for _, offerInstance := range offerVersions {
+		offer := offersByID[offerInstance.OfferID]
+		expireAt := triggersByOfferID[offer.ID].To
+		if offerPlayer, ok := playerOffersByOfferID[offer.ID]; ok {
+			expireAt, err = triggersByOfferID[offer.ID].PlayerExpireAt(offerPlayer.FirstViewTimestamp)
+			if err != nil {
+				return nil, err
+			}
+		}
+		fallbackOffers[offer.ID] = &OfferToReturn{
+			ID:             offerInstance.ID,
+			OfferID:        offer.ID,
+			ProductID:      offer.ProductID,
+			Contents:       offer.Contents,
+			Cost:           offer.Cost,
+			Metadata:       offer.Metadata,
+			ExpireAt:       expireAt,
+			RemainingStock: offer.remainingStock(),
+		}
+	}
+	return fallbackOffers, nil
+}
+
+//fallbackOfferReason returns why the player can't see a fallback offer at time t, or an empty string if they can
+func fallbackOfferReason(offer *Offer, playerOffersByOfferID map[string]*OfferPlayer, t time.Time) (string, error) {
+	offerPlayer, ok := playerOffersByOfferID[offer.ID]
+	if !ok {
+		offerPlayer = &OfferPlayer{}
+	}
+	reason, _, err := frequencyAndPeriodReason(offer, offerPlayer, t)
+	if err != nil || reason != "" {
+		return reason, err
+	}
+	return prerequisitesReason(offer, playerOffersByOfferID)
+}
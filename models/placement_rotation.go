@@ -0,0 +1,193 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	edat "github.com/topfreegames/extensions/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//Rotation of a placement shows each player Size offers picked from the enabled offers of the placement,
+//of which the ones the player can't see are then removed. The pick is the same during a day, which starts
+//ResetOffset after midnight UTC, and changes at the next one.
+type Rotation struct {
+	Size        int    `json:"size"`
+	ResetOffset string `json:"resetOffset,omitempty"`
+}
+
+//RotationState is the pick of the offers of a rotated placement for a player at a time
+type RotationState struct {
+	Placement string `json:"placement"`
+	Size      int    `json:"size"`
+	Day       string `json:"day"`
+	Seed      string `json:"seed"`
+	ResetAt   int64  `json:"resetAt"`
+}
+
+func (r *Rotation) validate() error {
+	if r.Size < 1 {
+		return fmt.Errorf("size must be at least 1")
+	}
+	offset, err := r.resetOffset()
+	if err != nil {
+		return err
+	}
+	if offset < 0 || offset >= 24*time.Hour {
+		return fmt.Errorf("resetOffset must be between 0 and 24h")
+	}
+	return nil
+}
+
+func (r *Rotation) resetOffset() (time.Duration, error) {
+	if r.ResetOffset == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(r.ResetOffset)
+}
+
+//dayStart returns when the day of the rotation at time t started
+func (r *Rotation) dayStart(t time.Time) time.Time {
+	offset, _ := r.resetOffset()
+	shifted := t.UTC().Add(-offset)
+	return time.Date(shifted.Year(), shifted.Month(), shifted.Day(), 0, 0, 0, 0, time.UTC).Add(offset)
+}
+
+//RotationSeed returns the seed of the pick of the offers of a placement for a player in a day,
+//given as YYYY-MM-DD. Each offer template is scored with the 64 bit FNV-1a hash of
+//"<seed>:<offer template id>" and the offers with the lowest scores are picked.
+func RotationSeed(gameID, playerID, placement, day string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", gameID, playerID, placement, day)
+}
+
+func rotationScore(seed, offerID string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(seed + ":" + offerID))
+	return h.Sum64()
+}
+
+//pickRotatedOfferIDs returns the ids of the size offer templates picked for the seed.
+//An offer keeps its score when other offers enter or leave the pool.
+func pickRotatedOfferIDs(seed string, offerIDs []string, size int) map[string]bool {
+	sortedOfferIDs := append([]string{}, offerIDs...)
+	sort.Slice(sortedOfferIDs, func(i, j int) bool {
+		si, sj := rotationScore(seed, sortedOfferIDs[i]), rotationScore(seed, sortedOfferIDs[j])
+		if si != sj {
+			return si < sj
+		}
+		return sortedOfferIDs[i] < sortedOfferIDs[j]
+	})
+	if len(sortedOfferIDs) > size {
+		sortedOfferIDs = sortedOfferIDs[:size]
+	}
+	picked := map[string]bool{}
+	for _, offerID := range sortedOfferIDs {
+		picked[offerID] = true
+	}
+	return picked
+}
+
+//state returns the pick of the offers of a placement for a player at time t
+func (r *Rotation) state(gameID, playerID, placement string, t time.Time) *RotationState {
+	dayStart := r.dayStart(t)
+	offset, _ := r.resetOffset()
+	day := dayStart.Add(-offset).Format("2006-01-02")
+	return &RotationState{
+		Placement: placement,
+		Size:      r.Size,
+		Day:       day,
+		Seed:      RotationSeed(gameID, playerID, placement, day),
+		ResetAt:   dayStart.Add(24 * time.Hour).Unix(),
+	}
+}
+
+//rotateOffers keeps, in their order, the offers picked by the rotation for a player at time t from the pool
+//of the placement. Offers that are not in the pool, like the disabled offers test players see, join it.
+func (r *Rotation) rotateOffers(gameID, playerID, placement string, pool []string, offers []*OfferToReturn, t time.Time) []*OfferToReturn {
+	offerIDs := append([]string{}, pool...)
+	for _, offer := range offers {
+		offerIDs = append(offerIDs, offer.OfferID)
+	}
+	picked := pickRotatedOfferIDs(r.state(gameID, playerID, placement, t).Seed, uniqueStrings(offerIDs), r.Size)
+
+	rotatedOffers := make([]*OfferToReturn, 0, r.Size)
+	for _, offer := range offers {
+		if picked[offer.OfferID] {
+			rotatedOffers = append(rotatedOffers, offer)
+		}
+	}
+	return rotatedOffers
+}
+
+//getRotationPools returns, by placement, the ids of the enabled offers of a game,
+//whether they are triggered or not, which the rotations of the placements pick from
+func getRotationPools(
+	ctx context.Context,
+	db runner.Connection,
+	gameID string,
+	mr *MixedMetricsReporter,
+) (map[string][]string, error) {
+	offers := []*Offer{}
+	err := mr.WithDatastoreSegment("offers", SegmentSelect, func() error {
+		builder := db.Select("id", "placement")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("offers").
+			Where("game_id = $1 AND enabled", gameID).
+			QueryStructs(&offers)
+	})
+	if err != nil {
+		return nil, err
+	}
+	pools := map[string][]string{}
+	for _, offer := range offers {
+		pools[offer.Placement] = append(pools[offer.Placement], offer.ID)
+	}
+	return pools, nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+//GetRotationStates returns the pick of the offers of the rotated placements of a game for a player at time t
+func GetRotationStates(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, playerID string,
+	t time.Time,
+	mr *MixedMetricsReporter,
+) ([]*RotationState, error) {
+	placements, err := ListPlacements(ctx, db, gameID, mr)
+	if err != nil {
+		return nil, err
+	}
+	states := []*RotationState{}
+	for _, placement := range placements {
+		rotation, err := placement.getRotation()
+		if err != nil {
+			return nil, err
+		}
+		if rotation != nil {
+			states = append(states, rotation.state(gameID, playerID, placement.Name, t))
+		}
+	}
+	return states, nil
+}
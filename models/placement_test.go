@@ -0,0 +1,313 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"hash/fnv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	edat "github.com/topfreegames/extensions/dat"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+	"gopkg.in/mgutz/dat.v2/dat"
+)
+
+var _ = Describe("Placement Model", func() {
+	currentTime := time.Unix(1486678000, 0)
+	expireDuration := 300 * time.Second
+	popupOfferID := "dd21ec96-2890-4ba0-b8e2-40ea67196990"
+	storeOfferID := "d5114990-77d7-45c4-ba5f-462fc86b213f"
+
+	upsertPlacement := func(placement *models.Placement) {
+		placement.GameID = defaultGameID
+		err := models.UpsertPlacement(nil, db, placement, offersCache, currentTime, nil)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	getOffer := func(offerID string) *models.Offer {
+		offer := new(models.Offer)
+		builder := db.SQL("SELECT * FROM offers WHERE id = $1 AND game_id = $2", offerID, defaultGameID)
+		builder.Execer = edat.NewExecer(builder.Execer)
+		err := builder.QueryStruct(offer)
+		Expect(err).NotTo(HaveOccurred())
+		return offer
+	}
+
+	getAvailableOffers := func(playerID string) map[string][]*models.OfferToReturn {
+		offers, err := models.GetAvailableOffers(nil, db, offersCache, defaultGameID, playerID, currentTime, expireDuration, map[string]string{}, false, nil)
+		Expect(err).NotTo(HaveOccurred())
+		return offers
+	}
+
+	Describe("Upsert placement", func() {
+		It("should create and replace a placement and audit it", func() {
+			upsertPlacement(&models.Placement{Name: "store"})
+			upsertPlacement(&models.Placement{Name: "store", MaxOffers: dat.NullInt64From(1)})
+
+			placements, err := models.ListPlacements(nil, db, defaultGameID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(placements).To(HaveLen(1))
+			Expect(placements[0].Name).To(Equal("store"))
+			Expect(placements[0].Ordering).To(Equal(models.PlacementOrderingPriority))
+			Expect(placements[0].MaxOffers.Int64).To(BeEquivalentTo(1))
+
+			events, err := models.ListAuditEvents(nil, db, defaultGameID, "", time.Unix(0, 0), 10, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(2))
+		})
+
+		It("should return error if the fallback offer is not an offer of the game", func() {
+			err := models.UpsertPlacement(nil, db, &models.Placement{
+				GameID:          defaultGameID,
+				Name:            "store",
+				FallbackOfferID: dat.NullStringFrom("a6ab2d3b-c1b9-4b5a-bf93-27a16b4c6f5e"),
+			}, offersCache, currentTime, nil)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.InvalidModelError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Describe("Delete placement", func() {
+		It("should delete a placement that no offer uses", func() {
+			upsertPlacement(&models.Placement{Name: "daily-deals"})
+
+			err := models.DeletePlacement(nil, db, defaultGameID, "daily-deals", offersCache, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			placements, err := models.ListPlacements(nil, db, defaultGameID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(placements).To(BeEmpty())
+		})
+
+		It("should return error if an offer uses the placement", func() {
+			upsertPlacement(&models.Placement{Name: "store"})
+
+			err := models.DeletePlacement(nil, db, defaultGameID, "store", offersCache, nil)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.InvalidModelError)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should return error if the placement does not exist", func() {
+			err := models.DeletePlacement(nil, db, defaultGameID, "store", offersCache, nil)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.ModelNotFoundError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Describe("Offer placement validation", func() {
+		It("should accept any placement if the game has no placements", func() {
+			offer := getOffer(storeOfferID)
+			offer.Placement = "popUp"
+
+			_, err := models.UpdateOffer(nil, db, offer, offersCache, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should return error if the placement is not a placement of the game", func() {
+			upsertPlacement(&models.Placement{Name: "store"})
+			upsertPlacement(&models.Placement{Name: "popup"})
+			offer := getOffer(storeOfferID)
+			offer.Placement = "popUp"
+
+			_, err := models.UpdateOffer(nil, db, offer, offersCache, nil)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.InvalidModelError)
+			Expect(ok).To(BeTrue())
+
+			offer.Placement = "popup"
+			_, err = models.UpdateOffer(nil, db, offer, offersCache, nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("Get available offers", func() {
+		It("should limit the offers of a placement to its maximum", func() {
+			upsertPlacement(&models.Placement{Name: "store", MaxOffers: dat.NullInt64From(1)})
+
+			offers := getAvailableOffers("placement-player")
+
+			Expect(offers["store"]).To(HaveLen(1))
+			Expect(offers["store"][0].ProductID).To(Equal("com.tfg.sample.3"))
+		})
+
+		It("should sort the offers of a placement by expiration", func() {
+			offer := getOffer(storeOfferID)
+			offer.Priority = 10
+			_, err := models.UpdateOffer(nil, db, offer, offersCache, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getAvailableOffers("placement-player")["store"][0].ProductID).To(Equal("com.tfg.sample.2"))
+
+			upsertPlacement(&models.Placement{Name: "store", Ordering: models.PlacementOrderingExpiration})
+
+			offers := getAvailableOffers("placement-player")
+			Expect(offers["store"]).To(HaveLen(2))
+			Expect(offers["store"][0].ProductID).To(Equal("com.tfg.sample.3"))
+			Expect(offers["store"][1].ProductID).To(Equal("com.tfg.sample.2"))
+		})
+
+		It("should return the fallback offer of a placement without offers", func() {
+			upsertPlacement(&models.Placement{Name: "daily-deals", FallbackOfferID: dat.NullStringFrom(popupOfferID)})
+
+			offers := getAvailableOffers("placement-player")
+
+			Expect(offers["daily-deals"]).To(HaveLen(1))
+			Expect(offers["daily-deals"][0].ProductID).To(Equal("com.tfg.sample"))
+			Expect(offers["daily-deals"][0].ExpireAt).To(Equal(int64(1486679000)))
+		})
+
+		It("should not return the fallback offer if the player can't see it", func() {
+			upsertPlacement(&models.Placement{Name: "daily-deals", FallbackOfferID: dat.NullStringFrom(popupOfferID)})
+			_, _, err := models.ViewOffer(nil, db, offersCache, defaultGameID, "eb7e8d2a-2739-4da3-aa31-7970b63bdad7", "placement-player", "impression-1", currentTime, cacheExpireDuration, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			offers := getAvailableOffers("placement-player")
+
+			Expect(offers).NotTo(HaveKey("daily-deals"))
+			Expect(getAvailableOffers("other-placement-player")).To(HaveKey("daily-deals"))
+		})
+
+		It("should not return the fallback offer if its filters do not match the player", func() {
+			upsertPlacement(&models.Placement{Name: "daily-deals", FallbackOfferID: dat.NullStringFrom(popupOfferID)})
+			_, err := db.Update("offers").
+				Set("filters", dat.JSON([]byte(`{"level": {"eq": "5"}}`))).
+				Where("id = $1", popupOfferID).
+				Exec()
+			Expect(err).NotTo(HaveOccurred())
+
+			offers, err := models.GetAvailableOffers(nil, db, offersCache, defaultGameID, "placement-player", currentTime, expireDuration, map[string]string{"level": "1"}, false, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offers).NotTo(HaveKey("daily-deals"))
+
+			offers, err = models.GetAvailableOffers(nil, db, offersCache, defaultGameID, "placement-player", currentTime, expireDuration, map[string]string{"level": "5"}, false, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offers).To(HaveKey("daily-deals"))
+		})
+
+		It("should not return the fallback offer if it is disabled", func() {
+			upsertPlacement(&models.Placement{Name: "daily-deals", FallbackOfferID: dat.NullStringFrom(popupOfferID)})
+			err := models.SetEnabledOffer(nil, db, defaultGameID, popupOfferID, false, offersCache, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			offers := getAvailableOffers("placement-player")
+
+			Expect(offers).NotTo(HaveKey("daily-deals"))
+		})
+	})
+
+	Describe("Rotation", func() {
+		otherStoreOfferID := "a411fbcf-dddc-4153-b42b-3f9b2684c965"
+		productIDs := map[string]string{
+			storeOfferID:      "com.tfg.sample.2",
+			otherStoreOfferID: "com.tfg.sample.3",
+		}
+		offerInstanceIDs := map[string]string{
+			storeOfferID:      "38cf3ed6-b999-4ee8-9e21-8f700532b37c",
+			otherStoreOfferID: "35df52e7-3161-446f-975b-92f32871e37c",
+		}
+
+		//pickedOfferID reproduces the pick of a rotation of size 1 from its documented seed
+		pickedOfferID := func(playerID string) string {
+			states, err := models.GetRotationStates(nil, db, defaultGameID, playerID, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(states).To(HaveLen(1))
+			score := func(offerID string) uint64 {
+				h := fnv.New64a()
+				h.Write([]byte(states[0].Seed + ":" + offerID))
+				return h.Sum64()
+			}
+			if score(storeOfferID) < score(otherStoreOfferID) {
+				return storeOfferID
+			}
+			return otherStoreOfferID
+		}
+
+		It("should return the offers picked for the player and the day", func() {
+			upsertPlacement(&models.Placement{Name: "store", Rotation: dat.JSON([]byte(`{"size": 1}`))})
+
+			for _, playerID := range []string{"rotation-player-1", "rotation-player-2", "rotation-player-3"} {
+				offers := getAvailableOffers(playerID)
+				Expect(offers["store"]).To(HaveLen(1))
+				Expect(offers["store"][0].ProductID).To(Equal(productIDs[pickedOfferID(playerID)]))
+			}
+		})
+
+		It("should not replace the pick with another offer if the player can't see it", func() {
+			upsertPlacement(&models.Placement{Name: "store", Rotation: dat.JSON([]byte(`{"size": 1}`))})
+			picked := pickedOfferID("rotation-player")
+			_, _, _, err := models.ClaimOffer(nil, db, defaultGameID, offerInstanceIDs[picked], "rotation-player", productIDs[picked], "transaction-1", currentTime.Unix(), currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			offers := getAvailableOffers("rotation-player")
+
+			Expect(offers).NotTo(HaveKey("store"))
+		})
+
+		It("should pick the offers of the given player in previews", func() {
+			upsertPlacement(&models.Placement{Name: "store", Rotation: dat.JSON([]byte(`{"size": 1}`))})
+
+			for _, playerID := range []string{"rotation-player-1", "rotation-player-2", "rotation-player-3"} {
+				offers, err := models.PreviewAvailableOffers(nil, db, defaultGameID, playerID, models.FixedClock{Time: currentTime}, nil, false, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(offers["store"]).To(HaveLen(1))
+				Expect(offers["store"][0].ProductID).To(Equal(productIDs[pickedOfferID(playerID)]))
+			}
+		})
+
+		It("should return the day, seed and reset of the rotation", func() {
+			upsertPlacement(&models.Placement{Name: "store", Rotation: dat.JSON([]byte(`{"size": 1, "resetOffset": "4h"}`))})
+
+			states, err := models.GetRotationStates(nil, db, defaultGameID, "rotation-player", currentTime, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(states).To(HaveLen(1))
+			Expect(states[0].Day).To(Equal("2017-02-09"))
+			Expect(states[0].Seed).To(Equal(models.RotationSeed(defaultGameID, "rotation-player", "store", "2017-02-09")))
+			Expect(states[0].ResetAt).To(Equal(int64(1486699200)))
+		})
+
+		It("should explain the offers the rotation did not pick", func() {
+			upsertPlacement(&models.Placement{Name: "store", Rotation: dat.JSON([]byte(`{"size": 1}`))})
+			picked := pickedOfferID("rotation-player")
+
+			explanations, err := models.ExplainAvailableOffers(nil, db, defaultGameID, "rotation-player", currentTime, map[string]string{}, false, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			for _, explanation := range explanations {
+				if explanation.Placement != "store" {
+					continue
+				}
+				Expect(explanation.Included).To(Equal(explanation.OfferID == picked))
+				if explanation.OfferID != picked {
+					Expect(explanation.Rule).To(Equal(models.UnavailableRotation))
+				}
+			}
+		})
+
+		It("should return error if the rotation is invalid", func() {
+			err := models.UpsertPlacement(nil, db, &models.Placement{
+				GameID:   defaultGameID,
+				Name:     "store",
+				Rotation: dat.JSON([]byte(`{"size": 1, "resetOffset": "25h"}`)),
+			}, offersCache, currentTime, nil)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.InvalidModelError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+})
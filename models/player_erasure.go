@@ -0,0 +1,188 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+
+	uuid "github.com/satori/go.uuid"
+	edat "github.com/topfreegames/extensions/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//auditedPlayerIDKeys are the fields of audit events that may hold a player id
+var auditedPlayerIDKeys = []string{"playerId", "fromPlayerId", "toPlayerId"}
+
+//PlayerErasure is the result of erasing a player from a game. It is also the audit record of
+//the erasure, so it does not contain the erased player id.
+type PlayerErasure struct {
+	GameID              string `json:"gameId"`
+	AnonymizedPlayerID  string `json:"anonymizedPlayerId,omitempty"`
+	OfferPlayers        int64  `json:"offerPlayers"`
+	OfferInstances      int64  `json:"offerInstances"`
+	DedupeKeys          int64  `json:"dedupeKeys"`
+	TestPlayers         int64  `json:"testPlayers"`
+	PlayerImpressions   int64  `json:"playerImpressions"`
+	Claims              int64  `json:"claims"`
+	ReceiptTransactions int64  `json:"receiptTransactions"`
+	AuditEvents         int64  `json:"auditEvents"`
+}
+
+//ErasePlayer removes the state of a player in a game. The claims ledger and the audit log are
+//kept, but the player id is replaced in them by a random one. Erasing a player that has no data
+//only records the erasure.
+func ErasePlayer(ctx context.Context, db runner.Connection, gameID, playerID string, mr *MixedMetricsReporter) (*PlayerErasure, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.AutoRollback()
+
+	erasure := &PlayerErasure{GameID: gameID}
+	for _, table := range []struct {
+		name    string
+		deleted *int64
+	}{
+		{"offer_players", &erasure.OfferPlayers},
+		{"offer_instances", &erasure.OfferInstances},
+		{"dedupe_keys", &erasure.DedupeKeys},
+		{"test_players", &erasure.TestPlayers},
+		{"player_impressions", &erasure.PlayerImpressions},
+	} {
+		*table.deleted, err = deletePlayerRows(ctx, tx, table.name, gameID, playerID, mr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	anonymizedPlayerID := "erased-" + uuid.NewV4().String()
+	err = mr.WithDatastoreSegment("claims", SegmentUpdate, func() error {
+		builder := tx.Update("claims")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		res, err := builder.Set("player_id", anonymizedPlayerID).
+			Where("game_id = $1 AND player_id = $2", gameID, playerID).
+			Exec()
+		if err != nil {
+			return err
+		}
+		erasure.Claims = res.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = mr.WithDatastoreSegment("receipt_transactions", SegmentUpdate, func() error {
+		builder := tx.Update("receipt_transactions")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		res, err := builder.Set("player_id", anonymizedPlayerID).
+			Where("game_id = $1 AND player_id = $2", gameID, playerID).
+			Exec()
+		if err != nil {
+			return err
+		}
+		erasure.ReceiptTransactions = res.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range auditedPlayerIDKeys {
+		err = mr.WithDatastoreSegment("audit_events", SegmentUpdate, func() error {
+			builder := tx.SQL(`
+				UPDATE audit_events SET
+					before = CASE WHEN before->>$4::text = $2 THEN jsonb_set(before, ARRAY[$4::text], to_jsonb($3::text)) ELSE before END,
+					after = CASE WHEN after->>$4::text = $2 THEN jsonb_set(after, ARRAY[$4::text], to_jsonb($3::text)) ELSE after END
+				WHERE game_id = $1 AND (before->>$4::text = $2 OR after->>$4::text = $2)`,
+				gameID, playerID, anonymizedPlayerID, key,
+			)
+			builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+			res, err := builder.Exec()
+			if err != nil {
+				return err
+			}
+			erasure.AuditEvents += res.RowsAffected
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if erasure.Claims > 0 || erasure.ReceiptTransactions > 0 || erasure.AuditEvents > 0 {
+		erasure.AnonymizedPlayerID = anonymizedPlayerID
+	}
+
+	err = insertAuditEvent(ctx, tx, gameID, "", AuditActionErasePlayer, nil, erasure, mr)
+	if err != nil {
+		return nil, err
+	}
+	return erasure, tx.Commit()
+}
+
+//ResetPlayerOffer forgets the impressions and claims of a player for one offer, so it can be
+//seen and claimed again. The claims ledger is kept.
+func ResetPlayerOffer(ctx context.Context, db runner.Connection, gameID, playerID, offerID string, mr *MixedMetricsReporter) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.AutoRollback()
+
+	var offerPlayer OfferPlayer
+	err = mr.WithDatastoreSegment("offer_players", SegmentDelete, func() error {
+		builder := tx.DeleteFrom("offer_players")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.
+			Where("game_id = $1 AND player_id = $2 AND offer_id = $3", gameID, playerID, offerID).
+			Returning("*").
+			QueryStruct(&offerPlayer)
+	})
+	err = handleNotFoundError("OfferPlayer", map[string]interface{}{
+		"GameID":   gameID,
+		"PlayerID": playerID,
+		"OfferID":  offerID,
+	}, err)
+	if err != nil {
+		return err
+	}
+
+	err = mr.WithDatastoreSegment("dedupe_keys", SegmentDelete, func() error {
+		builder := tx.DeleteFrom("dedupe_keys")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		_, err := builder.
+			Where("game_id = $1 AND player_id = $2 AND offer_id = $3", gameID, playerID, offerID).
+			Exec()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEvent(ctx, tx, gameID, offerID, AuditActionResetPlayerOffer, &offerPlayer, nil, mr)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func deletePlayerRows(ctx context.Context, db runner.Connection, table, gameID, playerID string, mr *MixedMetricsReporter) (int64, error) {
+	var deleted int64
+	err := mr.WithDatastoreSegment(table, SegmentDelete, func() error {
+		builder := db.DeleteFrom(table)
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		res, err := builder.Where("game_id = $1 AND player_id = $2", gameID, playerID).Exec()
+		if err != nil {
+			return err
+		}
+		deleted = res.RowsAffected
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,107 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+)
+
+var _ = Describe("Player Erasure Model", func() {
+	currentTime := time.Unix(1486678000, 0)
+	playerID := "player-1"
+	offerID := "dd21ec96-2890-4ba0-b8e2-40ea67196990"
+	offerInstanceID := "eb7e8d2a-2739-4da3-aa31-7970b63bdad7"
+
+	Describe("Erase player", func() {
+		It("should remove the state of the player and anonymize their claims", func() {
+			_, _, _, err := models.ClaimOffer(nil, db, defaultGameID, offerInstanceID, playerID, "com.tfg.sample", "transaction-1", currentTime.Unix(), currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+			err = models.AddTestPlayer(nil, db, &models.TestPlayer{GameID: defaultGameID, PlayerID: playerID}, offersCache, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			erasure, err := models.ErasePlayer(nil, db, defaultGameID, playerID, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(erasure.OfferPlayers).To(Equal(int64(1)))
+			Expect(erasure.OfferInstances).To(Equal(int64(1)))
+			Expect(erasure.DedupeKeys).To(Equal(int64(1)))
+			Expect(erasure.TestPlayers).To(Equal(int64(1)))
+			Expect(erasure.Claims).To(Equal(int64(1)))
+			Expect(erasure.AuditEvents).To(Equal(int64(1)))
+			Expect(erasure.AnonymizedPlayerID).To(HavePrefix("erased-"))
+
+			offerPlayers, err := models.GetOffersByPlayer(nil, db, defaultGameID, playerID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offerPlayers).To(BeEmpty())
+
+			claims, err := models.ListClaims(nil, db, defaultGameID, playerID, time.Unix(0, 0), 10, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(claims).To(BeEmpty())
+			claims, err = models.ListClaims(nil, db, defaultGameID, erasure.AnonymizedPlayerID, time.Unix(0, 0), 10, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(claims).To(HaveLen(1))
+
+			events, err := models.ListAuditEvents(nil, db, defaultGameID, "", time.Unix(0, 0), 10, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(2))
+			Expect(string(events[0].After)).NotTo(ContainSubstring(playerID))
+			Expect(events[1].Action).To(Equal(models.AuditActionErasePlayer))
+			Expect(string(events[1].After)).NotTo(ContainSubstring(playerID))
+		})
+
+		It("should be idempotent", func() {
+			_, err := models.ErasePlayer(nil, db, defaultGameID, playerID, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			erasure, err := models.ErasePlayer(nil, db, defaultGameID, playerID, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(erasure.OfferPlayers).To(BeZero())
+			Expect(erasure.AnonymizedPlayerID).To(BeEmpty())
+
+			events, err := models.ListAuditEvents(nil, db, defaultGameID, "", time.Unix(0, 0), 10, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(2))
+		})
+	})
+
+	Describe("Reset player offer", func() {
+		It("should forget the impressions and claims of the player for the offer", func() {
+			_, _, err := models.ViewOffer(nil, db, offersCache, defaultGameID, offerInstanceID, playerID, "impression-1", currentTime, cacheExpireDuration, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = models.ResetPlayerOffer(nil, db, defaultGameID, playerID, offerID, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = models.GetOfferPlayer(nil, db, defaultGameID, playerID, offerID, nil)
+			Expect(models.IsNoRowsInResultSetError(err)).To(BeTrue())
+
+			isReplay, _, err := models.ViewOffer(nil, db, offersCache, defaultGameID, offerInstanceID, playerID, "impression-1", currentTime, cacheExpireDuration, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isReplay).To(BeFalse())
+
+			events, err := models.ListAuditEvents(nil, db, defaultGameID, offerID, time.Unix(0, 0), 10, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Action).To(Equal(models.AuditActionResetPlayerOffer))
+		})
+
+		It("should return error if the player has no state for the offer", func() {
+			err := models.ResetPlayerOffer(nil, db, defaultGameID, "unknown-player", offerID, nil)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.ModelNotFoundError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,151 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	edat "github.com/topfreegames/extensions/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//PlayerGameExport is everything stored about a player in a game
+type PlayerGameExport struct {
+	GameID              string                `json:"gameId"`
+	TestPlayer          bool                  `json:"testPlayer"`
+	OfferPlayers        []*OfferPlayer        `json:"offerPlayers"`
+	Impressions         []*DedupeKey          `json:"impressions"`
+	Transactions        []*DedupeKey          `json:"transactions"`
+	Claims              []*Claim              `json:"claims"`
+	OfferInstances      []*OfferInstance      `json:"offerInstances"`
+	PlayerImpressions   []*PlayerImpressions  `json:"playerImpressions"`
+	ReceiptTransactions []*ReceiptTransaction `json:"receiptTransactions"`
+}
+
+//GetPlayerGameIDs returns the ids of the games that store data about the player
+func GetPlayerGameIDs(ctx context.Context, db runner.Connection, playerID string, mr *MixedMetricsReporter) ([]string, error) {
+	gameIDs := []string{}
+	err := mr.WithDatastoreSegment("offer_players", SegmentSelect, func() error {
+		builder := db.SQL(`
+			SELECT game_id FROM offer_players WHERE player_id = $1
+			UNION SELECT game_id FROM offer_instances WHERE player_id = $1
+			UNION SELECT game_id FROM dedupe_keys WHERE player_id = $1
+			UNION SELECT game_id FROM claims WHERE player_id = $1
+			UNION SELECT game_id FROM test_players WHERE player_id = $1
+			UNION SELECT game_id FROM player_impressions WHERE player_id = $1
+			UNION SELECT game_id FROM receipt_transactions WHERE player_id = $1
+			ORDER BY game_id`,
+			playerID,
+		)
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.QuerySlice(&gameIDs)
+	})
+	return gameIDs, err
+}
+
+//GetPlayerGameExport returns everything stored about a player in a game
+func GetPlayerGameExport(ctx context.Context, db runner.Connection, gameID, playerID string, mr *MixedMetricsReporter) (*PlayerGameExport, error) {
+	export := &PlayerGameExport{
+		GameID:              gameID,
+		OfferPlayers:        []*OfferPlayer{},
+		Impressions:         []*DedupeKey{},
+		Transactions:        []*DedupeKey{},
+		Claims:              []*Claim{},
+		OfferInstances:      []*OfferInstance{},
+		PlayerImpressions:   []*PlayerImpressions{},
+		ReceiptTransactions: []*ReceiptTransaction{},
+	}
+
+	var err error
+	export.TestPlayer, err = IsTestPlayer(ctx, db, gameID, playerID, mr)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, table := range []struct {
+		name    string
+		where   string
+		args    []interface{}
+		order   string
+		records interface{}
+	}{
+		{"offer_players", "game_id = $1 AND player_id = $2", []interface{}{gameID, playerID}, "offer_id", &export.OfferPlayers},
+		{"dedupe_keys", "game_id = $1 AND player_id = $2 AND kind = $3", []interface{}{gameID, playerID, DedupeKindImpression}, "created_at, key", &export.Impressions},
+		{"dedupe_keys", "game_id = $1 AND player_id = $2 AND kind = $3", []interface{}{gameID, playerID, DedupeKindTransaction}, "created_at, key", &export.Transactions},
+		{"claims", "game_id = $1 AND player_id = $2", []interface{}{gameID, playerID}, "claimed_at, id", &export.Claims},
+		{"offer_instances", "game_id = $1 AND player_id = $2", []interface{}{gameID, playerID}, "created_at, id", &export.OfferInstances},
+		{"player_impressions", "game_id = $1 AND player_id = $2", []interface{}{gameID, playerID}, "placement", &export.PlayerImpressions},
+		{"receipt_transactions", "game_id = $1 AND player_id = $2", []interface{}{gameID, playerID}, "created_at, transaction_id", &export.ReceiptTransactions},
+	} {
+		err = mr.WithDatastoreSegment(table.name, SegmentSelect, func() error {
+			builder := db.Select("*")
+			builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+			return builder.From(table.name).
+				Where(table.where, table.args...).
+				OrderBy(table.order).
+				QueryStructs(table.records)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return export, nil
+}
+
+//ExportPlayer writes as JSON into writer everything stored about a player in a game, or in every
+//game if gameID is empty. Games are read and written one at a time, so the export is streamed.
+func ExportPlayer(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, playerID string,
+	t time.Time,
+	writer io.Writer,
+	mr *MixedMetricsReporter,
+) error {
+	gameIDs := []string{gameID}
+	if gameID == "" {
+		var err error
+		gameIDs, err = GetPlayerGameIDs(ctx, db, playerID, mr)
+		if err != nil {
+			return err
+		}
+	}
+
+	playerIDJSON, err := json.Marshal(playerID)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(writer, `{"playerId":%s,"exportedAt":%d,"games":[`, playerIDJSON, t.Unix())
+	if err != nil {
+		return err
+	}
+
+	for i, id := range gameIDs {
+		export, err := GetPlayerGameExport(ctx, db, id, playerID, mr)
+		if err != nil {
+			return err
+		}
+		bts, err := json.Marshal(export)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			bts = append([]byte(","), bts...)
+		}
+		if _, err = writer.Write(bts); err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(writer, "]}\n")
+	return err
+}
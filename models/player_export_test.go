@@ -0,0 +1,89 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/topfreegames/offers/models"
+)
+
+var _ = Describe("Player Export Model", func() {
+	currentTime := time.Unix(1486678000, 0)
+	playerID := "player-1"
+	offerInstanceID := "eb7e8d2a-2739-4da3-aa31-7970b63bdad7"
+
+	Describe("Export player", func() {
+		It("should write everything stored about the player", func() {
+			_, _, err := models.ViewOffer(nil, db, offersCache, defaultGameID, offerInstanceID, playerID, "impression-1", currentTime, cacheExpireDuration, nil)
+			Expect(err).NotTo(HaveOccurred())
+			_, _, _, err = models.ClaimOffer(nil, db, defaultGameID, offerInstanceID, playerID, "com.tfg.sample", "transaction-1", currentTime.Unix(), currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			var buffer bytes.Buffer
+			err = models.ExportPlayer(nil, db, "", playerID, currentTime, &buffer, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			var export struct {
+				PlayerID   string                     `json:"playerId"`
+				ExportedAt int64                      `json:"exportedAt"`
+				Games      []*models.PlayerGameExport `json:"games"`
+			}
+			err = json.Unmarshal(buffer.Bytes(), &export)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(export.PlayerID).To(Equal(playerID))
+			Expect(export.ExportedAt).To(Equal(currentTime.Unix()))
+			Expect(export.Games).To(HaveLen(1))
+			game := export.Games[0]
+			Expect(game.GameID).To(Equal(defaultGameID))
+			Expect(game.OfferPlayers).To(HaveLen(1))
+			Expect(game.OfferPlayers[0].ClaimCounter).To(Equal(1))
+			Expect(game.Impressions).To(HaveLen(1))
+			Expect(game.Impressions[0].Key).To(Equal("impression-1"))
+			Expect(game.Transactions).To(HaveLen(1))
+			Expect(game.Transactions[0].Key).To(Equal("transaction-1"))
+			Expect(game.Claims).To(HaveLen(1))
+			Expect(game.OfferInstances).To(HaveLen(1))
+			Expect(game.OfferInstances[0].ID).To(Equal("abcd8d2a-2739-4da3-aa31-8970b63bdad7"))
+		})
+
+		It("should write an empty game if the player has no data in it", func() {
+			var buffer bytes.Buffer
+			err := models.ExportPlayer(nil, db, defaultGameID, "unknown-player", currentTime, &buffer, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(MatchJSON(`{
+				"playerId": "unknown-player",
+				"exportedAt": 1486678000,
+				"games": [{
+					"gameId": "offers-game",
+					"testPlayer": false,
+					"offerPlayers": [],
+					"impressions": [],
+					"transactions": [],
+					"claims": [],
+					"offerInstances": [],
+					"playerImpressions": [],
+					"receiptTransactions": []
+				}]
+			}`))
+		})
+
+		It("should write no games if the player has no data", func() {
+			var buffer bytes.Buffer
+			err := models.ExportPlayer(nil, db, "", "unknown-player", currentTime, &buffer, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(MatchJSON(`{"playerId": "unknown-player", "exportedAt": 1486678000, "games": []}`))
+		})
+	})
+})
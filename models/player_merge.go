@@ -0,0 +1,231 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"time"
+
+	edat "github.com/topfreegames/extensions/dat"
+	"github.com/topfreegames/offers/errors"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//PlayerMerge is the result of merging the state of a player into another
+type PlayerMerge struct {
+	GameID       string `json:"gameId"`
+	FromPlayerID string `json:"fromPlayerId"`
+	ToPlayerID   string `json:"toPlayerId"`
+	OfferPlayers int64  `json:"offerPlayers"`
+	DedupeKeys   int64  `json:"dedupeKeys"`
+
+	Claims              int64 `json:"claims"`
+	PlayerImpressions   int64 `json:"playerImpressions"`
+	ReceiptTransactions int64 `json:"receiptTransactions"`
+}
+
+//MergePlayers moves the state of fromPlayerID into toPlayerID, for instance when a guest links an account.
+//Offers seen or claimed by both have their counters summed and keep the latest timestamps, and the
+//impression and transaction ids of both are kept, so replays of the old player are still detected.
+//The claims and verified receipt transactions of the old player move to the new one, and so do the
+//impressions counted by the impression caps. Claims of a transaction the new player already claimed
+//for the same offer are left to the old player, the ledger already has them.
+func MergePlayers(ctx context.Context, db runner.Connection, gameID, fromPlayerID, toPlayerID string, mr *MixedMetricsReporter) (*PlayerMerge, error) {
+	if fromPlayerID == toPlayerID {
+		return nil, errors.NewInvalidModelError("PlayerMerge", "fromPlayerId and toPlayerId must be different")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.AutoRollback()
+
+	merge := &PlayerMerge{
+		GameID:       gameID,
+		FromPlayerID: fromPlayerID,
+		ToPlayerID:   toPlayerID,
+	}
+
+	err = mr.WithDatastoreSegment("offer_players", SegmentUpsert, func() error {
+		builder := tx.SQL(`
+			INSERT INTO offer_players (
+				game_id, player_id, offer_id, claim_counter, claim_timestamp,
+				view_counter, view_timestamp, first_view_timestamp, receipts, revocations, test_player
+			)
+			SELECT game_id, $3, offer_id, claim_counter, claim_timestamp,
+				view_counter, view_timestamp, first_view_timestamp, receipts, revocations, test_player
+			FROM offer_players
+			WHERE game_id = $1 AND player_id = $2
+			ON CONFLICT (game_id, player_id, offer_id) DO UPDATE SET
+				claim_counter = COALESCE(offer_players.claim_counter, 0) + COALESCE(EXCLUDED.claim_counter, 0),
+				claim_timestamp = GREATEST(offer_players.claim_timestamp, EXCLUDED.claim_timestamp),
+				view_counter = COALESCE(offer_players.view_counter, 0) + COALESCE(EXCLUDED.view_counter, 0),
+				view_timestamp = GREATEST(offer_players.view_timestamp, EXCLUDED.view_timestamp),
+				first_view_timestamp = LEAST(offer_players.first_view_timestamp, EXCLUDED.first_view_timestamp),
+				receipts = EXCLUDED.receipts || offer_players.receipts,
+				revocations = EXCLUDED.revocations || offer_players.revocations,
+				test_player = offer_players.test_player OR EXCLUDED.test_player`,
+			gameID, fromPlayerID, toPlayerID,
+		)
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		res, err := builder.Exec()
+		if err != nil {
+			return err
+		}
+		merge.OfferPlayers = res.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = mr.WithDatastoreSegment("dedupe_keys", SegmentInsert, func() error {
+		builder := tx.SQL(`
+			INSERT INTO dedupe_keys (game_id, player_id, offer_id, kind, key, created_at)
+			SELECT game_id, $3, offer_id, kind, key, created_at
+			FROM dedupe_keys
+			WHERE game_id = $1 AND player_id = $2
+			ON CONFLICT DO NOTHING`,
+			gameID, fromPlayerID, toPlayerID,
+		)
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		res, err := builder.Exec()
+		if err != nil {
+			return err
+		}
+		merge.DedupeKeys = res.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = mr.WithDatastoreSegment("claims", SegmentUpdate, func() error {
+		builder := tx.SQL(`
+			UPDATE claims SET player_id = $3
+			WHERE game_id = $1 AND player_id = $2 AND NOT EXISTS (
+				SELECT 1 FROM claims c
+				WHERE c.game_id = $1 AND c.player_id = $3
+				AND c.offer_id = claims.offer_id AND c.transaction_id = claims.transaction_id
+			)`,
+			gameID, fromPlayerID, toPlayerID,
+		)
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		res, err := builder.Exec()
+		if err != nil {
+			return err
+		}
+		merge.Claims = res.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = mr.WithDatastoreSegment("receipt_transactions", SegmentUpdate, func() error {
+		builder := tx.Update("receipt_transactions")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		res, err := builder.Set("player_id", toPlayerID).
+			Where("game_id = $1 AND player_id = $2", gameID, fromPlayerID).
+			Exec()
+		if err != nil {
+			return err
+		}
+		merge.ReceiptTransactions = res.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	merge.PlayerImpressions, err = mergePlayerImpressions(ctx, tx, gameID, fromPlayerID, toPlayerID, mr)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, table := range []string{"offer_players", "dedupe_keys", "player_impressions"} {
+		_, err = deletePlayerRows(ctx, tx, table, gameID, fromPlayerID, mr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = insertAuditEvent(ctx, tx, gameID, "", AuditActionMergePlayers, nil, merge, mr)
+	if err != nil {
+		return nil, err
+	}
+	return merge, tx.Commit()
+}
+
+//mergePlayerImpressions moves the impressions counted by the impression caps of fromPlayerID into toPlayerID.
+//If both players have impressions of a cap and the window of one started before the other ended,
+//the merged window starts at the first one and counts the impressions of both, or else the latest window is kept.
+func mergePlayerImpressions(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, fromPlayerID, toPlayerID string,
+	mr *MixedMetricsReporter,
+) (int64, error) {
+	fromImpressions, err := getPlayerImpressions(ctx, db, gameID, fromPlayerID, mr)
+	if err != nil || len(fromImpressions) == 0 {
+		return 0, err
+	}
+	toImpressions, err := getPlayerImpressions(ctx, db, gameID, toPlayerID, mr)
+	if err != nil {
+		return 0, err
+	}
+	toImpressionsByPlacement := map[string]*PlayerImpressions{}
+	for _, impressions := range toImpressions {
+		toImpressionsByPlacement[impressions.Placement] = impressions
+	}
+	placements, err := ListPlacements(ctx, db, gameID, mr)
+	if err != nil {
+		return 0, err
+	}
+	impressionCaps, err := getImpressionCaps(ctx, db, gameID, placements, nil, 0, mr)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, merged := range fromImpressions {
+		merged.PlayerID = toPlayerID
+		if impressions, ok := toImpressionsByPlacement[merged.Placement]; ok {
+			first, last := merged, impressions
+			if impressions.WindowStart.Time.Before(merged.WindowStart.Time) {
+				first, last = impressions, merged
+			}
+			var duration time.Duration
+			if impressionCap, ok := impressionCaps[merged.Placement]; ok {
+				duration, _ = time.ParseDuration(impressionCap.Every)
+			}
+			if first.WindowStart.Time.Add(duration).After(last.WindowStart.Time) {
+				merged.WindowStart, merged.Counter = first.WindowStart, first.Counter+last.Counter
+			} else {
+				merged.WindowStart, merged.Counter = last.WindowStart, last.Counter
+			}
+		}
+		err = mr.WithDatastoreSegment("player_impressions", SegmentUpsert, func() error {
+			builder := db.SQL(`
+				INSERT INTO player_impressions (game_id, player_id, placement, window_start, counter)
+				VALUES ($1, $2, $3, $4, $5)
+				ON CONFLICT (game_id, player_id, placement) DO UPDATE SET
+					window_start = EXCLUDED.window_start,
+					counter = EXCLUDED.counter`,
+				gameID, toPlayerID, merged.Placement, merged.WindowStart, merged.Counter,
+			)
+			builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+			_, err := builder.Exec()
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(fromImpressions)), nil
+}
@@ -0,0 +1,119 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+	"gopkg.in/mgutz/dat.v2/dat"
+)
+
+var _ = Describe("Player Merge Model", func() {
+	currentTime := time.Unix(1486678000, 0)
+	guestID := "guest-player"
+	accountID := "account-player"
+	offerID := "dd21ec96-2890-4ba0-b8e2-40ea67196990"
+	offerInstanceID := "eb7e8d2a-2739-4da3-aa31-7970b63bdad7"
+	otherOfferID := "d5114990-77d7-45c4-ba5f-462fc86b213f"
+	otherOfferInstanceID := "38cf3ed6-b999-4ee8-9e21-8f700532b37c"
+
+	Describe("Merge players", func() {
+		It("should sum the counters and keep the latest timestamps of both players", func() {
+			_, _, _, err := models.ClaimOffer(nil, db, defaultGameID, offerInstanceID, guestID, "com.tfg.sample", "transaction-1", currentTime.Unix(), currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+			_, _, _, err = models.ClaimOffer(nil, db, defaultGameID, offerInstanceID, accountID, "com.tfg.sample", "transaction-2", currentTime.Unix()+10, currentTime.Add(10*time.Second), nil)
+			Expect(err).NotTo(HaveOccurred())
+			_, _, err = models.ViewOffer(nil, db, offersCache, defaultGameID, otherOfferInstanceID, guestID, "impression-1", currentTime, cacheExpireDuration, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			merge, err := models.MergePlayers(nil, db, defaultGameID, guestID, accountID, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merge.OfferPlayers).To(Equal(int64(2)))
+			Expect(merge.DedupeKeys).To(Equal(int64(2)))
+			Expect(merge.Claims).To(Equal(int64(1)))
+
+			offerPlayer, err := models.GetOfferPlayer(nil, db, defaultGameID, accountID, offerID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offerPlayer.ClaimCounter).To(Equal(2))
+			Expect(offerPlayer.ClaimTimestamp.Time.Unix()).To(Equal(currentTime.Unix() + 10))
+
+			offerPlayer, err = models.GetOfferPlayer(nil, db, defaultGameID, accountID, otherOfferID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offerPlayer.ViewCounter).To(Equal(1))
+
+			offerPlayers, err := models.GetOffersByPlayer(nil, db, defaultGameID, guestID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offerPlayers).To(BeEmpty())
+
+			_, isReplay, _, err := models.ClaimOffer(nil, db, defaultGameID, offerInstanceID, accountID, "com.tfg.sample", "transaction-1", currentTime.Unix(), currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isReplay).To(BeTrue())
+
+			claims, err := models.ListClaims(nil, db, defaultGameID, accountID, time.Unix(0, 0), 10, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(claims).To(HaveLen(2))
+			claims, err = models.ListClaims(nil, db, defaultGameID, guestID, time.Unix(0, 0), 10, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(claims).To(BeEmpty())
+
+			events, err := models.ListAuditEvents(nil, db, defaultGameID, "", time.Unix(0, 0), 10, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Action).To(Equal(models.AuditActionMergePlayers))
+		})
+
+		It("should count the impressions of both players in the impression caps", func() {
+			err := models.UpsertPlacement(nil, db, &models.Placement{
+				GameID:        defaultGameID,
+				Name:          "store",
+				ImpressionCap: dat.JSON([]byte(`{"every": "1h", "max": 2}`)),
+			}, offersCache, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+			_, _, err = models.ViewOffer(nil, db, offersCache, defaultGameID, otherOfferInstanceID, guestID, "impression-1", currentTime, cacheExpireDuration, nil)
+			Expect(err).NotTo(HaveOccurred())
+			_, _, err = models.ViewOffer(nil, db, offersCache, defaultGameID, otherOfferInstanceID, accountID, "impression-2", currentTime.Add(time.Minute), cacheExpireDuration, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			merge, err := models.MergePlayers(nil, db, defaultGameID, guestID, accountID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merge.PlayerImpressions).To(Equal(int64(1)))
+
+			states, err := models.GetImpressionCapStates(nil, db, defaultGameID, accountID, currentTime.Add(time.Minute), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(states).To(HaveLen(1))
+			Expect(states[0].Count).To(Equal(2))
+			Expect(states[0].Reached).To(BeTrue())
+			Expect(states[0].ResetAt).To(Equal(currentTime.Add(time.Hour).Unix()))
+
+			states, err = models.GetImpressionCapStates(nil, db, defaultGameID, guestID, currentTime.Add(time.Minute), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(states[0].Count).To(BeZero())
+		})
+
+		It("should do nothing if the player has no state", func() {
+			merge, err := models.MergePlayers(nil, db, defaultGameID, guestID, accountID, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merge.OfferPlayers).To(BeZero())
+			Expect(merge.DedupeKeys).To(BeZero())
+		})
+
+		It("should return error if the players are the same", func() {
+			_, err := models.MergePlayers(nil, db, defaultGameID, guestID, guestID, nil)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.InvalidModelError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,155 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	edat "github.com/topfreegames/extensions/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//Reasons why an offer is not available to a player
+const (
+	UnavailableDisabled       = "disabled"
+	UnavailableNotStarted     = "not-started"
+	UnavailableExpired        = "expired"
+	UnavailableMaxViews       = "max-views-reached"
+	UnavailableFrequency      = "frequency"
+	UnavailableMaxClaims      = "max-claims-reached"
+	UnavailablePeriod         = "period"
+	UnavailableFilter         = "filter"
+	UnavailableSoldOut        = "sold-out"
+	UnavailablePlayerExpired  = "player-expired"
+	UnavailablePrerequisites  = "prerequisites"
+	UnavailableExclusionGroup = "exclusion-group"
+	UnavailableImpressionCap  = "impression-cap"
+	UnavailableRotation       = "rotation"
+)
+
+//PlayerOffer is the state of an offer seen or claimed by a player
+type PlayerOffer struct {
+	OfferID           string `json:"offerId"`
+	Name              string `json:"name"`
+	Placement         string `json:"placement"`
+	ViewCounter       int    `json:"viewCounter"`
+	LastViewAt        int64  `json:"lastViewAt,omitempty"`
+	ViewNextAt        int64  `json:"viewNextAt,omitempty"`
+	ClaimCounter      int    `json:"claimCounter"`
+	LastClaimAt       int64  `json:"lastClaimAt,omitempty"`
+	ClaimNextAt       int64  `json:"claimNextAt,omitempty"`
+	Available         bool   `json:"available"`
+	UnavailableReason string `json:"unavailableReason,omitempty"`
+
+	Revocations []*ClaimRevocation `json:"revocations,omitempty"`
+}
+
+//offerUnavailableReason returns why the offer is not available to the player at time t,
+//or an empty string if it is. Filters are not considered, they depend on the request.
+func offerUnavailableReason(offer *Offer, offerPlayer *OfferPlayer, t time.Time) (string, error) {
+	if !offer.Enabled {
+		return UnavailableDisabled, nil
+	}
+	var trigger Times
+	if err := json.Unmarshal(offer.Trigger, &trigger); err != nil {
+		return "", err
+	}
+	if t.Unix() < trigger.From {
+		return UnavailableNotStarted, nil
+	}
+	if t.Unix() > trigger.To {
+		return UnavailableExpired, nil
+	}
+	reason, _, err := frequencyAndPeriodReason(offer, offerPlayer, t)
+	if err != nil || reason != "" {
+		return reason, err
+	}
+	if remaining := offer.remainingStock(); remaining != nil && *remaining == 0 {
+		return UnavailableSoldOut, nil
+	}
+	return "", nil
+}
+
+//GetPlayerOffers returns the state at time t of every offer the player has seen or claimed
+func GetPlayerOffers(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, playerID string,
+	t time.Time,
+	mr *MixedMetricsReporter,
+) ([]*PlayerOffer, error) {
+	offerPlayers, err := GetOffersByPlayer(ctx, db, gameID, playerID, mr)
+	if err != nil {
+		return nil, err
+	}
+
+	offers := []*Offer{}
+	err = mr.WithDatastoreSegment("offers", SegmentSelect, func() error {
+		builder := db.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("offers").
+			Where("game_id = $1 AND id IN (SELECT offer_id FROM offer_players WHERE game_id = $1 AND player_id = $2)", gameID, playerID).
+			QueryStructs(&offers)
+	})
+	if err != nil {
+		return nil, err
+	}
+	offersByID := map[string]*Offer{}
+	for _, offer := range offers {
+		offersByID[offer.ID] = offer
+	}
+	offerPlayersByOfferID := map[string]*OfferPlayer{}
+	for _, offerPlayer := range offerPlayers {
+		offerPlayersByOfferID[offerPlayer.OfferID] = offerPlayer
+	}
+
+	playerOffers := []*PlayerOffer{}
+	for _, offerPlayer := range offerPlayers {
+		offer, ok := offersByID[offerPlayer.OfferID]
+		if !ok {
+			continue
+		}
+		playerOffer := &PlayerOffer{
+			OfferID:      offer.ID,
+			Name:         offer.Name,
+			Placement:    offer.Placement,
+			ViewCounter:  offerPlayer.ViewCounter,
+			ClaimCounter: offerPlayer.ClaimCounter,
+		}
+		if offerPlayer.ViewTimestamp.Valid {
+			playerOffer.LastViewAt = offerPlayer.ViewTimestamp.Time.Unix()
+			playerOffer.ViewNextAt, err = viewedOfferNextAt(offer, offerPlayer.ViewCounter, offerPlayer.ViewTimestamp.Time)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if offerPlayer.ClaimTimestamp.Valid {
+			playerOffer.LastClaimAt = offerPlayer.ClaimTimestamp.Time.Unix()
+			playerOffer.ClaimNextAt = claimedOfferNextAt(offer, offerPlayer.ClaimCounter, offerPlayer.ClaimTimestamp.Time)
+		}
+		playerOffer.Revocations, err = offerPlayer.getRevocations()
+		if err != nil {
+			return nil, err
+		}
+		playerOffer.UnavailableReason, err = offerUnavailableReason(offer, offerPlayer, t)
+		if err != nil {
+			return nil, err
+		}
+		if playerOffer.UnavailableReason == "" {
+			playerOffer.UnavailableReason, err = prerequisitesReason(offer, offerPlayersByOfferID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		playerOffer.Available = playerOffer.UnavailableReason == ""
+		playerOffers = append(playerOffers, playerOffer)
+	}
+	return playerOffers, nil
+}
@@ -0,0 +1,92 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	uuid "github.com/satori/go.uuid"
+	"github.com/topfreegames/offers/models"
+)
+
+var _ = Describe("Player Offer Model", func() {
+	currentTime := time.Unix(1486678000, 0)
+	offerInstanceID := "eb7e8d2a-2739-4da3-aa31-7970b63bdad7"
+	offerID := "dd21ec96-2890-4ba0-b8e2-40ea67196990"
+	playerID := "player-1"
+
+	It("should return the offers seen by the player", func() {
+		offers, err := models.GetPlayerOffers(nil, db, defaultGameID, playerID, currentTime, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offers).To(HaveLen(1))
+		Expect(offers[0].OfferID).To(Equal(offerID))
+		Expect(offers[0].Name).To(Equal("template-1"))
+		Expect(offers[0].Placement).To(Equal("popup"))
+		Expect(offers[0].LastViewAt).To(Equal(int64(1486677995)))
+		Expect(offers[0].ViewNextAt).To(Equal(int64(1486677996)))
+		Expect(offers[0].LastClaimAt).To(BeZero())
+		Expect(offers[0].Available).To(BeTrue())
+		Expect(offers[0].UnavailableReason).To(BeEmpty())
+	})
+
+	It("should return an empty list if the player has no offers", func() {
+		offers, err := models.GetPlayerOffers(nil, db, defaultGameID, "unknown-player", currentTime, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offers).To(BeEmpty())
+	})
+
+	It("should explain that the offer was seen too recently", func() {
+		_, _, err := models.ViewOffer(nil, db, offersCache, defaultGameID, offerInstanceID, playerID, uuid.NewV4().String(), currentTime, cacheExpireDuration, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		offers, err := models.GetPlayerOffers(nil, db, defaultGameID, playerID, currentTime, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offers).To(HaveLen(1))
+		Expect(offers[0].ViewCounter).To(Equal(1))
+		Expect(offers[0].ViewNextAt).To(Equal(currentTime.Unix() + 1))
+		Expect(offers[0].Available).To(BeFalse())
+		Expect(offers[0].UnavailableReason).To(Equal(models.UnavailableFrequency))
+	})
+
+	It("should explain that the offer was claimed too recently", func() {
+		_, _, _, err := models.ClaimOffer(nil, db, defaultGameID, offerInstanceID, playerID, "", uuid.NewV4().String(), currentTime.Unix(), currentTime, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		offers, err := models.GetPlayerOffers(nil, db, defaultGameID, playerID, currentTime, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offers[0].ClaimCounter).To(Equal(1))
+		Expect(offers[0].LastClaimAt).To(Equal(currentTime.Unix()))
+		Expect(offers[0].ClaimNextAt).To(Equal(currentTime.Unix() + 1))
+		Expect(offers[0].UnavailableReason).To(Equal(models.UnavailablePeriod))
+	})
+
+	It("should explain that the offer is disabled", func() {
+		err := models.SetEnabledOffer(nil, db, defaultGameID, offerID, false, offersCache, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		offers, err := models.GetPlayerOffers(nil, db, defaultGameID, playerID, currentTime, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offers[0].Available).To(BeFalse())
+		Expect(offers[0].UnavailableReason).To(Equal(models.UnavailableDisabled))
+	})
+
+	It("should explain that the offer expired", func() {
+		offers, err := models.GetPlayerOffers(nil, db, defaultGameID, playerID, time.Unix(1486679001, 0), nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offers[0].Available).To(BeFalse())
+		Expect(offers[0].UnavailableReason).To(Equal(models.UnavailableExpired))
+	})
+})
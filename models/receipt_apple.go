@@ -0,0 +1,117 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+//Apple verifyReceipt endpoints
+const (
+	AppleProductionURL = "https://buy.itunes.apple.com/verifyReceipt"
+	AppleSandboxURL    = "https://sandbox.itunes.apple.com/verifyReceipt"
+)
+
+//appleSandboxReceiptStatus is returned by the production endpoint for sandbox receipts
+const appleSandboxReceiptStatus = 21007
+
+//AppleReceiptVerifier verifies App Store receipts with Apple's verifyReceipt endpoint
+type AppleReceiptVerifier struct {
+	URL          string
+	SandboxURL   string
+	SharedSecret string
+	Client       *http.Client
+}
+
+//NewAppleReceiptVerifier ctor. Sandbox receipts are only accepted if allowSandbox is true.
+func NewAppleReceiptVerifier(sharedSecret string, allowSandbox bool, client *http.Client) *AppleReceiptVerifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	verifier := &AppleReceiptVerifier{
+		URL:          AppleProductionURL,
+		SharedSecret: sharedSecret,
+		Client:       client,
+	}
+	if allowSandbox {
+		verifier.SandboxURL = AppleSandboxURL
+	}
+	return verifier
+}
+
+type appleReceiptResponse struct {
+	Status  int `json:"status"`
+	Receipt struct {
+		InApp []struct {
+			ProductID     string `json:"product_id"`
+			TransactionID string `json:"transaction_id"`
+		} `json:"in_app"`
+	} `json:"receipt"`
+}
+
+func (a *AppleReceiptVerifier) post(ctx context.Context, url string, receipt *Receipt) (*appleReceiptResponse, error) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"receipt-data":             receipt.Data,
+		"password":                 a.SharedSecret,
+		"exclude-old-transactions": true,
+	})
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	res, err := a.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("apple verifyReceipt returned status code %d", res.StatusCode)
+	}
+
+	var appleRes appleReceiptResponse
+	err = json.NewDecoder(res.Body).Decode(&appleRes)
+	return &appleRes, err
+}
+
+//Verify receipt
+func (a *AppleReceiptVerifier) Verify(ctx context.Context, receipt *Receipt) (*ReceiptVerification, error) {
+	appleRes, err := a.post(ctx, a.URL, receipt)
+	if err != nil {
+		return nil, err
+	}
+	if appleRes.Status == appleSandboxReceiptStatus && a.SandboxURL != "" {
+		appleRes, err = a.post(ctx, a.SandboxURL, receipt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	verification := newReceiptVerification(StoreApple, receipt, "")
+	if appleRes.Status != 0 {
+		return verification.reject(fmt.Sprintf("apple returned status %d", appleRes.Status)), nil
+	}
+	for _, purchase := range appleRes.Receipt.InApp {
+		if purchase.TransactionID == receipt.TransactionID {
+			verification.ProductID = purchase.ProductID
+			if receipt.ProductID != "" && purchase.ProductID != receipt.ProductID {
+				return verification.reject("the transaction is for another product"), nil
+			}
+			return verification, nil
+		}
+	}
+	return verification.reject("the transaction is not in the receipt"), nil
+}
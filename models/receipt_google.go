@@ -0,0 +1,91 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+//googlePurchasedState is the purchaseState of completed purchases
+const googlePurchasedState = 0
+
+//GoogleReceiptVerifier verifies Google Play purchases with the license key of the app.
+//The receipt data must be a JSON object with the purchaseData and its base64 signature,
+//as returned by the Play Billing library.
+type GoogleReceiptVerifier struct {
+	PublicKey *rsa.PublicKey
+}
+
+//NewGoogleReceiptVerifier ctor, publicKey is the base64 encoded license key from the Play Console
+func NewGoogleReceiptVerifier(publicKey string) (*GoogleReceiptVerifier, error) {
+	der, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("google license key is not a RSA public key")
+	}
+	return &GoogleReceiptVerifier{PublicKey: rsaKey}, nil
+}
+
+type googleReceipt struct {
+	PurchaseData string `json:"purchaseData"`
+	Signature    string `json:"signature"`
+}
+
+type googlePurchase struct {
+	OrderID       string `json:"orderId"`
+	ProductID     string `json:"productId"`
+	PurchaseState int    `json:"purchaseState"`
+}
+
+//Verify receipt
+func (g *GoogleReceiptVerifier) Verify(ctx context.Context, receipt *Receipt) (*ReceiptVerification, error) {
+	verification := newReceiptVerification(StoreGoogle, receipt, "")
+
+	var data googleReceipt
+	if err := json.Unmarshal([]byte(receipt.Data), &data); err != nil {
+		return verification.reject("malformed receipt"), nil
+	}
+	signature, err := base64.StdEncoding.DecodeString(data.Signature)
+	if err != nil {
+		return verification.reject("malformed signature"), nil
+	}
+	hashed := sha1.Sum([]byte(data.PurchaseData))
+	if err := rsa.VerifyPKCS1v15(g.PublicKey, crypto.SHA1, hashed[:], signature); err != nil {
+		return verification.reject("invalid signature"), nil
+	}
+
+	var purchase googlePurchase
+	if err := json.Unmarshal([]byte(data.PurchaseData), &purchase); err != nil {
+		return verification.reject("malformed purchase data"), nil
+	}
+	verification.ProductID = purchase.ProductID
+	if purchase.OrderID != receipt.TransactionID {
+		return verification.reject("the receipt is for another transaction"), nil
+	}
+	if receipt.ProductID != "" && purchase.ProductID != receipt.ProductID {
+		return verification.reject("the transaction is for another product"), nil
+	}
+	if purchase.PurchaseState != googlePurchasedState {
+		return verification.reject("the purchase is not completed"), nil
+	}
+	return verification, nil
+}
@@ -0,0 +1,111 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"time"
+
+	edat "github.com/topfreegames/extensions/dat"
+	"gopkg.in/mgutz/dat.v2/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//Receipt stores
+const (
+	StoreApple  = "apple"
+	StoreGoogle = "google"
+)
+
+//FakeInvalidReceipt is the only receipt data rejected by FakeReceiptVerifier
+const FakeInvalidReceipt = "invalid"
+
+//Receipt is the proof of purchase sent by the client when claiming an offer
+type Receipt struct {
+	GameID        string
+	PlayerID      string
+	ProductID     string
+	TransactionID string
+	Store         string
+	Data          string
+}
+
+//ReceiptVerification is the result of verifying a receipt, stored with the claim
+type ReceiptVerification struct {
+	Store      string `json:"store"`
+	Verifier   string `json:"verifier"`
+	Valid      bool   `json:"valid"`
+	ProductID  string `json:"productId,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	VerifiedAt int64  `json:"verifiedAt,omitempty"`
+}
+
+func newReceiptVerification(verifier string, receipt *Receipt, productID string) *ReceiptVerification {
+	return &ReceiptVerification{
+		Store:     receipt.Store,
+		Verifier:  verifier,
+		Valid:     true,
+		ProductID: productID,
+	}
+}
+
+func (v *ReceiptVerification) reject(reason string) *ReceiptVerification {
+	v.Valid = false
+	v.Reason = reason
+	return v
+}
+
+//FakeReceiptVerifier accepts every receipt but FakeInvalidReceipt, without calling any store.
+//It is meant for local development and tests.
+type FakeReceiptVerifier struct{}
+
+//Verify receipt
+func (f *FakeReceiptVerifier) Verify(ctx context.Context, receipt *Receipt) (*ReceiptVerification, error) {
+	verification := newReceiptVerification("fake", receipt, receipt.ProductID)
+	if receipt.Data == FakeInvalidReceipt {
+		return verification.reject("fake receipt is invalid"), nil
+	}
+	return verification, nil
+}
+
+//ReceiptTransaction is a store transaction verified by a receipt and the offer it claimed.
+//A transaction claims a single offer of a game.
+type ReceiptTransaction struct {
+	GameID        string       `db:"game_id" json:"gameId"`
+	TransactionID string       `db:"transaction_id" json:"transactionId"`
+	PlayerID      string       `db:"player_id" json:"playerId"`
+	OfferID       string       `db:"offer_id" json:"offerId"`
+	CreatedAt     dat.NullTime `db:"created_at" json:"createdAt"`
+}
+
+//registerReceiptTransaction records that a verified transaction claims an offer for a player.
+//It returns false if the transaction was already recorded for another player or offer of the game.
+func registerReceiptTransaction(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, playerID, offerID, transactionID string,
+	t time.Time,
+	mr *MixedMetricsReporter,
+) (bool, error) {
+	var receiptTransaction ReceiptTransaction
+	err := mr.WithDatastoreSegment("receipt_transactions", SegmentUpsert, func() error {
+		builder := db.SQL(`
+			INSERT INTO receipt_transactions (game_id, transaction_id, player_id, offer_id, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (game_id, transaction_id) DO UPDATE SET game_id = receipt_transactions.game_id
+			RETURNING *`,
+			gameID, transactionID, playerID, offerID, t,
+		)
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.QueryStruct(&receiptTransaction)
+	})
+	if err != nil {
+		return false, err
+	}
+	return receiptTransaction.PlayerID == playerID && receiptTransaction.OfferID == offerID, nil
+}
@@ -0,0 +1,248 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	uuid "github.com/satori/go.uuid"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+)
+
+var _ = Describe("Receipt Verifiers", func() {
+	receipt := func(store, data string) *models.Receipt {
+		return &models.Receipt{
+			GameID:        defaultGameID,
+			PlayerID:      "player-1",
+			ProductID:     "com.tfg.sample",
+			TransactionID: "transaction-1",
+			Store:         store,
+			Data:          data,
+		}
+	}
+
+	Describe("Fake receipt verifier", func() {
+		It("should only reject the invalid receipt", func() {
+			verifier := &models.FakeReceiptVerifier{}
+
+			verification, err := verifier.Verify(nil, receipt("apple", "anything"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verification.Valid).To(BeTrue())
+
+			verification, err = verifier.Verify(nil, receipt("apple", models.FakeInvalidReceipt))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verification.Valid).To(BeFalse())
+		})
+	})
+
+	Describe("Apple receipt verifier", func() {
+		var server *httptest.Server
+		var responses []string
+
+		BeforeEach(func() {
+			responses = []string{}
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body map[string]interface{}
+				json.NewDecoder(r.Body).Decode(&body)
+				Expect(body["password"]).To(Equal("shared-secret"))
+				w.Write([]byte(responses[0]))
+				responses = responses[1:]
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		newVerifier := func() *models.AppleReceiptVerifier {
+			verifier := models.NewAppleReceiptVerifier("shared-secret", true, nil)
+			verifier.URL = server.URL
+			verifier.SandboxURL = server.URL
+			return verifier
+		}
+
+		It("should accept receipts with the transaction", func() {
+			responses = append(responses, `{"status": 0, "receipt": {"in_app": [{"product_id": "com.tfg.sample", "transaction_id": "transaction-1"}]}}`)
+
+			verification, err := newVerifier().Verify(nil, receipt("apple", "receipt-data"))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verification.Valid).To(BeTrue())
+			Expect(verification.ProductID).To(Equal("com.tfg.sample"))
+		})
+
+		It("should retry sandbox receipts in the sandbox", func() {
+			responses = append(responses,
+				`{"status": 21007}`,
+				`{"status": 0, "receipt": {"in_app": [{"product_id": "com.tfg.sample", "transaction_id": "transaction-1"}]}}`,
+			)
+
+			verification, err := newVerifier().Verify(nil, receipt("apple", "receipt-data"))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verification.Valid).To(BeTrue())
+			Expect(responses).To(BeEmpty())
+		})
+
+		It("should reject receipts without the transaction", func() {
+			responses = append(responses, `{"status": 0, "receipt": {"in_app": [{"product_id": "com.tfg.sample", "transaction_id": "transaction-2"}]}}`)
+
+			verification, err := newVerifier().Verify(nil, receipt("apple", "receipt-data"))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verification.Valid).To(BeFalse())
+		})
+
+		It("should reject receipts apple does not accept", func() {
+			responses = append(responses, `{"status": 21003}`)
+
+			verification, err := newVerifier().Verify(nil, receipt("apple", "receipt-data"))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verification.Valid).To(BeFalse())
+			Expect(verification.Reason).To(Equal("apple returned status 21003"))
+		})
+	})
+
+	Describe("Google receipt verifier", func() {
+		var privateKey *rsa.PrivateKey
+		var verifier *models.GoogleReceiptVerifier
+
+		googleReceipt := func(purchaseData string, key *rsa.PrivateKey) string {
+			hashed := sha1.Sum([]byte(purchaseData))
+			signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA1, hashed[:])
+			Expect(err).NotTo(HaveOccurred())
+			data, _ := json.Marshal(map[string]string{
+				"purchaseData": purchaseData,
+				"signature":    base64.StdEncoding.EncodeToString(signature),
+			})
+			return string(data)
+		}
+
+		BeforeEach(func() {
+			var err error
+			privateKey, err = rsa.GenerateKey(rand.Reader, 1024)
+			Expect(err).NotTo(HaveOccurred())
+			der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+			Expect(err).NotTo(HaveOccurred())
+			verifier, err = models.NewGoogleReceiptVerifier(base64.StdEncoding.EncodeToString(der))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should accept purchases signed with the license key", func() {
+			data := googleReceipt(`{"orderId": "transaction-1", "productId": "com.tfg.sample", "purchaseState": 0}`, privateKey)
+
+			verification, err := verifier.Verify(nil, receipt("google", data))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verification.Valid).To(BeTrue())
+		})
+
+		It("should reject purchases signed with another key", func() {
+			otherKey, err := rsa.GenerateKey(rand.Reader, 1024)
+			Expect(err).NotTo(HaveOccurred())
+			data := googleReceipt(`{"orderId": "transaction-1", "productId": "com.tfg.sample", "purchaseState": 0}`, otherKey)
+
+			verification, err := verifier.Verify(nil, receipt("google", data))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verification.Valid).To(BeFalse())
+			Expect(verification.Reason).To(Equal("invalid signature"))
+		})
+
+		It("should reject purchases of another product", func() {
+			data := googleReceipt(`{"orderId": "transaction-1", "productId": "com.tfg.other", "purchaseState": 0}`, privateKey)
+
+			verification, err := verifier.Verify(nil, receipt("google", data))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verification.Valid).To(BeFalse())
+		})
+	})
+
+	Describe("Claim offer with receipt", func() {
+		It("should store the verification with the claim", func() {
+			offerInstanceID := "eb7e8d2a-2739-4da3-aa31-7970b63bdad7"
+			playerID := "player-1"
+			transactionID := uuid.NewV4().String()
+			currentTime := time.Unix(1486678000, 0)
+			verification := &models.ReceiptVerification{
+				Store:      "apple",
+				Verifier:   "fake",
+				Valid:      true,
+				ProductID:  "com.tfg.sample",
+				VerifiedAt: currentTime.Unix(),
+			}
+
+			_, alreadyClaimed, _, err := models.ClaimOfferWithReceipt(
+				nil, db, defaultGameID, offerInstanceID, playerID, "", transactionID,
+				currentTime.Unix(), verification, currentTime, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(alreadyClaimed).To(BeFalse())
+
+			offerPlayers, err := models.GetOffersByPlayer(nil, db, defaultGameID, playerID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			var receipts map[string]*models.ReceiptVerification
+			for _, offerPlayer := range offerPlayers {
+				Expect(offerPlayer.Receipts.Unmarshal(&receipts)).To(Succeed())
+				if _, ok := receipts[transactionID]; ok {
+					break
+				}
+			}
+			Expect(receipts).To(HaveKey(transactionID))
+			Expect(receipts[transactionID].Store).To(Equal("apple"))
+			Expect(receipts[transactionID].Valid).To(BeTrue())
+		})
+
+		It("should return error if the receipt is for another product", func() {
+			currentTime := time.Unix(1486678000, 0)
+			verification := &models.ReceiptVerification{Store: "apple", Verifier: "fake", Valid: true, ProductID: "com.tfg.cheap"}
+
+			_, _, _, err := models.ClaimOfferWithReceipt(
+				nil, db, defaultGameID, "eb7e8d2a-2739-4da3-aa31-7970b63bdad7", "player-1", "", uuid.NewV4().String(),
+				currentTime.Unix(), verification, currentTime, nil,
+			)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.ReceiptRejectedError)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should return error if the transaction already claimed another offer", func() {
+			currentTime := time.Unix(1486678000, 0)
+			transactionID := uuid.NewV4().String()
+			_, _, _, err := models.ClaimOfferWithReceipt(
+				nil, db, defaultGameID, "eb7e8d2a-2739-4da3-aa31-7970b63bdad7", "player-1", "", transactionID, currentTime.Unix(),
+				&models.ReceiptVerification{Store: "apple", Verifier: "fake", Valid: true, ProductID: "com.tfg.sample"}, currentTime, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, _, err = models.ClaimOfferWithReceipt(
+				nil, db, defaultGameID, "38cf3ed6-b999-4ee8-9e21-8f700532b37c", "player-1", "", transactionID, currentTime.Unix(),
+				&models.ReceiptVerification{Store: "apple", Verifier: "fake", Valid: true, ProductID: "com.tfg.sample.2"}, currentTime, nil,
+			)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.ReceiptRejectedError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+})
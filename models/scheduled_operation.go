@@ -0,0 +1,303 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pmylund/go-cache"
+	edat "github.com/topfreegames/extensions/dat"
+	"github.com/topfreegames/offers/errors"
+	"gopkg.in/mgutz/dat.v2/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//Scheduled operation types
+const (
+	OperationEnable  = "enable"
+	OperationDisable = "disable"
+	OperationPatch   = "patch"
+)
+
+//Scheduled operation statuses
+const (
+	OperationStatusPending = "pending"
+	OperationStatusDone    = "done"
+	OperationStatusFailed  = "failed"
+)
+
+//scheduledOperationsLockSpace namespaces the advisory locks taken by the scheduler
+const scheduledOperationsLockSpace = 7001
+
+//scheduledOperationsActor is the actor recorded in the audit events of scheduled operations
+const scheduledOperationsActor = "scheduler"
+
+//ScheduledOperation is a change to an offer template that is applied at ExecuteAt (unix seconds)
+type ScheduledOperation struct {
+	ID         string       `db:"id" json:"id" valid:"uuidv4,optional"`
+	GameID     string       `db:"game_id" json:"gameId" valid:"matches(^[^-][a-zA-Z0-9-_]*$),stringlength(1|255),required"`
+	OfferID    string       `db:"offer_id" json:"offerId" valid:"uuidv4,optional"`
+	Operation  string       `db:"operation" json:"operation" valid:"matches(^(enable|disable|patch)$),required"`
+	Patch      dat.JSON     `db:"patch" json:"patch,omitempty" valid:"JSONObject"`
+	ExecuteAt  int64        `db:"execute_at" json:"executeAt" valid:"int64,required"`
+	Status     string       `db:"status" json:"status" valid:"optional"`
+	Error      string       `db:"error" json:"error,omitempty" valid:"optional"`
+	CreatedBy  string       `db:"created_by" json:"createdBy" valid:"optional"`
+	ExecutedAt dat.NullTime `db:"executed_at" json:"executedAt" valid:"optional"`
+	CreatedAt  dat.NullTime `db:"created_at" json:"createdAt" valid:"optional"`
+}
+
+//InsertScheduledOperation schedules an operation for an existing offer.
+//Patches are applied to the offer right away to check the trigger, placement and prerequisites they result in.
+func InsertScheduledOperation(ctx context.Context, db runner.Connection, op *ScheduledOperation, mr *MixedMetricsReporter) error {
+	offer, err := getFullOffer(ctx, db, op.GameID, op.OfferID, mr)
+	if err != nil {
+		return err
+	}
+
+	if op.Operation == OperationPatch {
+		var patch map[string]interface{}
+		if op.Patch == nil || op.Patch.Unmarshal(&patch) != nil || len(patch) == 0 {
+			return errors.NewInvalidModelError("ScheduledOperation", "patch operations require a non empty patch")
+		}
+		err = op.applyPatch(offer)
+		if err != nil {
+			return err
+		}
+		err = validateTrigger(offer)
+		if err != nil {
+			return err
+		}
+		err = validateOfferPlacement(ctx, db, offer, mr)
+		if err != nil {
+			return err
+		}
+		err = validatePrerequisites(ctx, db, offer, mr)
+		if err != nil {
+			return err
+		}
+	} else {
+		op.Patch = dat.JSON([]byte(`{}`))
+	}
+
+	return mr.WithDatastoreSegment("scheduled_operations", SegmentInsert, func() error {
+		builder := db.InsertInto("scheduled_operations")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.Columns("game_id", "offer_id", "operation", "patch", "execute_at", "created_by").
+			Record(op).
+			Returning("id", "status", "error", "created_at").
+			QueryStruct(op)
+	})
+}
+
+//ListScheduledOperations returns the operations scheduled for an offer, in execution order
+func ListScheduledOperations(ctx context.Context, db runner.Connection, gameID, offerID string, mr *MixedMetricsReporter) ([]*ScheduledOperation, error) {
+	ops := []*ScheduledOperation{}
+	err := mr.WithDatastoreSegment("scheduled_operations", SegmentSelect, func() error {
+		builder := db.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("scheduled_operations").
+			Where("game_id = $1 AND offer_id = $2", gameID, offerID).
+			OrderBy("execute_at, created_at").
+			QueryStructs(&ops)
+	})
+	return ops, err
+}
+
+//RunScheduledOperations executes the pending operations due at time t and returns how many were run.
+//Each operation runs while a transaction holds an advisory lock on the operation,
+//so concurrent schedulers never execute the same operation twice.
+//Operations that fail to apply are marked as failed with the error, before the lock is released,
+//instead of aborting the run.
+func RunScheduledOperations(ctx context.Context, db runner.Connection, offersCache *cache.Cache, t time.Time, limit uint64, mr *MixedMetricsReporter) (int, error) {
+	ops := []*ScheduledOperation{}
+	err := mr.WithDatastoreSegment("scheduled_operations", SegmentSelect, func() error {
+		builder := db.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("scheduled_operations").
+			Where("status = $1 AND execute_at <= $2", OperationStatusPending, t.Unix()).
+			OrderBy("execute_at, created_at").
+			Limit(limit).
+			QueryStructs(&ops)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	executed := 0
+	for _, op := range ops {
+		ran, err := runScheduledOperation(ctx, db, op, offersCache, t, mr)
+		if err != nil {
+			return executed, err
+		}
+		if ran {
+			executed++
+		}
+	}
+	return executed, nil
+}
+
+func runScheduledOperation(
+	ctx context.Context,
+	db runner.Connection,
+	op *ScheduledOperation,
+	offersCache *cache.Cache,
+	t time.Time,
+	mr *MixedMetricsReporter,
+) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.AutoRollback()
+
+	var locked bool
+	err = mr.WithDatastoreSegment("scheduled_operations", SegmentSelect, func() error {
+		return tx.SQL("SELECT pg_try_advisory_xact_lock($1, hashtext($2))", scheduledOperationsLockSpace, op.ID).
+			QueryScalar(&locked)
+	})
+	if err != nil || !locked {
+		return false, err
+	}
+
+	var status string
+	err = mr.WithDatastoreSegment("scheduled_operations", SegmentSelect, func() error {
+		builder := tx.Select("status")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("scheduled_operations").
+			Where("id = $1", op.ID).
+			QueryScalar(&status)
+	})
+	if err != nil || status != OperationStatusPending {
+		return false, err
+	}
+
+	opCtx := NewContextWithAuditInfo(ctx, scheduledOperationsActor, op.ID)
+	applyErr := applyScheduledOperationInTx(opCtx, db, op, offersCache, t, mr)
+	if applyErr != nil {
+		err = setScheduledOperationStatus(ctx, tx, op.ID, OperationStatusFailed, applyErr.Error(), t, mr)
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit()
+}
+
+//applyScheduledOperationInTx applies the operation and marks it as done in a transaction of its own,
+//so a failure rolls back only the operation while the caller keeps holding the lock to mark it as failed
+func applyScheduledOperationInTx(
+	ctx context.Context,
+	db runner.Connection,
+	op *ScheduledOperation,
+	offersCache *cache.Cache,
+	t time.Time,
+	mr *MixedMetricsReporter,
+) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.AutoRollback()
+
+	err = applyScheduledOperation(ctx, tx, op, offersCache, t, mr)
+	if err != nil {
+		return err
+	}
+	err = setScheduledOperationStatus(ctx, tx, op.ID, OperationStatusDone, "", t, mr)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func applyScheduledOperation(
+	ctx context.Context,
+	db runner.Connection,
+	op *ScheduledOperation,
+	offersCache *cache.Cache,
+	t time.Time,
+	mr *MixedMetricsReporter,
+) error {
+	switch op.Operation {
+	case OperationEnable:
+		return SetEnabledOffer(ctx, db, op.GameID, op.OfferID, true, offersCache, mr)
+	case OperationDisable:
+		return SetEnabledOffer(ctx, db, op.GameID, op.OfferID, false, offersCache, mr)
+	case OperationPatch:
+		game, err := GetGameByID(ctx, db, op.GameID, mr)
+		if err != nil {
+			return err
+		}
+		requireApproval, err := game.RequirePublishApproval()
+		if err != nil {
+			return err
+		}
+		if requireApproval {
+			return applyScheduledPatchAsDraft(ctx, db, op, t, mr)
+		}
+		offer, err := getFullOffer(ctx, db, op.GameID, op.OfferID, mr)
+		if err != nil {
+			return err
+		}
+		err = op.applyPatch(offer)
+		if err != nil {
+			return err
+		}
+		_, err = UpdateOffer(ctx, db, offer, offersCache, mr)
+		return err
+	}
+	return errors.NewInvalidModelError("ScheduledOperation", "unknown operation "+op.Operation)
+}
+
+//applyScheduledPatchAsDraft saves the patch into the draft of the offer, on top of the draft if there
+//is one already, with whoever scheduled the operation as author, so the change still needs to be
+//published by someone else
+func applyScheduledPatchAsDraft(ctx context.Context, db runner.Connection, op *ScheduledOperation, t time.Time, mr *MixedMetricsReporter) error {
+	var offer *Offer
+	draft, err := GetOfferDraft(ctx, db, op.GameID, op.OfferID, mr)
+	if err == nil {
+		offer = offerFromDraft(draft)
+	} else if _, ok := err.(*errors.ModelNotFoundError); ok {
+		offer, err = getFullOffer(ctx, db, op.GameID, op.OfferID, mr)
+	}
+	if err != nil {
+		return err
+	}
+	err = op.applyPatch(offer)
+	if err != nil {
+		return err
+	}
+	return UpsertOfferDraft(ctx, db, OfferDraftFromOffer(offer, op.CreatedBy), t, mr)
+}
+
+//applyPatch writes the fields of the patch of the operation into offer
+func (op *ScheduledOperation) applyPatch(offer *Offer) error {
+	err := json.Unmarshal(op.Patch, offer)
+	if err != nil {
+		return errors.NewInvalidModelError("ScheduledOperation", fmt.Sprintf("invalid patch: %s", err.Error()))
+	}
+	offer.ID = op.OfferID
+	offer.GameID = op.GameID
+	return nil
+}
+
+func setScheduledOperationStatus(ctx context.Context, db runner.Connection, id, status, errMsg string, t time.Time, mr *MixedMetricsReporter) error {
+	return mr.WithDatastoreSegment("scheduled_operations", SegmentUpdate, func() error {
+		builder := db.Update("scheduled_operations")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		_, err := builder.Set("status", status).
+			Set("error", errMsg).
+			Set("executed_at", t).
+			Where("id = $1 AND status = $2", id, OperationStatusPending).
+			Exec()
+		return err
+	})
+}
@@ -0,0 +1,213 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	uuid "github.com/satori/go.uuid"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+	"gopkg.in/mgutz/dat.v2/dat"
+)
+
+var _ = Describe("Scheduled Operation Model", func() {
+	currentTime := time.Unix(1486678000, 0)
+	offerID := "dd21ec96-2890-4ba0-b8e2-40ea67196990"
+
+	isEnabled := func() bool {
+		var enabled bool
+		err := db.Select("enabled").
+			From("offers").
+			Where("id = $1", offerID).
+			QueryScalar(&enabled)
+		Expect(err).NotTo(HaveOccurred())
+		return enabled
+	}
+
+	Describe("Insert scheduled operation", func() {
+		It("should schedule an operation as pending", func() {
+			op := &models.ScheduledOperation{
+				GameID:    defaultGameID,
+				OfferID:   offerID,
+				Operation: models.OperationDisable,
+				ExecuteAt: currentTime.Unix(),
+			}
+
+			err := models.InsertScheduledOperation(nil, db, op, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(op.ID).NotTo(BeEmpty())
+			Expect(op.Status).To(Equal(models.OperationStatusPending))
+		})
+
+		It("should return error if offer does not exist", func() {
+			op := &models.ScheduledOperation{
+				GameID:    defaultGameID,
+				OfferID:   uuid.NewV4().String(),
+				Operation: models.OperationDisable,
+				ExecuteAt: currentTime.Unix(),
+			}
+			expectedError := errors.NewModelNotFoundError("Offer", map[string]interface{}{
+				"GameID": defaultGameID,
+				"ID":     op.OfferID,
+			})
+
+			err := models.InsertScheduledOperation(nil, db, op, nil)
+
+			Expect(err).To(MatchError(expectedError))
+		})
+
+		It("should return error if a patch operation has no patch", func() {
+			op := &models.ScheduledOperation{
+				GameID:    defaultGameID,
+				OfferID:   offerID,
+				Operation: models.OperationPatch,
+				ExecuteAt: currentTime.Unix(),
+			}
+			expectedError := errors.NewInvalidModelError("ScheduledOperation", "patch operations require a non empty patch")
+
+			err := models.InsertScheduledOperation(nil, db, op, nil)
+
+			Expect(err).To(MatchError(expectedError))
+		})
+
+		It("should return error if a patch results in an invalid offer", func() {
+			op := &models.ScheduledOperation{
+				GameID:    defaultGameID,
+				OfferID:   offerID,
+				Operation: models.OperationPatch,
+				Patch:     dat.JSON([]byte(`{"trigger": {"from": 1486678000, "to": 1486679000, "duration": "-1h"}}`)),
+				ExecuteAt: currentTime.Unix(),
+			}
+
+			err := models.InsertScheduledOperation(nil, db, op, nil)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.InvalidModelError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Describe("Run scheduled operations", func() {
+		It("should only run operations that are due", func() {
+			due := &models.ScheduledOperation{
+				GameID:    defaultGameID,
+				OfferID:   offerID,
+				Operation: models.OperationDisable,
+				ExecuteAt: currentTime.Unix() - 1,
+			}
+			err := models.InsertScheduledOperation(nil, db, due, nil)
+			Expect(err).NotTo(HaveOccurred())
+			future := &models.ScheduledOperation{
+				GameID:    defaultGameID,
+				OfferID:   offerID,
+				Operation: models.OperationEnable,
+				ExecuteAt: currentTime.Unix() + 3600,
+			}
+			err = models.InsertScheduledOperation(nil, db, future, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			executed, err := models.RunScheduledOperations(nil, db, offersCache, currentTime, 100, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(executed).To(Equal(1))
+			Expect(isEnabled()).To(BeFalse())
+
+			ops, err := models.ListScheduledOperations(nil, db, defaultGameID, offerID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ops).To(HaveLen(2))
+			Expect(ops[0].ID).To(Equal(due.ID))
+			Expect(ops[0].Status).To(Equal(models.OperationStatusDone))
+			Expect(ops[0].ExecutedAt.Valid).To(BeTrue())
+			Expect(ops[1].ID).To(Equal(future.ID))
+			Expect(ops[1].Status).To(Equal(models.OperationStatusPending))
+		})
+
+		It("should not run an operation twice", func() {
+			op := &models.ScheduledOperation{
+				GameID:    defaultGameID,
+				OfferID:   offerID,
+				Operation: models.OperationDisable,
+				ExecuteAt: currentTime.Unix(),
+			}
+			err := models.InsertScheduledOperation(nil, db, op, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			executed, err := models.RunScheduledOperations(nil, db, offersCache, currentTime, 100, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(executed).To(Equal(1))
+
+			executed, err = models.RunScheduledOperations(nil, db, offersCache, currentTime.Add(time.Hour), 100, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(executed).To(Equal(0))
+		})
+
+		It("should apply a content patch as a new offer version", func() {
+			op := &models.ScheduledOperation{
+				GameID:    defaultGameID,
+				OfferID:   offerID,
+				Operation: models.OperationPatch,
+				Patch:     dat.JSON([]byte(`{"contents": {"gems": 1000}}`)),
+				ExecuteAt: currentTime.Unix(),
+			}
+			err := models.InsertScheduledOperation(nil, db, op, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			executed, err := models.RunScheduledOperations(nil, db, offersCache, currentTime, 100, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(executed).To(Equal(1))
+			var offer models.Offer
+			err = db.Select("contents, version, enabled").
+				From("offers").
+				Where("id = $1", offerID).
+				QueryStruct(&offer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offer.Contents).To(MatchJSON(`{"gems": 1000}`))
+			Expect(offer.Version).To(Equal(2))
+			Expect(offer.Enabled).To(BeTrue())
+		})
+
+		It("should save a content patch as the offer draft if the game requires publish approval", func() {
+			_, err := db.Update("games").
+				Set("metadata", dat.JSON([]byte(`{"requirePublishApproval": true}`))).
+				Where("id = $1", defaultGameID).
+				Exec()
+			Expect(err).NotTo(HaveOccurred())
+			op := &models.ScheduledOperation{
+				GameID:    defaultGameID,
+				OfferID:   offerID,
+				Operation: models.OperationPatch,
+				Patch:     dat.JSON([]byte(`{"contents": {"gems": 1000}}`)),
+				ExecuteAt: currentTime.Unix(),
+				CreatedBy: "author@tfgco.com",
+			}
+			err = models.InsertScheduledOperation(nil, db, op, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			executed, err := models.RunScheduledOperations(nil, db, offersCache, currentTime, 100, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(executed).To(Equal(1))
+			var version int
+			err = db.Select("version").
+				From("offers").
+				Where("id = $1", offerID).
+				QueryScalar(&version)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal(1))
+			draft, err := models.GetOfferDraft(nil, db, defaultGameID, offerID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(draft.Contents).To(MatchJSON(`{"gems": 1000}`))
+			Expect(draft.Author).To(Equal("author@tfgco.com"))
+		})
+	})
+})
@@ -0,0 +1,345 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	edat "github.com/topfreegames/extensions/dat"
+	"github.com/topfreegames/offers/errors"
+	"gopkg.in/mgutz/dat.v2/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//GameSnapshot is a portable copy of a game, its offer templates, their current versions and its placements
+type GameSnapshot struct {
+	Game          *Game           `json:"game"`
+	Offers        []*Offer        `json:"offers"`
+	OfferVersions []*OfferVersion `json:"offerVersions"`
+	Placements    []*Placement    `json:"placements"`
+}
+
+//SnapshotImportResult maps the IDs found in a snapshot to the IDs they were stored with
+type SnapshotImportResult struct {
+	GameID        string            `json:"gameId"`
+	Offers        map[string]string `json:"offers"`
+	OfferVersions map[string]string `json:"offerVersions"`
+}
+
+//ExportGame builds a snapshot of the game with the given id
+func ExportGame(ctx context.Context, db runner.Connection, gameID string, mr *MixedMetricsReporter) (*GameSnapshot, error) {
+	game, err := GetGameByID(ctx, db, gameID, mr)
+	if err != nil {
+		return nil, err
+	}
+
+	offers := []*Offer{}
+	err = mr.WithDatastoreSegment("offers", SegmentSelect, func() error {
+		builder := db.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("offers").
+			Where("game_id = $1", gameID).
+			OrderBy("created_at, id").
+			QueryStructs(&offers)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	offerVersions := []*OfferVersion{}
+	err = mr.WithDatastoreSegment("offer_versions", SegmentSelect, func() error {
+		builder := db.Select("ov.*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("offer_versions ov JOIN offers o ON (ov.offer_id=o.id AND ov.offer_version=o.version)").
+			Where("ov.game_id = $1", gameID).
+			OrderBy("ov.created_at, ov.id").
+			QueryStructs(&offerVersions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	placements, err := ListPlacements(ctx, db, gameID, mr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GameSnapshot{
+		Game:          game,
+		Offers:        offers,
+		OfferVersions: offerVersions,
+		Placements:    placements,
+	}, nil
+}
+
+//ImportGame writes a snapshot into the game with id targetGameID in a single transaction.
+//Offer and offer version IDs are kept unless they are already used by another game,
+//in which case new IDs are generated. Offers and placements that already belong to the target game are overwritten.
+//The prerequisites of the offers and the fallback offers of the placements are remapped to the stored offer IDs.
+func ImportGame(
+	ctx context.Context,
+	db runner.Connection,
+	snapshot *GameSnapshot,
+	targetGameID string,
+	t time.Time,
+	mr *MixedMetricsReporter,
+) (*SnapshotImportResult, error) {
+	result := &SnapshotImportResult{
+		GameID:        targetGameID,
+		Offers:        map[string]string{},
+		OfferVersions: map[string]string{},
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.AutoRollback()
+
+	game := *snapshot.Game
+	game.ID = targetGameID
+	err = UpsertGame(ctx, tx, &game, t, mr)
+	if err != nil {
+		return nil, err
+	}
+
+	offers := make([]*Offer, 0, len(snapshot.Offers))
+	for _, snapshotOffer := range snapshot.Offers {
+		offer := *snapshotOffer
+		offer.GameID = targetGameID
+		err = importOffer(ctx, tx, &offer, mr)
+		if err != nil {
+			return nil, err
+		}
+		result.Offers[snapshotOffer.ID] = offer.ID
+		offers = append(offers, &offer)
+	}
+
+	for _, offer := range offers {
+		err = remapPrerequisites(ctx, tx, offer, result.Offers, mr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, offer := range offers {
+		err = validatePrerequisites(ctx, tx, offer, mr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, snapshotOfferVersion := range snapshot.OfferVersions {
+		offerID, ok := result.Offers[snapshotOfferVersion.OfferID]
+		if !ok {
+			continue
+		}
+		offerVersion := *snapshotOfferVersion
+		offerVersion.GameID = targetGameID
+		offerVersion.OfferID = offerID
+		err = importOfferVersion(ctx, tx, &offerVersion, mr)
+		if err != nil {
+			return nil, err
+		}
+		result.OfferVersions[snapshotOfferVersion.ID] = offerVersion.ID
+	}
+
+	for _, snapshotPlacement := range snapshot.Placements {
+		placement := *snapshotPlacement
+		placement.GameID = targetGameID
+		if placement.FallbackOfferID.Valid {
+			placement.FallbackOfferID = dat.NullString{}
+			if offerID, ok := result.Offers[snapshotPlacement.FallbackOfferID.String]; ok {
+				placement.FallbackOfferID = dat.NullStringFrom(offerID)
+			}
+		}
+		err = importPlacement(ctx, tx, &placement, t, mr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func getOfferOwner(ctx context.Context, db runner.Connection, id string, mr *MixedMetricsReporter) (string, error) {
+	var gameIDs []string
+	err := mr.WithDatastoreSegment("offers", SegmentSelect, func() error {
+		builder := db.Select("game_id")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("offers").
+			Where("id = $1", id).
+			QuerySlice(&gameIDs)
+	})
+	if err != nil || len(gameIDs) == 0 {
+		return "", err
+	}
+	return gameIDs[0], nil
+}
+
+func importOffer(ctx context.Context, db runner.Connection, offer *Offer, mr *MixedMetricsReporter) error {
+	if offer.Prerequisites == nil {
+		offer.Prerequisites = dat.JSON([]byte(`{}`))
+	}
+	err := validateTrigger(offer)
+	if err != nil {
+		return err
+	}
+	owner, err := getOfferOwner(ctx, db, offer.ID, mr)
+	if err != nil {
+		return err
+	}
+
+	if owner == offer.GameID {
+		return mr.WithDatastoreSegment("offers", SegmentUpdate, func() error {
+			builder := db.Update("offers")
+			builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+			return builder.SetMap(map[string]interface{}{
+				"name":            offer.Name,
+				"period":          offer.Period,
+				"frequency":       offer.Frequency,
+				"trigger":         offer.Trigger,
+				"placement":       offer.Placement,
+				"priority":        offer.Priority,
+				"exclusion_group": offer.ExclusionGroup,
+				"metadata":        offer.Metadata,
+				"product_id":      offer.ProductID,
+				"contents":        offer.Contents,
+				"filters":         offer.Filters,
+				"cost":            offer.Cost,
+				"stock":           offer.Stock,
+				"prerequisites":   offer.Prerequisites,
+				"enabled":         offer.Enabled,
+				"version":         offer.Version,
+			}).
+				Where("id = $1 AND game_id = $2", offer.ID, offer.GameID).
+				Returning("id").
+				QueryStruct(offer)
+		})
+	}
+
+	columns := []string{
+		"game_id", "name", "period", "frequency", "trigger", "placement", "priority", "exclusion_group",
+		"metadata", "product_id", "contents", "filters", "cost", "stock", "prerequisites", "enabled", "version",
+		"created_at",
+	}
+	if owner == "" {
+		columns = append(columns, "id")
+	}
+	return mr.WithDatastoreSegment("offers", SegmentInsert, func() error {
+		builder := db.InsertInto("offers")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.Columns(columns...).
+			Record(offer).
+			Returning("id").
+			QueryStruct(offer)
+	})
+}
+
+//remapPrerequisites rewrites the prerequisites of an imported offer to refer to the stored offer IDs
+func remapPrerequisites(ctx context.Context, db runner.Connection, offer *Offer, offerIDs map[string]string, mr *MixedMetricsReporter) error {
+	prerequisites, err := getPrerequisites(offer)
+	if err != nil {
+		return errors.NewInvalidModelError("Offer", fmt.Sprintf("invalid prerequisites: %s", err.Error()))
+	}
+	if len(prerequisites.requiredOfferIDs()) == 0 && len(prerequisites.NotClaimed) == 0 {
+		return nil
+	}
+	remapped, err := json.Marshal(prerequisites.remap(offerIDs))
+	if err != nil {
+		return err
+	}
+	offer.Prerequisites = dat.JSON(remapped)
+
+	return mr.WithDatastoreSegment("offers", SegmentUpdate, func() error {
+		builder := db.Update("offers")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		_, err := builder.Set("prerequisites", offer.Prerequisites).
+			Where("id = $1 AND game_id = $2", offer.ID, offer.GameID).
+			Exec()
+		return err
+	})
+}
+
+func importOfferVersion(ctx context.Context, db runner.Connection, offerVersion *OfferVersion, mr *MixedMetricsReporter) error {
+	var existing []string
+	err := mr.WithDatastoreSegment("offer_versions", SegmentSelect, func() error {
+		builder := db.Select("id")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("offer_versions").
+			Where("game_id = $1 AND offer_id = $2 AND offer_version = $3",
+				offerVersion.GameID, offerVersion.OfferID, offerVersion.OfferVersion).
+			QuerySlice(&existing)
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(existing) > 0 {
+		offerVersion.ID = existing[0]
+		return mr.WithDatastoreSegment("offer_versions", SegmentUpdate, func() error {
+			builder := db.Update("offer_versions")
+			builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+			return builder.Set("contents", offerVersion.Contents).
+				Set("product_id", offerVersion.ProductID).
+				Set("cost", offerVersion.Cost).
+				Where("id = $1", offerVersion.ID).
+				Returning("id").
+				QueryStruct(offerVersion)
+		})
+	}
+
+	var taken []string
+	err = mr.WithDatastoreSegment("offer_versions", SegmentSelect, func() error {
+		builder := db.Select("id")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("offer_versions").
+			Where("id = $1", offerVersion.ID).
+			QuerySlice(&taken)
+	})
+	if err != nil {
+		return err
+	}
+
+	columns := []string{"game_id", "offer_id", "offer_version", "contents", "product_id", "cost"}
+	if len(taken) == 0 {
+		columns = append(columns, "id")
+	}
+	return mr.WithDatastoreSegment("offer_versions", SegmentInsert, func() error {
+		builder := db.InsertInto("offer_versions")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.Columns(columns...).
+			Record(offerVersion).
+			Returning("id").
+			QueryStruct(offerVersion)
+	})
+}
+
+func importPlacement(ctx context.Context, db runner.Connection, placement *Placement, t time.Time, mr *MixedMetricsReporter) error {
+	err := placement.validate()
+	if err != nil {
+		return err
+	}
+	placement.UpdatedAt = dat.NullTimeFrom(t)
+
+	return mr.WithDatastoreSegment("placements", SegmentUpsert, func() error {
+		builder := db.Upsert("placements")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.Columns("game_id", "name", "ordering", "max_offers", "fallback_offer_id", "impression_cap", "rotation", "updated_at").
+			Record(placement).
+			Where("game_id = $1 AND name = $2", placement.GameID, placement.Name).
+			Returning("created_at", "updated_at").
+			QueryStruct(placement)
+	})
+}
@@ -0,0 +1,176 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	uuid "github.com/satori/go.uuid"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+	"gopkg.in/mgutz/dat.v2/dat"
+)
+
+var _ = Describe("Snapshot Model", func() {
+	currentTime := time.Unix(1486678000, 0)
+
+	Describe("Export game", func() {
+		It("should export the game, its offers and their current versions", func() {
+			snapshot, err := models.ExportGame(nil, db, defaultGameID, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(snapshot.Game.ID).To(Equal(defaultGameID))
+			Expect(snapshot.Offers).To(HaveLen(5))
+			Expect(snapshot.OfferVersions).To(HaveLen(3))
+			for _, offerVersion := range snapshot.OfferVersions {
+				Expect(offerVersion.OfferVersion).To(Equal(1))
+			}
+		})
+
+		It("should return error if game does not exist", func() {
+			gameID := uuid.NewV4().String()
+			expectedError := errors.NewModelNotFoundError("Game", map[string]interface{}{
+				"ID": gameID,
+			})
+
+			_, err := models.ExportGame(nil, db, gameID, nil)
+
+			Expect(err).To(MatchError(expectedError))
+		})
+	})
+
+	Describe("Import game", func() {
+		It("should remap offer ids already used by another game", func() {
+			snapshot, err := models.ExportGame(nil, db, defaultGameID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			targetGameID := uuid.NewV4().String()
+
+			result, err := models.ImportGame(nil, db, snapshot, targetGameID, currentTime, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Offers).To(HaveLen(5))
+			Expect(result.OfferVersions).To(HaveLen(3))
+			for oldID, newID := range result.Offers {
+				Expect(newID).NotTo(Equal(oldID))
+			}
+
+			imported, err := models.ExportGame(nil, db, targetGameID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(imported.Game.Name).To(Equal(snapshot.Game.Name))
+			Expect(imported.Offers).To(HaveLen(5))
+			Expect(imported.OfferVersions).To(HaveLen(3))
+		})
+
+		It("should preserve ids that are not in use", func() {
+			snapshot, err := models.ExportGame(nil, db, defaultGameID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			offerID := uuid.NewV4().String()
+			offerVersionID := uuid.NewV4().String()
+			oldOfferID := snapshot.Offers[0].ID
+			snapshot.Offers = snapshot.Offers[:1]
+			snapshot.Offers[0].ID = offerID
+			for _, offerVersion := range snapshot.OfferVersions {
+				if offerVersion.OfferID == oldOfferID {
+					offerVersion.ID = offerVersionID
+					offerVersion.OfferID = offerID
+				}
+			}
+			targetGameID := uuid.NewV4().String()
+
+			result, err := models.ImportGame(nil, db, snapshot, targetGameID, currentTime, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Offers).To(Equal(map[string]string{offerID: offerID}))
+			Expect(result.OfferVersions).To(Equal(map[string]string{offerVersionID: offerVersionID}))
+		})
+
+		It("should import the placements with their fallback offers remapped", func() {
+			popupOfferID := "dd21ec96-2890-4ba0-b8e2-40ea67196990"
+			err := models.UpsertPlacement(nil, db, &models.Placement{
+				GameID:          defaultGameID,
+				Name:            "popup",
+				MaxOffers:       dat.NullInt64From(1),
+				FallbackOfferID: dat.NullStringFrom(popupOfferID),
+			}, offersCache, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+			snapshot, err := models.ExportGame(nil, db, defaultGameID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(snapshot.Placements).To(HaveLen(1))
+			targetGameID := uuid.NewV4().String()
+
+			result, err := models.ImportGame(nil, db, snapshot, targetGameID, currentTime, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			placements, err := models.ListPlacements(nil, db, targetGameID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(placements).To(HaveLen(1))
+			Expect(placements[0].Name).To(Equal("popup"))
+			Expect(placements[0].MaxOffers).To(Equal(dat.NullInt64From(1)))
+			Expect(placements[0].FallbackOfferID.String).To(Equal(result.Offers[popupOfferID]))
+			Expect(placements[0].FallbackOfferID.String).NotTo(Equal(popupOfferID))
+		})
+
+		It("should remap the prerequisites of the offers", func() {
+			snapshot, err := models.ExportGame(nil, db, defaultGameID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			requiredOfferID := snapshot.Offers[1].ID
+			snapshot.Offers[0].Prerequisites = dat.JSON([]byte(fmt.Sprintf(`{"claimed": ["%s"]}`, requiredOfferID)))
+			targetGameID := uuid.NewV4().String()
+
+			result, err := models.ImportGame(nil, db, snapshot, targetGameID, currentTime, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			var prerequisites string
+			err = db.Select("prerequisites").
+				From("offers").
+				Where("id = $1", result.Offers[snapshot.Offers[0].ID]).
+				QueryScalar(&prerequisites)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(prerequisites).To(MatchJSON(fmt.Sprintf(`{"claimed": ["%s"]}`, result.Offers[requiredOfferID])))
+		})
+
+		It("should return error if a prerequisite offer is not in the snapshot", func() {
+			snapshot, err := models.ExportGame(nil, db, defaultGameID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			missingOfferID := uuid.NewV4().String()
+			snapshot.Offers[0].Prerequisites = dat.JSON([]byte(fmt.Sprintf(`{"claimed": ["%s"]}`, missingOfferID)))
+			targetGameID := uuid.NewV4().String()
+
+			_, err = models.ImportGame(nil, db, snapshot, targetGameID, currentTime, nil)
+
+			Expect(err).To(MatchError(errors.NewInvalidModelError("Offer", fmt.Sprintf("the prerequisite offer %s does not exist", missingOfferID))))
+		})
+
+		It("should overwrite offers when importing into the same game", func() {
+			snapshot, err := models.ExportGame(nil, db, defaultGameID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			snapshot.Offers[0].Name = "imported-name"
+
+			result, err := models.ImportGame(nil, db, snapshot, defaultGameID, currentTime, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			for oldID, newID := range result.Offers {
+				Expect(newID).To(Equal(oldID))
+			}
+			for oldID, newID := range result.OfferVersions {
+				Expect(newID).To(Equal(oldID))
+			}
+
+			var name string
+			err = db.Select("name").
+				From("offers").
+				Where("id = $1", snapshot.Offers[0].ID).
+				QueryScalar(&name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal("imported-name"))
+		})
+	})
+})
@@ -0,0 +1,306 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pmylund/go-cache"
+	edat "github.com/topfreegames/extensions/dat"
+	runner "gopkg.in/mgutz/dat.v2/sqlx-runner"
+)
+
+//TestPlayer is a player of a game used by QA. Test players see disabled offers and the drafts
+//of offers, are not limited by frequency or period and their claims and impressions are tagged.
+type TestPlayer struct {
+	GameID    string    `db:"game_id" json:"gameId" valid:"optional"`
+	PlayerID  string    `db:"player_id" json:"playerId" valid:"ascii,stringlength(1|1000),required"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt" valid:"optional"`
+}
+
+//ListTestPlayers returns the test players of a game
+func ListTestPlayers(ctx context.Context, db runner.Connection, gameID string, mr *MixedMetricsReporter) ([]*TestPlayer, error) {
+	testPlayers := []*TestPlayer{}
+	err := mr.WithDatastoreSegment("test_players", SegmentSelect, func() error {
+		builder := db.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("test_players").
+			Where("game_id = $1", gameID).
+			OrderBy("player_id").
+			QueryStructs(&testPlayers)
+	})
+	return testPlayers, err
+}
+
+//IsTestPlayer returns true if the player is a test player of the game
+func IsTestPlayer(ctx context.Context, db runner.Connection, gameID, playerID string, mr *MixedMetricsReporter) (bool, error) {
+	var count int
+	err := mr.WithDatastoreSegment("test_players", SegmentSelect, func() error {
+		builder := db.Select("COUNT(*)")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("test_players").
+			Where("game_id = $1 AND player_id = $2", gameID, playerID).
+			QueryScalar(&count)
+	})
+	return count > 0, err
+}
+
+//isCachedTestPlayer returns true if the player is a test player of the game, reading the
+//test players of the game from offersCache, if it is not nil, or from the database
+func isCachedTestPlayer(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, playerID string,
+	offersCache *cache.Cache,
+	expireDuration time.Duration,
+	mr *MixedMetricsReporter,
+) (bool, error) {
+	if offersCache == nil {
+		return IsTestPlayer(ctx, db, gameID, playerID, mr)
+	}
+
+	testPlayersKey := GetTestPlayersKey(gameID)
+	if testPlayerIDs, found := offersCache.Get(testPlayersKey); found {
+		return testPlayerIDs.(map[string]bool)[playerID], nil
+	}
+	testPlayers, err := ListTestPlayers(ctx, db, gameID, mr)
+	if err != nil {
+		return false, err
+	}
+	testPlayerIDs := make(map[string]bool, len(testPlayers))
+	for _, testPlayer := range testPlayers {
+		testPlayerIDs[testPlayer.PlayerID] = true
+	}
+	offersCache.Set(testPlayersKey, testPlayerIDs, expireDuration)
+	return testPlayerIDs[playerID], nil
+}
+
+//AddTestPlayer makes the player a test player of the game. Adding a test player twice is a no-op.
+func AddTestPlayer(
+	ctx context.Context,
+	db runner.Connection,
+	testPlayer *TestPlayer,
+	offersCache *cache.Cache,
+	t time.Time,
+	mr *MixedMetricsReporter,
+) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.AutoRollback()
+
+	testPlayers := []*TestPlayer{}
+	err = mr.WithDatastoreSegment("test_players", SegmentInsert, func() error {
+		builder := tx.SQL(`
+			INSERT INTO test_players (game_id, player_id, created_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING
+			RETURNING *`,
+			testPlayer.GameID, testPlayer.PlayerID, t,
+		)
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.QueryStructs(&testPlayers)
+	})
+	err = handleForeignKeyViolationError("TestPlayer", err)
+	if err != nil {
+		return err
+	}
+	if len(testPlayers) == 0 {
+		err = mr.WithDatastoreSegment("test_players", SegmentSelect, func() error {
+			builder := tx.Select("*")
+			builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+			return builder.From("test_players").
+				Where("game_id = $1 AND player_id = $2", testPlayer.GameID, testPlayer.PlayerID).
+				QueryStruct(testPlayer)
+		})
+		if err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+	*testPlayer = *testPlayers[0]
+
+	err = insertAuditEvent(ctx, tx, testPlayer.GameID, "", AuditActionAddTestPlayer, nil, testPlayer, mr)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err == nil {
+		offersCache.Delete(GetTestPlayersKey(testPlayer.GameID))
+	}
+	return err
+}
+
+//RemoveTestPlayer makes the player a regular player of the game again.
+//The claims and impressions made while they were a test player remain tagged.
+func RemoveTestPlayer(
+	ctx context.Context,
+	db runner.Connection,
+	gameID, playerID string,
+	offersCache *cache.Cache,
+	mr *MixedMetricsReporter,
+) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.AutoRollback()
+
+	var testPlayer TestPlayer
+	err = mr.WithDatastoreSegment("test_players", SegmentDelete, func() error {
+		builder := tx.DeleteFrom("test_players")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.
+			Where("game_id = $1 AND player_id = $2", gameID, playerID).
+			Returning("*").
+			QueryStruct(&testPlayer)
+	})
+	err = handleNotFoundError("TestPlayer", map[string]interface{}{
+		"GameID":   gameID,
+		"PlayerID": playerID,
+	}, err)
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditEvent(ctx, tx, gameID, "", AuditActionRemoveTestPlayer, &testPlayer, nil, mr)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err == nil {
+		offersCache.Delete(GetTestPlayersKey(gameID))
+	}
+	return err
+}
+
+//ResetTestPlayer forgets the impressions and claims of a test player, so every offer can be
+//seen and claimed again. The claims ledger is kept. It returns a ModelNotFoundError if the
+//player is not a test player of the game.
+func ResetTestPlayer(ctx context.Context, db runner.Connection, gameID, playerID string, mr *MixedMetricsReporter) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.AutoRollback()
+
+	var testPlayer TestPlayer
+	err = mr.WithDatastoreSegment("test_players", SegmentSelect, func() error {
+		builder := tx.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("test_players").
+			Where("game_id = $1 AND player_id = $2", gameID, playerID).
+			QueryStruct(&testPlayer)
+	})
+	err = handleNotFoundError("TestPlayer", map[string]interface{}{
+		"GameID":   gameID,
+		"PlayerID": playerID,
+	}, err)
+	if err != nil {
+		return err
+	}
+
+	err = mr.WithDatastoreSegment("offer_players", SegmentDelete, func() error {
+		builder := tx.DeleteFrom("offer_players")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		_, err := builder.Where("game_id = $1 AND player_id = $2", gameID, playerID).Exec()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = mr.WithDatastoreSegment("dedupe_keys", SegmentDelete, func() error {
+		builder := tx.DeleteFrom("dedupe_keys")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		_, err := builder.Where("game_id = $1 AND player_id = $2", gameID, playerID).Exec()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+//getTestPlayerOffers returns the offers of the game a test player can see at time t.
+//Disabled offers are included and the fields of offers with a draft are replaced by the draft's, except
+//the product, contents and cost: the version stays the published one so the offer can still be viewed and
+//claimed, and the offer is returned with what a claim of that version gives, flagged as having a draft.
+func getTestPlayerOffers(
+	ctx context.Context,
+	db runner.Connection,
+	gameID string,
+	t time.Time,
+	filterAttrs map[string]string,
+	allowInefficientQueries bool,
+	mr *MixedMetricsReporter,
+) ([]*Offer, error) {
+	offers := []*Offer{}
+	err := mr.WithDatastoreSegment("offers", SegmentSelect, func() error {
+		builder := db.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("offers").
+			Where("game_id = $1", gameID).
+			OrderBy("id").
+			QueryStructs(&offers)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	drafts := []*OfferDraft{}
+	err = mr.WithDatastoreSegment("offer_drafts", SegmentSelect, func() error {
+		builder := db.Select("*")
+		builder.Execer = edat.NewExecer(builder.Execer).WithContext(ctx)
+		return builder.From("offer_drafts").
+			Where("game_id = $1", gameID).
+			QueryStructs(&drafts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	draftsByOfferID := map[string]*OfferDraft{}
+	for _, draft := range drafts {
+		draftsByOfferID[draft.OfferID] = draft
+	}
+
+	testOffers := []*Offer{}
+	for _, offer := range offers {
+		if draft, ok := draftsByOfferID[offer.ID]; ok {
+			draftOffer := offerFromDraft(draft)
+			draftOffer.Enabled = offer.Enabled
+			draftOffer.Version = offer.Version
+			draftOffer.ProductID = offer.ProductID
+			draftOffer.Contents = offer.Contents
+			draftOffer.Cost = offer.Cost
+			draftOffer.HasDraft = true
+			offer = draftOffer
+		}
+
+		var trigger Times
+		if err := json.Unmarshal(offer.Trigger, &trigger); err != nil {
+			return nil, err
+		}
+		if t.Unix() < trigger.From || t.Unix() > trigger.To {
+			continue
+		}
+
+		key, err := mismatchedFilterKey(offer, filterAttrs, allowInefficientQueries)
+		if err != nil {
+			return nil, err
+		}
+		if key != "" {
+			continue
+		}
+		testOffers = append(testOffers, offer)
+	}
+	return testOffers, nil
+}
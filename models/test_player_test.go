@@ -0,0 +1,216 @@
+// offers api
+// https://github.com/topfreegames/offers
+//
+// Licensed under the MIT license:
+// http://www.opensource.org/licenses/mit-license
+// Copyright © 2018 Top Free Games <backend@tfgco.com>
+
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/topfreegames/offers/errors"
+	"github.com/topfreegames/offers/models"
+	"gopkg.in/mgutz/dat.v2/dat"
+)
+
+var _ = Describe("Test Player Model", func() {
+	currentTime := time.Unix(1486678000, 0)
+	expireDuration := 300 * time.Second
+	playerID := "qa-player"
+	offerID := "dd21ec96-2890-4ba0-b8e2-40ea67196990"
+	offerInstanceID := "eb7e8d2a-2739-4da3-aa31-7970b63bdad7"
+	draftOfferID := "d5114990-77d7-45c4-ba5f-462fc86b213f"
+	draftOfferInstanceID := "38cf3ed6-b999-4ee8-9e21-8f700532b37c"
+
+	addTestPlayer := func() {
+		err := models.AddTestPlayer(nil, db, &models.TestPlayer{GameID: defaultGameID, PlayerID: playerID}, offersCache, currentTime, nil)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	findOffer := func(offers map[string][]*models.OfferToReturn, placement, id string) *models.OfferToReturn {
+		for _, offer := range offers[placement] {
+			if offer.ID == id {
+				return offer
+			}
+		}
+		return nil
+	}
+
+	Describe("Add test player", func() {
+		It("should add the player once and audit it", func() {
+			addTestPlayer()
+			addTestPlayer()
+
+			testPlayers, err := models.ListTestPlayers(nil, db, defaultGameID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testPlayers).To(HaveLen(1))
+			Expect(testPlayers[0].PlayerID).To(Equal(playerID))
+
+			isTestPlayer, err := models.IsTestPlayer(nil, db, defaultGameID, playerID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isTestPlayer).To(BeTrue())
+
+			events, err := models.ListAuditEvents(nil, db, defaultGameID, "", time.Unix(0, 0), 10, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Action).To(Equal(models.AuditActionAddTestPlayer))
+		})
+
+		It("should return error if game does not exist", func() {
+			err := models.AddTestPlayer(nil, db, &models.TestPlayer{GameID: "non-existing-game", PlayerID: playerID}, offersCache, currentTime, nil)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.InvalidModelError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Describe("Remove test player", func() {
+		It("should remove the player", func() {
+			addTestPlayer()
+
+			err := models.RemoveTestPlayer(nil, db, defaultGameID, playerID, offersCache, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			isTestPlayer, err := models.IsTestPlayer(nil, db, defaultGameID, playerID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isTestPlayer).To(BeFalse())
+		})
+
+		It("should return error if the player is not a test player", func() {
+			err := models.RemoveTestPlayer(nil, db, defaultGameID, playerID, offersCache, nil)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.ModelNotFoundError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Describe("Get available offers", func() {
+		It("should return disabled offers and flag the offers with drafts to test players", func() {
+			addTestPlayer()
+			err := models.SetEnabledOffer(nil, db, defaultGameID, offerID, false, offersCache, nil)
+			Expect(err).NotTo(HaveOccurred())
+			offer, err := models.GetOfferByID(nil, db, defaultGameID, draftOfferID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			draft := models.OfferDraftFromOffer(offer, "qa@tfgco.com")
+			draft.Contents = dat.JSON([]byte(`{"gems": 999}`))
+			err = models.UpsertOfferDraft(nil, db, draft, currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			offers, err := models.GetAvailableOffers(nil, db, offersCache, defaultGameID, playerID, currentTime, expireDuration, map[string]string{}, false, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(findOffer(offers, "popup", offerInstanceID)).NotTo(BeNil())
+			draftOffer := findOffer(offers, "store", draftOfferInstanceID)
+			Expect(draftOffer).NotTo(BeNil())
+			Expect(draftOffer.Contents).NotTo(MatchJSON(`{"gems": 999}`))
+			Expect(draftOffer.ProductID).To(Equal("com.tfg.sample.2"))
+			Expect(draftOffer.HasDraft).To(BeTrue())
+
+			offers, err = models.GetAvailableOffers(nil, db, offersCache, defaultGameID, "regular-player", currentTime, expireDuration, map[string]string{}, false, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(findOffer(offers, "popup", offerInstanceID)).To(BeNil())
+			Expect(findOffer(offers, "store", draftOfferInstanceID).HasDraft).To(BeFalse())
+		})
+
+		It("should see a player added or removed as test player after the test players were cached", func() {
+			err := models.SetEnabledOffer(nil, db, defaultGameID, offerID, false, offersCache, nil)
+			Expect(err).NotTo(HaveOccurred())
+			offers, err := models.GetAvailableOffers(nil, db, offersCache, defaultGameID, playerID, currentTime, expireDuration, map[string]string{}, false, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(findOffer(offers, "popup", offerInstanceID)).To(BeNil())
+
+			addTestPlayer()
+			offers, err = models.GetAvailableOffers(nil, db, offersCache, defaultGameID, playerID, currentTime, expireDuration, map[string]string{}, false, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(findOffer(offers, "popup", offerInstanceID)).NotTo(BeNil())
+
+			err = models.RemoveTestPlayer(nil, db, defaultGameID, playerID, offersCache, nil)
+			Expect(err).NotTo(HaveOccurred())
+			offers, err = models.GetAvailableOffers(nil, db, offersCache, defaultGameID, playerID, currentTime, expireDuration, map[string]string{}, false, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(findOffer(offers, "popup", offerInstanceID)).To(BeNil())
+		})
+
+		It("should ignore frequency and period for test players", func() {
+			addTestPlayer()
+			_, _, err := models.ViewOffer(nil, db, offersCache, defaultGameID, offerInstanceID, playerID, "impression-1", currentTime, cacheExpireDuration, nil)
+			Expect(err).NotTo(HaveOccurred())
+			_, _, _, err = models.ClaimOffer(nil, db, defaultGameID, offerInstanceID, playerID, "com.tfg.sample", "transaction-1", currentTime.Unix(), currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			offers, err := models.GetAvailableOffers(nil, db, offersCache, defaultGameID, playerID, currentTime, expireDuration, map[string]string{}, false, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(findOffer(offers, "popup", offerInstanceID)).NotTo(BeNil())
+		})
+	})
+
+	Describe("Tagging", func() {
+		It("should tag the claims and impressions of test players", func() {
+			addTestPlayer()
+			_, _, err := models.ViewOffer(nil, db, offersCache, defaultGameID, offerInstanceID, playerID, "impression-1", currentTime, cacheExpireDuration, nil)
+			Expect(err).NotTo(HaveOccurred())
+			_, _, _, err = models.ClaimOffer(nil, db, defaultGameID, offerInstanceID, playerID, "com.tfg.sample", "transaction-1", currentTime.Unix(), currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			offerPlayer, err := models.GetOfferPlayer(nil, db, defaultGameID, playerID, offerID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offerPlayer.TestPlayer).To(BeTrue())
+
+			claims, err := models.ListClaims(nil, db, defaultGameID, playerID, time.Unix(0, 0), 10, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(claims).To(HaveLen(1))
+			Expect(claims[0].TestPlayer).To(BeTrue())
+		})
+
+		It("should not tag the claims of regular players", func() {
+			_, _, _, err := models.ClaimOffer(nil, db, defaultGameID, offerInstanceID, playerID, "com.tfg.sample", "transaction-1", currentTime.Unix(), currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			claims, err := models.ListClaims(nil, db, defaultGameID, playerID, time.Unix(0, 0), 10, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(claims).To(HaveLen(1))
+			Expect(claims[0].TestPlayer).To(BeFalse())
+		})
+	})
+
+	Describe("Reset test player", func() {
+		It("should forget impressions and claims but keep the ledger", func() {
+			addTestPlayer()
+			_, _, err := models.ViewOffer(nil, db, offersCache, defaultGameID, offerInstanceID, playerID, "impression-1", currentTime, cacheExpireDuration, nil)
+			Expect(err).NotTo(HaveOccurred())
+			_, _, _, err = models.ClaimOffer(nil, db, defaultGameID, offerInstanceID, playerID, "com.tfg.sample", "transaction-1", currentTime.Unix(), currentTime, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = models.ResetTestPlayer(nil, db, defaultGameID, playerID, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			offerPlayers, err := models.GetOffersByPlayer(nil, db, defaultGameID, playerID, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offerPlayers).To(BeEmpty())
+
+			isReplay, _, err := models.ViewOffer(nil, db, offersCache, defaultGameID, offerInstanceID, playerID, "impression-1", currentTime, cacheExpireDuration, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isReplay).To(BeFalse())
+
+			claims, err := models.ListClaims(nil, db, defaultGameID, playerID, time.Unix(0, 0), 10, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(claims).To(HaveLen(1))
+		})
+
+		It("should return error if the player is not a test player", func() {
+			err := models.ResetTestPlayer(nil, db, defaultGameID, playerID, nil)
+
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*errors.ModelNotFoundError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+})
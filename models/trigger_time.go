@@ -8,16 +8,68 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
+
+	"github.com/topfreegames/offers/errors"
+	"gopkg.in/mgutz/dat.v2/dat"
 )
 
 //TimeTrigger implements interface Trigger
 type TimeTrigger struct{}
 
-//Times holds from and to in UnixTimestamp
+//Times holds from and to in UnixTimestamp and, optionally, how long the offer lasts
+//for each player after they first see it
 type Times struct {
-	From int64 `json:"from"`
-	To   int64 `json:"to"`
+	From     int64  `json:"from"`
+	To       int64  `json:"to"`
+	Duration string `json:"duration,omitempty"`
+}
+
+func (t Times) validate() error {
+	if t.Duration == "" {
+		return nil
+	}
+	duration, err := time.ParseDuration(t.Duration)
+	if err != nil {
+		return err
+	}
+	if duration <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+	return nil
+}
+
+//validateTrigger returns an error if the trigger of the offer has an invalid duration
+func validateTrigger(offer *Offer) error {
+	if len(offer.Trigger) == 0 {
+		return nil
+	}
+	var trigger Times
+	if err := json.Unmarshal(offer.Trigger, &trigger); err != nil {
+		return errors.NewInvalidModelError("Offer", fmt.Sprintf("invalid trigger: %s", err.Error()))
+	}
+	if err := trigger.validate(); err != nil {
+		return errors.NewInvalidModelError("Offer", fmt.Sprintf("invalid trigger: %s", err.Error()))
+	}
+	return nil
+}
+
+//PlayerExpireAt returns until when the offer can be seen by a player that first saw it at firstView.
+//Offers without a duration, or not seen yet, expire at the end of the trigger.
+func (t Times) PlayerExpireAt(firstView dat.NullTime) (int64, error) {
+	if t.Duration == "" || !firstView.Valid {
+		return t.To, nil
+	}
+	duration, err := time.ParseDuration(t.Duration)
+	if err != nil {
+		return 0, err
+	}
+	if expireAt := firstView.Time.Add(duration).Unix(); expireAt < t.To {
+		return expireAt, nil
+	}
+	return t.To, nil
 }
 
 //IsTriggered returns the current time